@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
+	"github.com/spf13/cobra"
+
 	"lorecraft/internal/config"
 	"lorecraft/internal/store"
 	"lorecraft/internal/store/postgres"
@@ -11,13 +14,47 @@ import (
 )
 
 func openDB(ctx context.Context, cfg *config.ProjectConfig) (store.Store, error) {
-	dsn := cfg.Database.DSN
+	return openDSN(ctx, cfg.Database.DSN, cfg)
+}
+
+// openDSN opens the database at dsn, which need not be the one configured for
+// the project.
+func openDSN(ctx context.Context, dsn string, cfg *config.ProjectConfig) (store.Store, error) {
 	switch {
 	case strings.HasPrefix(dsn, "postgres://"):
 		return postgres.New(ctx, dsn, cfg)
 	case strings.HasPrefix(dsn, "sqlite://"):
 		return sqlite.New(ctx, dsn, cfg)
 	default:
-		return nil, nil
+		return nil, fmt.Errorf("unsupported database DSN %q (expected postgres:// or sqlite://)", dsn)
+	}
+}
+
+// openQueryDB opens the database for a query command, restricted to the
+// audience named by the inherited --audience flag.
+func openQueryDB(ctx context.Context, cmd *cobra.Command, cfg *config.ProjectConfig) (store.Store, error) {
+	db, err := openDB(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	audience, _ := cmd.Flags().GetString("audience")
+	restricted, err := applyAudience(db, cfg, audience)
+	if err != nil {
+		db.Close(ctx)
+		return nil, err
+	}
+	return restricted, nil
+}
+
+// applyAudience narrows db to what the named audience may see. An empty name
+// leaves it unrestricted.
+func applyAudience(db store.Store, cfg *config.ProjectConfig, name string) (store.Store, error) {
+	if strings.TrimSpace(name) == "" {
+		return db, nil
+	}
+	audience, ok := cfg.AudienceByName(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown audience: %s", name)
 	}
+	return db.WithVisibility(&store.Visibility{Property: audience.Property, Hidden: audience.Hidden}), nil
 }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/dump"
+)
+
+func dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage lorecraft databases",
+	}
+	cmd.AddCommand(dbCopyCmd())
+	return cmd
+}
+
+func dbCopyCmd() *cobra.Command {
+	var from, to string
+	cmd := &cobra.Command{
+		Use:   "copy",
+		Short: "Copy every entity, edge and event from one database to another",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDBCopy(from, to)
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "DSN of the database to copy (default: the project database)")
+	cmd.Flags().StringVar(&to, "to", "", "DSN of the database to write")
+	cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func runDBCopy(from, to string) error {
+	ctx := context.Background()
+
+	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
+	if err != nil {
+		return err
+	}
+
+	schema, err := config.LoadSchema("schema.yaml")
+	if err != nil {
+		return err
+	}
+
+	if from == "" {
+		from = cfg.Database.DSN
+	}
+	if from == to {
+		return fmt.Errorf("--from and --to name the same database")
+	}
+
+	source, err := openDSN(ctx, from, cfg)
+	if err != nil {
+		return err
+	}
+	defer source.Close(ctx)
+
+	doc, err := dump.Build(ctx, source, dump.Metadata{Project: cfg.Project, Lorecraft: version})
+	if err != nil {
+		return err
+	}
+
+	target, err := openDSN(ctx, to, cfg)
+	if err != nil {
+		return err
+	}
+	defer target.Close(ctx)
+
+	if err := target.EnsureSchema(ctx, schema); err != nil {
+		return fmt.Errorf("ensuring schema: %w", err)
+	}
+	result, err := dump.Restore(ctx, target, doc, dump.Options{})
+	if err != nil {
+		return err
+	}
+	printRestoreResult(result)
+	return nil
+}
@@ -0,0 +1,15 @@
+package main
+
+import "github.com/spf13/cobra"
+
+func exportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the database to other formats",
+	}
+	cmd.PersistentFlags().String("audience", "", "Only export what this audience from lorecraft.yaml may see")
+	cmd.AddCommand(exportHTMLCmd())
+	cmd.AddCommand(exportGraphCmd())
+	cmd.AddCommand(exportJSONCmd())
+	return cmd
+}
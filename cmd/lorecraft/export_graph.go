@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/export"
+)
+
+func exportGraphCmd() *cobra.Command {
+	var format string
+	var opts export.GraphOptions
+	cmd := &cobra.Command{
+		Use:   "graph [root]",
+		Short: "Write the relationship graph for Graphviz, Gephi or Mermaid",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Root = args[0]
+			}
+			return runExportGraph(cmd, format, opts)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", export.GraphDOT, "Output format: "+strings.Join(export.GraphFormats, ", "))
+	cmd.Flags().StringVar(&opts.Layer, "layer", "", "Only include entities in this layer")
+	cmd.Flags().StringVar(&opts.EntityType, "type", "", "Only include entities of this type or its subtypes")
+	cmd.Flags().StringVar(&opts.RelType, "rel", "", "Only include relationships of this type; inverse names such as HAS_MEMBER are accepted")
+	cmd.Flags().IntVar(&opts.Depth, "depth", 1, "Traversal depth from the root entity (1-5)")
+	return cmd
+}
+
+func runExportGraph(cmd *cobra.Command, format string, opts export.GraphOptions) error {
+	ctx := context.Background()
+
+	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
+	if err != nil {
+		return err
+	}
+
+	schema, err := config.LoadSchema("schema.yaml")
+	if err != nil {
+		return err
+	}
+
+	db, err := openQueryDB(ctx, cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+
+	graph, err := export.BuildGraph(ctx, db, schema, opts)
+	if err != nil {
+		return err
+	}
+	return export.WriteGraph(os.Stdout, graph, format)
+}
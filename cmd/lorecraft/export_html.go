@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/export"
+)
+
+func exportHTMLCmd() *cobra.Command {
+	var opts export.HTMLOptions
+	cmd := &cobra.Command{
+		Use:   "html <dir>",
+		Short: "Render the lore as a static, linked HTML site",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExportHTML(cmd, args[0], opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.Title, "title", "", "Site title (defaults to the project name)")
+	cmd.Flags().BoolVar(&opts.Secrets, "gm-notes", false, "Include GM notes on entity pages")
+	return cmd
+}
+
+func runExportHTML(cmd *cobra.Command, dir string, opts export.HTMLOptions) error {
+	ctx := context.Background()
+
+	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
+	if err != nil {
+		return err
+	}
+
+	schema, err := config.LoadSchema("schema.yaml")
+	if err != nil {
+		return err
+	}
+
+	db, err := openQueryDB(ctx, cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+
+	if opts.Title == "" {
+		opts.Title = cfg.Project
+	}
+	result, err := export.HTML(ctx, db, schema, dir, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Exported %d entities, %d types and %d tags to %s\n", result.Entities, result.Types, result.Tags, dir)
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/dump"
+)
+
+func exportJSONCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "json [file]",
+		Short: "Dump entities, edges and events to a portable JSON document",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runExportJSON(cmd, path)
+		},
+	}
+}
+
+func runExportJSON(cmd *cobra.Command, path string) error {
+	ctx := context.Background()
+
+	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
+	if err != nil {
+		return err
+	}
+
+	db, err := openQueryDB(ctx, cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+
+	audience, _ := cmd.Flags().GetString("audience")
+	doc, err := dump.Build(ctx, db, dump.Metadata{Project: cfg.Project, Lorecraft: version, Audience: audience})
+	if err != nil {
+		return err
+	}
+
+	if path == "" || path == "-" {
+		if err := dump.Write(os.Stdout, doc); err != nil {
+			return fmt.Errorf("writing dump: %w", err)
+		}
+		return nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+	if err := dump.Write(f, doc); err != nil {
+		f.Close()
+		return fmt.Errorf("writing dump: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stdout, "Exported %d entities, %d edges and %d events to %s\n", len(doc.Entities), len(doc.Edges), len(doc.Events), path)
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/dump"
+)
+
+func importCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Load the database from other formats",
+	}
+	cmd.AddCommand(importJSONCmd())
+	return cmd
+}
+
+func importJSONCmd() *cobra.Command {
+	var options dump.Options
+	cmd := &cobra.Command{
+		Use:   "json <file>",
+		Short: "Restore a document written by export json; use - to read stdin",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImportJSON(args[0], options)
+		},
+	}
+	cmd.Flags().BoolVar(&options.AllowFiltered, "allow-filtered", false, "Restore a document exported with --audience, removing what it hides")
+	return cmd
+}
+
+func runImportJSON(path string, options dump.Options) error {
+	ctx := context.Background()
+
+	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
+	if err != nil {
+		return err
+	}
+
+	schema, err := config.LoadSchema("schema.yaml")
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", path, err)
+		}
+		defer f.Close()
+		in = f
+	}
+	doc, err := dump.Read(in)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+
+	if err := db.EnsureSchema(ctx, schema); err != nil {
+		return fmt.Errorf("ensuring schema: %w", err)
+	}
+	result, err := dump.Restore(ctx, db, doc, options)
+	if errors.Is(err, dump.ErrFiltered) {
+		return fmt.Errorf("%w (use --allow-filtered to restore it anyway)", err)
+	}
+	if err != nil {
+		return err
+	}
+	printRestoreResult(result)
+	return nil
+}
+
+func printRestoreResult(result dump.Result) {
+	fmt.Fprintln(os.Stdout, "Import complete.")
+	fmt.Fprintf(os.Stdout, "  Nodes restored: %d\n", result.Entities)
+	fmt.Fprintf(os.Stdout, "  Edges restored: %d\n", result.Edges)
+	fmt.Fprintf(os.Stdout, "  Nodes removed:  %d\n", result.NodesRemoved)
+}
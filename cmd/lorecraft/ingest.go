@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -13,18 +14,23 @@ import (
 
 func ingestCmd() *cobra.Command {
 	var full bool
+	var atomic bool
 	cmd := &cobra.Command{
 		Use:   "ingest",
 		Short: "Synchronise the database with markdown source files",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runIngest(cmd, full)
+			if !cmd.Flags().Changed("atomic") {
+				atomic = full
+			}
+			return runIngest(cmd, full, atomic)
 		},
 	}
 	cmd.Flags().BoolVar(&full, "full", false, "Force full re-ingestion (ignore incremental hashes)")
+	cmd.Flags().BoolVar(&atomic, "atomic", false, "Commit changes only if the whole run succeeds (default for --full)")
 	return cmd
 }
 
-func runIngest(cmd *cobra.Command, full bool) error {
+func runIngest(cmd *cobra.Command, full, atomic bool) error {
 	ctx := context.Background()
 
 	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
@@ -43,16 +49,43 @@ func runIngest(cmd *cobra.Command, full bool) error {
 	}
 	defer db.Close(ctx)
 
-	result, err := ingest.Run(ctx, cfg, schema, db, ingest.Options{Full: full})
+	result, err := ingest.Run(ctx, cfg, schema, db, ingest.Options{Full: full, Atomic: atomic})
 	if err != nil {
 		return err
 	}
 
+	if result.RolledBack {
+		fmt.Fprintln(os.Stdout, "Ingestion rolled back; no changes were committed.")
+		fmt.Fprintf(os.Stdout, "\nErrors (%d):\n", len(result.Errors))
+		for _, item := range result.Errors {
+			fmt.Fprintf(os.Stdout, "  - %v\n", item)
+		}
+		return fmt.Errorf("ingestion rolled back")
+	}
+
 	fmt.Fprintln(os.Stdout, "Ingestion complete.")
-	fmt.Fprintf(os.Stdout, "  Nodes upserted: %d\n", result.NodesUpserted)
-	fmt.Fprintf(os.Stdout, "  Edges upserted: %d\n", result.EdgesUpserted)
-	fmt.Fprintf(os.Stdout, "  Nodes removed:  %d\n", result.NodesRemoved)
-	fmt.Fprintf(os.Stdout, "  Files skipped:  %d\n", result.FilesSkipped)
+	fmt.Fprintf(os.Stdout, "  Nodes upserted:   %d\n", result.NodesUpserted)
+	fmt.Fprintf(os.Stdout, "  Edges upserted:   %d\n", result.EdgesUpserted)
+	fmt.Fprintf(os.Stdout, "  Nodes removed:    %d\n", result.NodesRemoved)
+	fmt.Fprintf(os.Stdout, "  Files skipped:    %d\n", result.FilesSkipped)
+	fmt.Fprintf(os.Stdout, "  Entities skipped: %d\n", result.EntitiesSkipped)
+
+	if len(result.Reingested) > 0 || result.Renamed > 0 {
+		fmt.Fprintln(os.Stdout, "\nSchema changed since the last ingestion.")
+		if result.Renamed > 0 {
+			fmt.Fprintf(os.Stdout, "  Values renamed: %d\n", result.Renamed)
+		}
+		if len(result.Reingested) > 0 {
+			fmt.Fprintf(os.Stdout, "  Re-ingested:    %s\n", strings.Join(result.Reingested, ", "))
+		}
+	}
+
+	if len(result.Warnings) > 0 {
+		fmt.Fprintf(os.Stdout, "\nWarnings (%d):\n", len(result.Warnings))
+		for _, item := range result.Warnings {
+			fmt.Fprintf(os.Stdout, "  - %s\n", item)
+		}
+	}
 
 	if len(result.Errors) > 0 {
 		fmt.Fprintf(os.Stdout, "\nErrors (%d):\n", len(result.Errors))
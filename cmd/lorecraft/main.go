@@ -15,8 +15,13 @@ func main() {
 	root.SetVersionTemplate("{{.Version}}\n")
 	root.AddCommand(ingestCmd())
 	root.AddCommand(serveCmd())
+	root.AddCommand(watchCmd())
 	root.AddCommand(validateCmd())
 	root.AddCommand(queryCmd())
+	root.AddCommand(exportCmd())
+	root.AddCommand(importCmd())
+	root.AddCommand(dbCmd())
+	root.AddCommand(schemaCmd())
 	root.AddCommand(initCmd())
 	root.AddCommand(versionCmd())
 	if err := root.Execute(); err != nil {
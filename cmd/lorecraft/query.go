@@ -7,9 +7,11 @@ func queryCmd() *cobra.Command {
 		Use:   "query",
 		Short: "Query the database from the CLI",
 	}
+	cmd.PersistentFlags().String("audience", "", "Only show what this audience from lorecraft.yaml may see")
 	cmd.AddCommand(querySQLCmd())
 	cmd.AddCommand(queryEntityCmd())
 	cmd.AddCommand(queryRelationsCmd())
+	cmd.AddCommand(queryPathCmd())
 	cmd.AddCommand(queryListCmd())
 	cmd.AddCommand(querySearchCmd())
 	cmd.AddCommand(queryStateCmd())
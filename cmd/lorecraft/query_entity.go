@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"lorecraft/internal/config"
+	"lorecraft/internal/store"
 )
 
 func queryEntityCmd() *cobra.Command {
@@ -34,7 +35,7 @@ func runQueryEntity(cmd *cobra.Command, name, entityType string) error {
 		return err
 	}
 
-	db, err := openDB(ctx, cfg)
+	db, err := openQueryDB(ctx, cmd, cfg)
 	if err != nil {
 		return err
 	}
@@ -46,21 +47,27 @@ func runQueryEntity(cmd *cobra.Command, name, entityType string) error {
 	}
 	if entity == nil {
 		fmt.Fprintf(os.Stdout, "No entity found for %q.\n", name)
-		return nil
+		return printSuggestions(ctx, db, name)
 	}
 
 	fmt.Fprintf(os.Stdout, "Name: %s\n", entity.Name)
 	fmt.Fprintf(os.Stdout, "Type: %s\n", entity.EntityType)
 	fmt.Fprintf(os.Stdout, "Layer: %s\n", entity.Layer)
+	if len(entity.Aliases) > 0 {
+		fmt.Fprintf(os.Stdout, "Aliases: %s\n", joinValues(entity.Aliases))
+	}
 	if len(entity.Tags) > 0 {
 		fmt.Fprintf(os.Stdout, "Tags: %s\n", joinValues(entity.Tags))
 	}
 	if entity.SourceFile != "" {
-		fmt.Fprintf(os.Stdout, "Source: %s\n", entity.SourceFile)
+		fmt.Fprintf(os.Stdout, "Source: %s\n", store.SourceKey(entity.SourceFile, entity.SourceAnchor))
 	}
 	if entity.Body != "" {
 		fmt.Fprintf(os.Stdout, "Body:\n%s\n", entity.Body)
 	}
+	if entity.Secret != "" {
+		fmt.Fprintf(os.Stdout, "GM notes:\n%s\n", entity.Secret)
+	}
 
 	if len(entity.Properties) == 0 {
 		return nil
@@ -72,8 +79,17 @@ func runQueryEntity(cmd *cobra.Command, name, entityType string) error {
 	}
 	sort.Strings(keys)
 
+	defaulted := make(map[string]struct{}, len(entity.Defaulted))
+	for _, key := range entity.Defaulted {
+		defaulted[key] = struct{}{}
+	}
+
 	fmt.Fprintln(os.Stdout, "Properties:")
 	for _, key := range keys {
+		if _, ok := defaulted[key]; ok {
+			fmt.Fprintf(os.Stdout, "  %s: %v (default)\n", key, entity.Properties[key])
+			continue
+		}
 		fmt.Fprintf(os.Stdout, "  %s: %v\n", key, entity.Properties[key])
 	}
 	return nil
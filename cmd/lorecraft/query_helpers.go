@@ -1,7 +1,35 @@
 package main
 
-import "strings"
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"lorecraft/internal/store"
+)
 
 func joinValues(values []string) string {
 	return strings.Join(values, ", ")
 }
+
+// printSuggestions lists the entities whose names resemble name, best first,
+// after a lookup found nothing.
+func printSuggestions(ctx context.Context, db store.Store, name string) error {
+	suggestions, err := db.SuggestEntities(ctx, name, store.MaxSuggestions)
+	if err != nil {
+		return err
+	}
+	if len(suggestions) == 0 {
+		return nil
+	}
+	fmt.Fprintln(os.Stdout, "Did you mean:")
+	for _, s := range suggestions {
+		if s.Alias != "" {
+			fmt.Fprintf(os.Stdout, "  %s (%s, %s) -- alias %s\n", s.Name, s.EntityType, s.Layer, s.Alias)
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "  %s (%s, %s)\n", s.Name, s.EntityType, s.Layer)
+	}
+	return nil
+}
@@ -21,7 +21,7 @@ func queryListCmd() *cobra.Command {
 			return runQueryList(cmd, entityType, layer, tag)
 		},
 	}
-	cmd.Flags().StringVar(&entityType, "type", "", "Entity type to filter")
+	cmd.Flags().StringVar(&entityType, "type", "", "Entity type to filter, subtypes included")
 	cmd.Flags().StringVar(&layer, "layer", "", "Layer to filter")
 	cmd.Flags().StringVar(&tag, "tag", "", "Tag to filter")
 	return cmd
@@ -35,7 +35,7 @@ func runQueryList(cmd *cobra.Command, entityType, layer, tag string) error {
 		return err
 	}
 
-	db, err := openDB(ctx, cfg)
+	db, err := openQueryDB(ctx, cmd, cfg)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/store"
+)
+
+func queryPathCmd() *cobra.Command {
+	var relTypes []string
+	var depth int
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "path <from> <to>",
+		Short: "Show how two entities are connected",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQueryPath(cmd, args[0], args[1], relTypes, depth, all)
+		},
+	}
+	cmd.Flags().StringSliceVar(&relTypes, "type", nil, "Relationship types a path may use (repeatable)")
+	cmd.Flags().IntVar(&depth, "depth", 3, "Maximum number of hops (1-5)")
+	cmd.Flags().BoolVar(&all, "all", false, "Show every path within --depth, not only the shortest")
+	return cmd
+}
+
+func runQueryPath(cmd *cobra.Command, from, to string, relTypes []string, depth int, all bool) error {
+	ctx := context.Background()
+
+	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
+	if err != nil {
+		return err
+	}
+
+	db, err := openQueryDB(ctx, cmd, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+
+	paths, err := db.FindPaths(ctx, from, to, depth, relTypes)
+	if err != nil {
+		return err
+	}
+	if !all {
+		paths = store.Shortest(paths)
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stdout, "No path from %q to %q within %d hops.\n", from, to, depth)
+		return nil
+	}
+
+	for i, path := range paths {
+		if i > 0 {
+			fmt.Fprintln(os.Stdout)
+		}
+		first := path.Hops[0].From
+		hops := "hops"
+		if len(path.Hops) == 1 {
+			hops = "hop"
+		}
+		fmt.Fprintf(os.Stdout, "[%d] %d %s\n", i+1, len(path.Hops), hops)
+		fmt.Fprintf(os.Stdout, "  %s (%s)\n", first.Name, first.EntityType)
+		for _, hop := range path.Hops {
+			arrow := "-" + hop.Type + "->"
+			if hop.Direction == "incoming" {
+				arrow = "<-" + hop.Type + "-"
+			}
+			fmt.Fprintf(os.Stdout, "    %s %s (%s)\n", arrow, hop.To.Name, hop.To.EntityType)
+		}
+	}
+	return nil
+}
@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"lorecraft/internal/config"
+	"lorecraft/internal/store"
 )
 
 func queryRelationsCmd() *cobra.Command {
@@ -37,13 +39,17 @@ func runQueryRelations(cmd *cobra.Command, name, relType, direction string, dept
 		return err
 	}
 
-	db, err := openDB(ctx, cfg)
+	db, err := openQueryDB(ctx, cmd, cfg)
 	if err != nil {
 		return err
 	}
 	defer db.Close(ctx)
 
 	rels, err := db.GetRelationships(ctx, name, relType, direction, depth)
+	if errors.Is(err, store.ErrEntityNotFound) {
+		fmt.Fprintf(os.Stdout, "No entity found for %q.\n", name)
+		return printSuggestions(ctx, db, name)
+	}
 	if err != nil {
 		return err
 	}
@@ -22,7 +22,7 @@ func querySearchCmd() *cobra.Command {
 			return runQuerySearch(cmd, query, entityType, layer)
 		},
 	}
-	cmd.Flags().StringVar(&entityType, "type", "", "Entity type to filter")
+	cmd.Flags().StringVar(&entityType, "type", "", "Entity type to filter, subtypes included")
 	cmd.Flags().StringVar(&layer, "layer", "", "Layer to filter")
 	return cmd
 }
@@ -35,7 +35,7 @@ func runQuerySearch(cmd *cobra.Command, query, entityType, layer string) error {
 		return err
 	}
 
-	db, err := openDB(ctx, cfg)
+	db, err := openQueryDB(ctx, cmd, cfg)
 	if err != nil {
 		return err
 	}
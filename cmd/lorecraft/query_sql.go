@@ -39,7 +39,7 @@ func runSQL(cmd *cobra.Command, query string, params map[string]any) error {
 		return err
 	}
 
-	db, err := openDB(ctx, cfg)
+	db, err := openQueryDB(ctx, cmd, cfg)
 	if err != nil {
 		return err
 	}
@@ -38,7 +38,7 @@ func runQueryState(cmd *cobra.Command, name, layer string) error {
 		return err
 	}
 
-	db, err := openDB(ctx, cfg)
+	db, err := openQueryDB(ctx, cmd, cfg)
 	if err != nil {
 		return err
 	}
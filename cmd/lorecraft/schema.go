@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"lorecraft/internal/config"
+)
+
+func schemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Inspect the schema",
+	}
+	cmd.AddCommand(schemaDiffCmd())
+	return cmd
+}
+
+func schemaDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff",
+		Short: "Compare schema.yaml with the schema of the last complete ingestion",
+		RunE:  runSchemaDiff,
+	}
+}
+
+func runSchemaDiff(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
+	if err != nil {
+		return err
+	}
+
+	schema, err := config.LoadSchema("schema.yaml")
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close(ctx)
+
+	state, err := db.GetSchemaState(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case state == nil:
+		fmt.Fprintln(os.Stdout, "No schema recorded yet; run lorecraft ingest first.")
+		return nil
+	case state.Fingerprint == schema.Fingerprint():
+		fmt.Fprintln(os.Stdout, "Schema unchanged since the last ingestion.")
+		return nil
+	}
+
+	previous, err := config.ParseSchema(state.Source)
+	if err != nil {
+		fmt.Fprintf(os.Stdout, "The recorded schema no longer loads (%v).\n", err)
+		fmt.Fprintln(os.Stdout, "The next ingestion re-ingests every entity.")
+		return nil
+	}
+	printSchemaDiff(os.Stdout, config.DiffSchemas(previous, schema))
+	return nil
+}
+
+func printSchemaDiff(w io.Writer, diff config.SchemaDiff) {
+	fmt.Fprintf(w, "Schema changes since the last ingestion (%d):\n", len(diff.Changes))
+	for _, change := range diff.Changes {
+		fmt.Fprintf(w, "  - %s\n", change)
+	}
+
+	if len(diff.Reingest) == 0 && len(diff.PropertyRenames) == 0 && len(diff.RelationshipRenames) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nThe next ingestion will:")
+	for _, rename := range diff.PropertyRenames {
+		fmt.Fprintf(w, "  - move %s values of %s to %s\n", rename.EntityType, rename.From, rename.To)
+	}
+	for _, rename := range diff.RelationshipRenames {
+		fmt.Fprintf(w, "  - rename %s edges to %s\n", rename.From, rename.To)
+	}
+	if len(diff.Reingest) > 0 {
+		fmt.Fprintf(w, "  - re-ingest entity types: %s\n", strings.Join(diff.Reingest, ", "))
+	}
+}
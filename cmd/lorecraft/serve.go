@@ -2,31 +2,58 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
 	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
 	"lorecraft/internal/mcp"
 
 	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func serveCmd() *cobra.Command {
+	var watch bool
+	var writes bool
+	var audience string
+	var httpAddr string
+	var watchOptions ingest.WatchOptions
 	cmd := &cobra.Command{
 		Use:   "serve",
-		Short: "Start the MCP server over stdio",
-		RunE:  runServe,
+		Short: "Start the MCP server over stdio or HTTP",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd, httpAddr, audience, watch, writes, watchOptions)
+		},
 	}
+	cmd.Flags().StringVar(&httpAddr, "http", "", "Serve streamable HTTP on this address (e.g. :8080) instead of stdio")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Ingest changes to layer paths while serving")
+	cmd.Flags().StringVar(&audience, "audience", "", "Only expose what this audience from lorecraft.yaml may see")
+	cmd.Flags().BoolVar(&writes, "writes", false, "Offer the create_entity and update_entity tools for layers with a drafts path")
+	addWatchFlags(cmd, &watchOptions)
 	return cmd
 }
 
-func runServe(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+func runServe(cmd *cobra.Command, httpAddr, audience string, watch, writes bool, watchOptions ingest.WatchOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
 	if err != nil {
 		return err
 	}
+	// Write tools bypass the audience and touch the lore tree, so they are
+	// never offered to a restricted audience or to anonymous HTTP clients.
+	if writes && audience != "" {
+		return fmt.Errorf("--writes cannot be combined with --audience")
+	}
+	if writes && httpAddr != "" && len(cfg.Server.Tokens) == 0 {
+		return fmt.Errorf("--writes over HTTP requires server.tokens in lorecraft.yaml")
+	}
 
 	schema, err := config.LoadSchema("schema.yaml")
 	if err != nil {
@@ -37,8 +64,36 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	defer db.Close(ctx)
+	defer db.Close(context.Background())
+
+	visible, err := applyAudience(db, cfg, audience)
+	if err != nil {
+		return err
+	}
+
+	if watch {
+		startWatch(ctx, cfg, schema, db, watchOptions)
+	}
+
+	server := mcp.NewServer(schema, visible, version)
+	if writes {
+		server.EnableWrites(cfg, db)
+	}
+	if err := server.LoadPromptTemplates("prompts"); err != nil {
+		return err
+	}
+	if httpAddr == "" {
+		return server.Run(ctx, &sdk.StdioTransport{})
+	}
 
-	server := mcp.NewServer(schema, db, version)
-	return server.Run(ctx, &sdk.StdioTransport{})
+	listener, err := net.Listen("tcp", httpAddr)
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", httpAddr, err)
+	}
+	auth := "without authentication"
+	if len(cfg.Server.Tokens) > 0 {
+		auth = fmt.Sprintf("with %d bearer token(s)", len(cfg.Server.Tokens))
+	}
+	fmt.Fprintf(os.Stderr, "Serving MCP over HTTP on %s %s. Press Ctrl+C to stop.\n", listener.Addr(), auth)
+	return server.RunHTTP(ctx, listener, cfg.Server.Tokens)
 }
@@ -88,6 +88,9 @@ func printIssues(out io.Writer, issues []validate.Issue) {
 		if issue.FilePath != "" {
 			location = fmt.Sprintf("%s (%s)", location, issue.FilePath)
 		}
+		if issue.Field != "" {
+			location = fmt.Sprintf("%s field %s", location, issue.Field)
+		}
 		fmt.Fprintf(out, "  - %s: %s (%s)\n", location, issue.Message, issue.Code)
 	}
 }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+)
+
+func watchCmd() *cobra.Command {
+	var options ingest.WatchOptions
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch layer paths and ingest changes as they happen",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWatch(cmd, options)
+		},
+	}
+	addWatchFlags(cmd, &options)
+	return cmd
+}
+
+func addWatchFlags(cmd *cobra.Command, options *ingest.WatchOptions) {
+	cmd.Flags().DurationVar(&options.Interval, "interval", ingest.DefaultWatchInterval, "How often to poll layer paths for changes")
+	cmd.Flags().DurationVar(&options.Debounce, "debounce", ingest.DefaultWatchDebounce, "Quiet period before ingesting a batch of changes")
+}
+
+func runWatch(cmd *cobra.Command, options ingest.WatchOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.LoadProjectConfig("lorecraft.yaml")
+	if err != nil {
+		return err
+	}
+
+	schema, err := config.LoadSchema("schema.yaml")
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close(context.Background())
+
+	result, err := ingest.Run(ctx, cfg, schema, db, ingest.Options{})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Initial ingestion: %d nodes upserted, %d edges upserted, %d nodes removed, %d files and %d entities skipped.\n",
+		result.NodesUpserted, result.EdgesUpserted, result.NodesRemoved, result.FilesSkipped, result.EntitiesSkipped)
+	printWatchErrors(os.Stdout, result.Errors)
+	printWatchWarnings(os.Stdout, result.Warnings)
+
+	fmt.Fprintf(os.Stdout, "Watching %d layer(s) for changes. Press Ctrl+C to stop.\n", len(cfg.Layers))
+	return ingest.Watch(ctx, cfg, schema, db, options, func(change ingest.Change) {
+		printWatchChange(os.Stdout, change)
+	})
+}
+
+// startWatch runs ingest.Watch in the background for serve --watch, logging to
+// stderr because stdout carries the stdio transport.
+func startWatch(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema, db ingest.Store, options ingest.WatchOptions) {
+	go func() {
+		err := ingest.Watch(ctx, cfg, schema, db, options, func(change ingest.Change) {
+			printWatchChange(os.Stderr, change)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "watch stopped: %v\n", err)
+		}
+	}()
+}
+
+func printWatchChange(w io.Writer, change ingest.Change) {
+	stamp := time.Now().Format("15:04:05")
+	if change.Result == nil {
+		fmt.Fprintf(w, "[%s] watch error: %v\n", stamp, change.Err)
+		return
+	}
+
+	fmt.Fprintf(w, "[%s] %d added, %d modified, %d removed: %d nodes upserted, %d edges upserted, %d nodes removed\n",
+		stamp,
+		len(change.Added),
+		len(change.Modified),
+		len(change.Removed),
+		change.Result.NodesUpserted,
+		change.Result.EdgesUpserted,
+		change.Result.NodesRemoved,
+	)
+	for _, path := range change.Added {
+		fmt.Fprintf(w, "  + %s\n", path)
+	}
+	for _, path := range change.Modified {
+		fmt.Fprintf(w, "  ~ %s\n", path)
+	}
+	for _, path := range change.Removed {
+		fmt.Fprintf(w, "  - %s\n", path)
+	}
+	if change.Err != nil {
+		fmt.Fprintf(w, "  error: %v\n", change.Err)
+	}
+	printWatchErrors(w, change.Result.Errors)
+	printWatchWarnings(w, change.Result.Warnings)
+}
+
+func printWatchErrors(w io.Writer, errs []error) {
+	for _, item := range errs {
+		fmt.Fprintf(w, "  error: %v\n", item)
+	}
+}
+
+func printWatchWarnings(w io.Writer, warnings []string) {
+	for _, item := range warnings {
+		fmt.Fprintf(w, "  warning: %s\n", item)
+	}
+}
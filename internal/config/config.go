@@ -3,17 +3,21 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 type ProjectConfig struct {
-	Project  string         `yaml:"project"`
-	Version  int            `yaml:"version"`
-	Database DatabaseConfig `yaml:"database"`
-	Layers   []Layer        `yaml:"layers"`
-	Exclude  []string       `yaml:"exclude"`
+	Project   string         `yaml:"project"`
+	Version   int            `yaml:"version"`
+	Database  DatabaseConfig `yaml:"database"`
+	Layers    []Layer        `yaml:"layers"`
+	Exclude   []string       `yaml:"exclude"`
+	Links     LinksConfig    `yaml:"links"`
+	Audiences []Audience     `yaml:"audiences"`
+	Server    ServerConfig   `yaml:"server"`
 }
 
 type DatabaseConfig struct {
@@ -25,6 +29,82 @@ type Layer struct {
 	Paths     []string `yaml:"paths"`
 	Canonical bool     `yaml:"canonical"`
 	DependsOn []string `yaml:"depends_on"`
+	// Drafts is the directory, inside one of Paths, where MCP write tools
+	// create entity files. Layers without it cannot be written to.
+	Drafts string `yaml:"drafts"`
+	// Include and Exclude are globs, relative to the layer path a file lies
+	// under, that select the files to ingest. With no Include, every markdown
+	// file is.
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+	// Rules infer the type and default frontmatter of files by path.
+	Rules []PathRule `yaml:"rules"`
+}
+
+// LinksConfig controls how inline links in markdown bodies become edges.
+type LinksConfig struct {
+	// Relationship is the edge type created for each link. Defaults to MENTIONS.
+	Relationship string `yaml:"relationship"`
+	// Markdown also follows standard markdown links to other lore files by
+	// relative path, in addition to [[wiki links]].
+	Markdown bool `yaml:"markdown"`
+	// Disabled turns link extraction off entirely.
+	Disabled bool `yaml:"disabled"`
+}
+
+const DefaultLinkRelationship = "MENTIONS"
+
+// Audience names a group of readers and the entities hidden from them: any
+// entity whose Property value is one of Hidden.
+type Audience struct {
+	Name     string   `yaml:"name"`
+	Property string   `yaml:"property"`
+	Hidden   []string `yaml:"hidden"`
+}
+
+// ServerConfig configures `lorecraft serve --http`.
+type ServerConfig struct {
+	// Tokens are the bearer tokens accepted over HTTP. Environment variables
+	// such as ${LORECRAFT_TOKEN} are expanded so tokens can be kept out of the
+	// file. With no tokens, HTTP requests are not authenticated.
+	Tokens []string `yaml:"tokens"`
+}
+
+// InDrafts reports whether path lies inside the layer's drafts directory.
+func (l Layer) InDrafts(path string) bool {
+	return l.Drafts != "" && withinAny(path, []string{l.Drafts})
+}
+
+// LayerByName returns the layer with the given name, compared
+// case-insensitively.
+func (c *ProjectConfig) LayerByName(name string) (*Layer, bool) {
+	for i := range c.Layers {
+		if strings.EqualFold(c.Layers[i].Name, name) {
+			return &c.Layers[i], true
+		}
+	}
+	return nil, false
+}
+
+// AudienceByName returns the audience with the given name, compared
+// case-insensitively.
+func (c *ProjectConfig) AudienceByName(name string) (*Audience, bool) {
+	for i := range c.Audiences {
+		if strings.EqualFold(c.Audiences[i].Name, name) {
+			return &c.Audiences[i], true
+		}
+	}
+	return nil, false
+}
+
+// RelationshipType returns the configured link relationship, upper-cased, or
+// MENTIONS when none is set.
+func (l LinksConfig) RelationshipType() string {
+	rel := strings.ToUpper(strings.TrimSpace(l.Relationship))
+	if rel == "" {
+		return DefaultLinkRelationship
+	}
+	return rel
 }
 
 func LoadProjectConfig(path string) (*ProjectConfig, error) {
@@ -38,6 +118,10 @@ func LoadProjectConfig(path string) (*ProjectConfig, error) {
 		return nil, fmt.Errorf("loading project config: %w", err)
 	}
 
+	for i, token := range cfg.Server.Tokens {
+		cfg.Server.Tokens[i] = strings.TrimSpace(os.ExpandEnv(token))
+	}
+
 	if err := validateProjectConfig(&cfg); err != nil {
 		return nil, fmt.Errorf("loading project config: %w", err)
 	}
@@ -59,6 +143,37 @@ func validateProjectConfig(cfg *ProjectConfig) error {
 		return fmt.Errorf("at least one layer is required")
 	}
 
+	if rel := cfg.Links.RelationshipType(); strings.Trim(rel, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_") != "" {
+		return fmt.Errorf("links relationship must contain only letters, digits and underscores: %s", cfg.Links.Relationship)
+	}
+
+	audiences := make(map[string]struct{})
+	for i, audience := range cfg.Audiences {
+		if strings.TrimSpace(audience.Name) == "" {
+			return fmt.Errorf("audience %d name is required", i)
+		}
+		key := strings.ToLower(audience.Name)
+		if _, exists := audiences[key]; exists {
+			return fmt.Errorf("duplicate audience name: %s", audience.Name)
+		}
+		audiences[key] = struct{}{}
+		if strings.TrimSpace(audience.Property) == "" {
+			return fmt.Errorf("audience %s property is required", audience.Name)
+		}
+		if strings.Contains(audience.Property, `"`) {
+			return fmt.Errorf("audience %s property cannot contain double quotes", audience.Name)
+		}
+		if len(audience.Hidden) == 0 {
+			return fmt.Errorf("audience %s hides no values", audience.Name)
+		}
+	}
+
+	for i, token := range cfg.Server.Tokens {
+		if token == "" {
+			return fmt.Errorf("server token %d is empty", i)
+		}
+	}
+
 	seen := make(map[string]struct{})
 	layersByName := make(map[string]Layer)
 	for i, layer := range cfg.Layers {
@@ -74,6 +189,12 @@ func validateProjectConfig(cfg *ProjectConfig) error {
 		}
 		seen[key] = struct{}{}
 		layersByName[key] = layer
+		if layer.Drafts != "" && !withinAny(layer.Drafts, layer.Paths) {
+			return fmt.Errorf("layer %s drafts path %s is not inside its paths", layer.Name, layer.Drafts)
+		}
+		if err := validatePathRules(layer); err != nil {
+			return err
+		}
 	}
 
 	for _, layer := range cfg.Layers {
@@ -127,3 +248,17 @@ func validateProjectConfig(cfg *ProjectConfig) error {
 
 	return nil
 }
+
+// withinAny reports whether path is one of roots or below one of them.
+func withinAny(path string, roots []string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
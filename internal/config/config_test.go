@@ -52,6 +52,80 @@ func TestLoadProjectConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("links relationship defaults to MENTIONS", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\n")
+		cfg, err := LoadProjectConfig(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cfg.Links.RelationshipType() != "MENTIONS" {
+			t.Fatalf("expected MENTIONS, got %q", cfg.Links.RelationshipType())
+		}
+	})
+
+	t.Run("invalid links relationship", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\nlinks:\n  relationship: refers-to\n")
+		if _, err := LoadProjectConfig(path); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("audiences", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\naudiences:\n  - name: player\n    property: visibility\n    hidden: [gm]\n")
+		cfg, err := LoadProjectConfig(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		audience, ok := cfg.AudienceByName("Player")
+		if !ok || audience.Property != "visibility" || len(audience.Hidden) != 1 {
+			t.Fatalf("unexpected audience: %#v", audience)
+		}
+	})
+
+	t.Run("audience without hidden values", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\naudiences:\n  - name: player\n    property: visibility\n")
+		if _, err := LoadProjectConfig(path); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("server tokens from environment", func(t *testing.T) {
+		t.Setenv("LORECRAFT_TEST_TOKEN", "s3cret")
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\nserver:\n  tokens: [\"${LORECRAFT_TEST_TOKEN}\", literal]\n")
+		cfg, err := LoadProjectConfig(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(cfg.Server.Tokens) != 2 || cfg.Server.Tokens[0] != "s3cret" || cfg.Server.Tokens[1] != "literal" {
+			t.Fatalf("unexpected tokens: %#v", cfg.Server.Tokens)
+		}
+	})
+
+	t.Run("server token from unset variable", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\nserver:\n  tokens: [\"${LORECRAFT_TEST_UNSET_TOKEN}\"]\n")
+		if _, err := LoadProjectConfig(path); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("drafts inside layer paths", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore/]\n    drafts: ./lore/drafts\n")
+		cfg, err := LoadProjectConfig(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cfg.Layers[0].Drafts != "./lore/drafts" {
+			t.Fatalf("unexpected drafts path: %q", cfg.Layers[0].Drafts)
+		}
+	})
+
+	t.Run("drafts outside layer paths", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\n    drafts: ./lore-drafts\n")
+		if _, err := LoadProjectConfig(path); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
 	t.Run("duplicate layer names", func(t *testing.T) {
 		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\n  - name: Setting\n    paths: [./lore2]\n")
 		if _, err := LoadProjectConfig(path); err == nil {
@@ -80,6 +154,20 @@ func TestLoadProjectConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("path rule without type or defaults", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\n    rules:\n      - glob: npcs\n")
+		if _, err := LoadProjectConfig(path); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("invalid include glob", func(t *testing.T) {
+		path := writeTempConfig(t, "project: test\nversion: 1\ndatabase:\n  dsn: \"postgres://localhost:5432/lorecraft\"\nlayers:\n  - name: setting\n    paths: [./lore]\n    include: [\"npcs/[\"]\n")
+		if _, err := LoadProjectConfig(path); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
 	t.Run("file not found", func(t *testing.T) {
 		if _, err := LoadProjectConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
 			t.Fatalf("expected error")
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SchemaChange describes one difference between two schemas.
+type SchemaChange struct {
+	// Kind is "added", "removed", "renamed" or "changed".
+	Kind string
+	// What names the changed part, such as "entity type npc",
+	// "property npc.role", "field npc.faction" or "relationship MEMBER_OF".
+	What   string
+	Detail string
+}
+
+func (c SchemaChange) String() string {
+	if c.Detail == "" {
+		return c.What + " " + c.Kind
+	}
+	return c.What + " " + c.Kind + ": " + c.Detail
+}
+
+// PropertyRename moves the stored values of a property of an entity type.
+type PropertyRename struct {
+	EntityType string
+	From       string
+	To         string
+}
+
+// RelationshipRename renames stored edges.
+type RelationshipRename struct {
+	From string
+	To   string
+}
+
+// SchemaDiff is what changed between the schema data was ingested with and
+// the current one.
+type SchemaDiff struct {
+	Changes []SchemaChange
+	// Reingest lists the entity types, in schema order, whose stored entities
+	// no longer match their definition and must be ingested again.
+	Reingest            []string
+	PropertyRenames     []PropertyRename
+	RelationshipRenames []RelationshipRename
+}
+
+// Fingerprint hashes the entity and relationship types of the schema, so two
+// schemas that differ only in layout or comments share a fingerprint.
+func (s *Schema) Fingerprint() string {
+	if s == nil {
+		return ""
+	}
+	data, _ := json.Marshal(struct {
+		Version           int
+		EntityTypes       []EntityType
+		RelationshipTypes []RelationshipType
+	}{s.Version, s.EntityTypes, s.RelationshipTypes})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// definition encodes what decides how entities of the type are stored.
+func (e *EntityType) definition() string {
+	data, _ := json.Marshal(struct {
+		Abstract      bool
+		Properties    []Property
+		FieldMappings []FieldMapping
+	}{e.Abstract, e.Properties, e.FieldMappings})
+	return string(data)
+}
+
+// DiffSchemas compares the schema stored data was ingested with to the
+// current one. Changes to inherited members are reported once, on the type
+// that declares them, but every type inheriting them is re-ingested.
+func DiffSchemas(old, current *Schema) SchemaDiff {
+	var diff SchemaDiff
+
+	for i := range current.EntityTypes {
+		entity := &current.EntityTypes[i]
+		previous, ok := old.EntityTypeByName(entity.Name)
+		if !ok {
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: "added", What: "entity type " + entity.Name})
+			continue
+		}
+		diff.diffEntityType(previous, entity)
+		if previous.definition() != entity.definition() {
+			diff.Reingest = append(diff.Reingest, entity.Name)
+		}
+	}
+	for _, entity := range old.EntityTypes {
+		if !current.IsValidEntityType(entity.Name) {
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: "removed", What: "entity type " + entity.Name, Detail: "its entities are dropped"})
+		}
+	}
+
+	renamed := make(map[string]bool)
+	for _, rel := range current.RelationshipTypes {
+		previous, ok := old.RelationshipTypeByName(rel.Name)
+		if !ok {
+			if _, had := old.RelationshipTypeByName(rel.RenamedFrom); rel.RenamedFrom != "" && had {
+				renamed[strings.ToLower(rel.RenamedFrom)] = true
+				diff.RelationshipRenames = append(diff.RelationshipRenames, RelationshipRename{From: rel.RenamedFrom, To: rel.Name})
+				diff.Changes = append(diff.Changes, SchemaChange{Kind: "renamed", What: "relationship " + rel.RenamedFrom, Detail: "to " + rel.Name})
+				continue
+			}
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: "added", What: "relationship " + rel.Name})
+			continue
+		}
+		var details []string
+		if !strings.EqualFold(previous.Inverse, rel.Inverse) {
+			details = append(details, fmt.Sprintf("inverse %s (was %s)", orNone(rel.Inverse), orNone(previous.Inverse)))
+		}
+		if previous.Symmetric != rel.Symmetric {
+			details = append(details, flagChange("symmetric", rel.Symmetric))
+		}
+		if len(details) > 0 {
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: "changed", What: "relationship " + rel.Name, Detail: strings.Join(details, ", ")})
+		}
+	}
+	for _, rel := range old.RelationshipTypes {
+		if !current.IsValidRelationshipType(rel.Name) && !renamed[strings.ToLower(rel.Name)] {
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: "removed", What: "relationship " + rel.Name})
+		}
+	}
+
+	return diff
+}
+
+func (d *SchemaDiff) diffEntityType(old, current *EntityType) {
+	what := "entity type " + current.Name
+	if old.Abstract != current.Abstract {
+		d.Changes = append(d.Changes, SchemaChange{Kind: "changed", What: what, Detail: flagChange("abstract", current.Abstract)})
+	}
+	if !slices.EqualFunc(old.Extends, current.Extends, strings.EqualFold) {
+		d.Changes = append(d.Changes, SchemaChange{Kind: "changed", What: what, Detail: fmt.Sprintf("extends %s (was %s)", orNone(strings.Join(current.Extends, ", ")), orNone(strings.Join(old.Extends, ", ")))})
+	}
+
+	renamed := make(map[string]bool)
+	for _, prop := range current.Properties {
+		declared := prop.InheritedFrom == ""
+		what := "property " + current.Name + "." + prop.Name
+		previous, ok := old.PropertyByName(prop.Name)
+		if !ok {
+			if _, had := old.PropertyByName(prop.RenamedFrom); prop.RenamedFrom != "" && had {
+				renamed[prop.RenamedFrom] = true
+				d.PropertyRenames = append(d.PropertyRenames, PropertyRename{EntityType: current.Name, From: prop.RenamedFrom, To: prop.Name})
+				if declared {
+					d.Changes = append(d.Changes, SchemaChange{Kind: "renamed", What: "property " + current.Name + "." + prop.RenamedFrom, Detail: "to " + prop.Name})
+				}
+				continue
+			}
+			if declared {
+				d.Changes = append(d.Changes, SchemaChange{Kind: "added", What: what})
+			}
+			continue
+		}
+		if details := propertyChanges(previous, &prop); declared && len(details) > 0 {
+			d.Changes = append(d.Changes, SchemaChange{Kind: "changed", What: what, Detail: strings.Join(details, ", ")})
+		}
+	}
+	for _, prop := range old.Properties {
+		if _, ok := current.PropertyByName(prop.Name); ok || renamed[prop.Name] || prop.InheritedFrom != "" {
+			continue
+		}
+		d.Changes = append(d.Changes, SchemaChange{Kind: "removed", What: "property " + current.Name + "." + prop.Name})
+	}
+
+	for _, mapping := range current.FieldMappings {
+		if mapping.InheritedFrom != "" {
+			continue
+		}
+		what := "field " + current.Name + "." + mapping.Field
+		previous, ok := fieldMapping(old, mapping.Field)
+		if !ok {
+			d.Changes = append(d.Changes, SchemaChange{Kind: "added", What: what})
+			continue
+		}
+		var details []string
+		if !strings.EqualFold(previous.Relationship, mapping.Relationship) {
+			details = append(details, fmt.Sprintf("relationship %s (was %s)", mapping.Relationship, previous.Relationship))
+		}
+		if !slices.EqualFunc(previous.TargetType, mapping.TargetType, strings.EqualFold) {
+			details = append(details, fmt.Sprintf("target type %s (was %s)", anyType(mapping.TargetType), anyType(previous.TargetType)))
+		}
+		if len(details) > 0 {
+			d.Changes = append(d.Changes, SchemaChange{Kind: "changed", What: what, Detail: strings.Join(details, ", ")})
+		}
+	}
+	for _, mapping := range old.FieldMappings {
+		if _, ok := fieldMapping(current, mapping.Field); ok || mapping.InheritedFrom != "" {
+			continue
+		}
+		d.Changes = append(d.Changes, SchemaChange{Kind: "removed", What: "field " + current.Name + "." + mapping.Field})
+	}
+}
+
+func propertyChanges(old, current *Property) []string {
+	var details []string
+	if !strings.EqualFold(old.Type, current.Type) {
+		details = append(details, fmt.Sprintf("type %s (was %s)", current.Type, old.Type))
+	}
+	var dropped, added []string
+	for _, value := range old.Values {
+		if !containsStringCI(current.Values, value) {
+			dropped = append(dropped, value)
+		}
+	}
+	for _, value := range current.Values {
+		if !containsStringCI(old.Values, value) {
+			added = append(added, value)
+		}
+	}
+	if len(dropped) > 0 {
+		details = append(details, "values dropped: "+strings.Join(dropped, ", "))
+	}
+	if len(added) > 0 {
+		details = append(details, "values added: "+strings.Join(added, ", "))
+	}
+	if old.Default != current.Default {
+		details = append(details, fmt.Sprintf("default %s (was %s)", orNone(current.Default), orNone(old.Default)))
+	}
+	if old.Required != current.Required {
+		details = append(details, flagChange("required", current.Required))
+	}
+	return details
+}
+
+func fieldMapping(entity *EntityType, field string) (*FieldMapping, bool) {
+	for i := range entity.FieldMappings {
+		if entity.FieldMappings[i].Field == field {
+			return &entity.FieldMappings[i], true
+		}
+	}
+	return nil, false
+}
+
+func flagChange(flag string, now bool) string {
+	if now {
+		return "now " + flag
+	}
+	return "no longer " + flag
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+func anyType(types []string) string {
+	if len(types) == 0 {
+		return "any"
+	}
+	return strings.Join(types, ", ")
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const diffSchemaBefore = `version: 1
+entity_types:
+  - name: character
+    abstract: true
+    properties:
+      - { name: status, type: enum, values: [alive, dead, unknown] }
+  - name: npc
+    extends: character
+    properties:
+      - { name: occupation, type: string }
+      - { name: mood, type: string }
+    field_mappings:
+      - { field: faction, relationship: MEMBER, target_type: [faction] }
+  - name: faction
+  - name: rumour
+relationship_types:
+  - name: MEMBER
+  - name: ALLY_OF
+`
+
+const diffSchemaAfter = `version: 1
+entity_types:
+  - name: character
+    abstract: true
+    properties:
+      - { name: status, type: enum, values: [alive, dead] }
+  - name: npc
+    extends: character
+    properties:
+      - { name: role, type: string, renamed_from: occupation }
+      - { name: mood, type: string }
+    field_mappings:
+      - { field: faction, relationship: MEMBER_OF, target_type: [faction] }
+  - name: faction
+    properties:
+      - { name: motto, type: string }
+  - name: deity
+relationship_types:
+  - name: MEMBER_OF
+    renamed_from: MEMBER
+  - name: ALLY_OF
+    symmetric: true
+`
+
+func TestDiffSchemas(t *testing.T) {
+	before, err := ParseSchema([]byte(diffSchemaBefore))
+	if err != nil {
+		t.Fatalf("parse before: %v", err)
+	}
+	after, err := ParseSchema([]byte(diffSchemaAfter))
+	if err != nil {
+		t.Fatalf("parse after: %v", err)
+	}
+
+	diff := DiffSchemas(before, after)
+
+	var changes []string
+	for _, change := range diff.Changes {
+		changes = append(changes, change.String())
+	}
+	want := []string{
+		"property character.status changed: values dropped: unknown",
+		"property npc.occupation renamed: to role",
+		"field npc.faction changed: relationship MEMBER_OF (was MEMBER)",
+		"property faction.motto added",
+		"entity type deity added",
+		"entity type rumour removed: its entities are dropped",
+		"relationship MEMBER renamed: to MEMBER_OF",
+		"relationship ALLY_OF changed: now symmetric",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("unexpected changes:\n%s", strings.Join(changes, "\n"))
+	}
+
+	if !reflect.DeepEqual(diff.Reingest, []string{"character", "npc", "faction"}) {
+		t.Fatalf("expected types inheriting a change to be re-ingested, got %v", diff.Reingest)
+	}
+	if !reflect.DeepEqual(diff.PropertyRenames, []PropertyRename{{EntityType: "npc", From: "occupation", To: "role"}}) {
+		t.Fatalf("unexpected property renames: %#v", diff.PropertyRenames)
+	}
+	if !reflect.DeepEqual(diff.RelationshipRenames, []RelationshipRename{{From: "MEMBER", To: "MEMBER_OF"}}) {
+		t.Fatalf("unexpected relationship renames: %#v", diff.RelationshipRenames)
+	}
+}
+
+func TestSchemaFingerprint(t *testing.T) {
+	schema, err := ParseSchema([]byte(diffSchemaBefore))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	reformatted, err := ParseSchema([]byte("# Campaign schema\n" + strings.ReplaceAll(diffSchemaBefore, "{ name: mood, type: string }", "{name: mood,   type: string}")))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if schema.Fingerprint() != reformatted.Fingerprint() {
+		t.Fatalf("expected layout and comments not to change the fingerprint")
+	}
+
+	changed, err := ParseSchema([]byte(diffSchemaAfter))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if schema.Fingerprint() == changed.Fingerprint() {
+		t.Fatalf("expected a changed schema to change the fingerprint")
+	}
+	if len(DiffSchemas(schema, reformatted).Changes) != 0 {
+		t.Fatalf("expected no changes between equivalent schemas")
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// resolveInheritance checks the extends declarations of the schema and gives
+// every entity type the properties and field mappings of its ancestors.
+// Parents are visited in the order they are listed. Two parents may pass on
+// the same member only if both define it alike or the child declares it.
+func resolveInheritance(s *Schema) error {
+	index := make(map[string]int, len(s.EntityTypes))
+	for i, entity := range s.EntityTypes {
+		index[strings.ToLower(entity.Name)] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		resolved
+	)
+	state := make([]int, len(s.EntityTypes))
+	// stack holds the types being resolved, so a cycle can be reported whole.
+	var stack []string
+	var resolve func(i int) error
+	resolve = func(i int) error {
+		entity := &s.EntityTypes[i]
+		switch state[i] {
+		case resolved:
+			return nil
+		case visiting:
+			start := slices.IndexFunc(stack, func(name string) bool { return strings.EqualFold(name, entity.Name) })
+			chain := append(slices.Clone(stack[start:]), entity.Name)
+			return fmt.Errorf("entity types extend each other in a cycle: %s", strings.Join(chain, " → "))
+		}
+		state[i] = visiting
+		stack = append(stack, entity.Name)
+
+		var parents []*EntityType
+		for _, name := range entity.Extends {
+			p, ok := index[strings.ToLower(strings.TrimSpace(name))]
+			if !ok {
+				return fmt.Errorf("entity type %s extends unknown type %s", entity.Name, name)
+			}
+			if err := resolve(p); err != nil {
+				return err
+			}
+			parents = append(parents, &s.EntityTypes[p])
+		}
+
+		var properties []Property
+		var mappings []FieldMapping
+		for _, parent := range parents {
+			if !containsStringCI(entity.ancestors, parent.Name) {
+				entity.ancestors = append(entity.ancestors, parent.Name)
+			}
+			for _, prop := range parent.Properties {
+				if prop.InheritedFrom == "" {
+					prop.InheritedFrom = parent.Name
+				}
+				if _, declared := entity.PropertyByName(prop.Name); declared {
+					continue
+				}
+				merged, err := inheritMember(entity.Name, "property", prop.Name, properties, prop, func(p Property) string { return p.Name })
+				if err != nil {
+					return err
+				}
+				properties = merged
+			}
+			for _, mapping := range parent.FieldMappings {
+				if mapping.InheritedFrom == "" {
+					mapping.InheritedFrom = parent.Name
+				}
+				if declaresMapping(entity, mapping.Field) {
+					continue
+				}
+				merged, err := inheritMember(entity.Name, "field mapping", mapping.Field, mappings, mapping, func(m FieldMapping) string { return m.Field })
+				if err != nil {
+					return err
+				}
+				mappings = merged
+			}
+		}
+		for _, parent := range parents {
+			for _, ancestor := range parent.ancestors {
+				if !containsStringCI(entity.ancestors, ancestor) {
+					entity.ancestors = append(entity.ancestors, ancestor)
+				}
+			}
+		}
+
+		entity.Properties = append(properties, entity.Properties...)
+		entity.FieldMappings = append(mappings, entity.FieldMappings...)
+		state[i] = resolved
+		stack = stack[:len(stack)-1]
+		return nil
+	}
+
+	for i := range s.EntityTypes {
+		if err := resolve(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inheritMember appends member to members unless one of the same name is
+// already inherited, in which case both must be defined alike.
+func inheritMember[T any](typeName, kind, name string, members []T, member T, nameOf func(T) string) ([]T, error) {
+	for _, existing := range members {
+		if !strings.EqualFold(nameOf(existing), name) {
+			continue
+		}
+		if !sameMember(existing, member) {
+			return nil, fmt.Errorf("entity type %s inherits conflicting definitions of %s %s; declare it to choose one", typeName, kind, name)
+		}
+		return members, nil
+	}
+	return append(members, member), nil
+}
+
+// sameMember compares two members regardless of where they were declared.
+func sameMember[T any](a, b T) bool {
+	x, y := reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()
+	x.FieldByName("InheritedFrom").SetString("")
+	y.FieldByName("InheritedFrom").SetString("")
+	return reflect.DeepEqual(a, b)
+}
+
+func declaresMapping(entity *EntityType, field string) bool {
+	for _, mapping := range entity.FieldMappings {
+		if strings.EqualFold(mapping.Field, field) {
+			return true
+		}
+	}
+	return false
+}
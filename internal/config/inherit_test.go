@@ -0,0 +1,102 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const inheritanceSchema = `version: 1
+entity_types:
+  - name: character
+    abstract: true
+    properties:
+      - { name: status, type: enum, values: [alive, dead], default: alive }
+      - { name: role, type: string }
+    field_mappings:
+      - { field: location, relationship: LOCATED_IN, target_type: [place] }
+  - name: divine
+    abstract: true
+    properties:
+      - { name: domain, type: string }
+  - name: npc
+    extends: character
+    properties:
+      - { name: role, type: string, required: true }
+  - name: deity
+    extends: [character, divine]
+  - name: avatar
+    extends: deity
+  - name: place
+relationship_types:
+  - name: LOCATED_IN
+`
+
+func TestLoadSchema_Inheritance(t *testing.T) {
+	schema, err := LoadSchema(writeTempSchema(t, inheritanceSchema))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	npc, _ := schema.EntityTypeByName("npc")
+	status, ok := npc.PropertyByName("status")
+	if !ok || status.InheritedFrom != "character" || status.Default != "alive" {
+		t.Fatalf("expected status to be inherited from character, got %#v", status)
+	}
+	role, _ := npc.PropertyByName("role")
+	if role.InheritedFrom != "" || !role.Required || len(npc.Properties) != 2 {
+		t.Fatalf("expected the declared role to replace the inherited one, got %#v", npc.Properties)
+	}
+	if len(npc.FieldMappings) != 1 || npc.FieldMappings[0].InheritedFrom != "character" {
+		t.Fatalf("expected the location mapping to be inherited, got %#v", npc.FieldMappings)
+	}
+
+	avatar, _ := schema.EntityTypeByName("avatar")
+	if !reflect.DeepEqual(avatar.Ancestors(), []string{"deity", "character", "divine"}) {
+		t.Fatalf("unexpected ancestors: %#v", avatar.Ancestors())
+	}
+	if domain, ok := avatar.PropertyByName("domain"); !ok || domain.InheritedFrom != "divine" {
+		t.Fatalf("expected domain from the second parent, got %#v", domain)
+	}
+
+	if !schema.IsA("avatar", "Character") || schema.IsA("place", "character") || !schema.IsA("dragon", "dragon") {
+		t.Fatalf("unexpected IsA results")
+	}
+	if got := schema.Subtypes("character"); !reflect.DeepEqual(got, []string{"character", "npc", "deity", "avatar"}) {
+		t.Fatalf("unexpected subtypes: %#v", got)
+	}
+}
+
+func TestLoadSchema_InheritanceErrors(t *testing.T) {
+	cases := map[string]struct {
+		types string
+		want  string
+	}{
+		"unknown parent": {
+			types: "  - name: npc\n    extends: person\n",
+			want:  "extends unknown type person",
+		},
+		"cycle": {
+			types: "  - name: a\n    extends: b\n  - name: b\n    extends: c\n  - name: c\n    extends: b\n",
+			want:  "in a cycle: b → c → b",
+		},
+		"extends itself": {
+			types: "  - name: a\n    extends: a\n",
+			want:  "in a cycle: a → a",
+		},
+		"conflicting parents": {
+			types: "  - name: a\n    properties:\n      - { name: rank, type: string }\n" +
+				"  - name: b\n    properties:\n      - { name: rank, type: integer }\n" +
+				"  - name: c\n    extends: [a, b]\n",
+			want: "conflicting definitions of property rank",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadSchema(writeTempSchema(t, "version: 1\nentity_types:\n"+c.types+"relationship_types:\n  - name: RELATED_TO\n"))
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Fatalf("expected %q, got %v", c.want, err)
+			}
+		})
+	}
+}
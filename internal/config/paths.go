@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PathRule gives the files of a layer that match Glob an entity type and
+// default frontmatter values. A file's own frontmatter wins over both.
+type PathRule struct {
+	Glob     string         `yaml:"glob"`
+	Type     string         `yaml:"type"`
+	Defaults map[string]any `yaml:"defaults"`
+}
+
+// Frontmatter returns the frontmatter values the rule supplies, type
+// included.
+func (r PathRule) Frontmatter() map[string]any {
+	values := make(map[string]any, len(r.Defaults)+1)
+	for key, value := range r.Defaults {
+		values[key] = value
+	}
+	if r.Type != "" {
+		values["type"] = r.Type
+	}
+	return values
+}
+
+// Includes reports whether the layer ingests the file at path: it must match
+// one of the Include globs, if there are any, and none of the Exclude globs.
+func (l Layer) Includes(path string) bool {
+	rel, ok := l.relPath(path)
+	if !ok {
+		return false
+	}
+	if len(l.Include) > 0 && !matchAny(l.Include, rel) {
+		return false
+	}
+	return !matchAny(l.Exclude, rel)
+}
+
+// RuleFor returns the first rule whose glob matches path.
+func (l Layer) RuleFor(path string) (PathRule, bool) {
+	rel, ok := l.relPath(path)
+	if !ok {
+		return PathRule{}, false
+	}
+	for _, rule := range l.Rules {
+		if MatchGlob(rule.Glob, rel) {
+			return rule, true
+		}
+	}
+	return PathRule{}, false
+}
+
+// relPath returns path relative to the layer path it lies under, with
+// forward slashes.
+func (l Layer) relPath(path string) (string, bool) {
+	for _, root := range l.Paths {
+		if !withinAny(path, []string{root}) {
+			continue
+		}
+		rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(path))
+		if err != nil {
+			continue
+		}
+		return filepath.ToSlash(rel), true
+	}
+	return "", false
+}
+
+// MatchGlob reports whether the slash-separated path name matches pattern.
+// Each segment of pattern is matched as by path.Match, and a "**" segment
+// matches any number of directories. A pattern that matches a directory
+// matches every file below it, so "npcs" and "npcs/**" are the same.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateGlob(pattern string) error {
+	if strings.Trim(pattern, "/") == "" {
+		return fmt.Errorf("empty glob")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return nil
+}
+
+func validatePathRules(layer Layer) error {
+	for _, pattern := range layer.Include {
+		if err := validateGlob(pattern); err != nil {
+			return fmt.Errorf("layer %s include: %w", layer.Name, err)
+		}
+	}
+	for _, pattern := range layer.Exclude {
+		if err := validateGlob(pattern); err != nil {
+			return fmt.Errorf("layer %s exclude: %w", layer.Name, err)
+		}
+	}
+	for i, rule := range layer.Rules {
+		if err := validateGlob(rule.Glob); err != nil {
+			return fmt.Errorf("layer %s rule %d: %w", layer.Name, i, err)
+		}
+		if strings.TrimSpace(rule.Type) == "" && len(rule.Defaults) == 0 {
+			return fmt.Errorf("layer %s rule %s sets neither type nor defaults", layer.Name, rule.Glob)
+		}
+		for _, key := range []string{"type", "title"} {
+			if _, ok := rule.Defaults[key]; ok {
+				return fmt.Errorf("layer %s rule %s cannot default %s", layer.Name, rule.Glob, key)
+			}
+		}
+	}
+	return nil
+}
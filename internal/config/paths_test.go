@@ -0,0 +1,67 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"npcs/*.md", "npcs/mira.md", true},
+		{"npcs/*.md", "npcs/harbour/mira.md", false},
+		{"npcs/**/*.md", "npcs/mira.md", true},
+		{"npcs/**/*.md", "npcs/harbour/mira.md", true},
+		{"npcs", "npcs/harbour/mira.md", true},
+		{"**/archive", "npcs/archive/old.md", true},
+		{"**/archive", "archive.md", false},
+		{"*.md", "npcs/mira.md", false},
+		{"*.md", "mira.md", true},
+	}
+	for _, c := range cases {
+		if got := MatchGlob(c.pattern, c.name); got != c.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", c.pattern, c.name, got, c.want)
+		}
+	}
+}
+
+func TestLayer_IncludesAndRuleFor(t *testing.T) {
+	root := filepath.Join("lore")
+	layer := Layer{
+		Name:    "setting",
+		Paths:   []string{root},
+		Include: []string{"npcs", "places"},
+		Exclude: []string{"**/drafts"},
+		Rules: []PathRule{
+			{Glob: "npcs/villains/*.md", Type: "npc", Defaults: map[string]any{"status": "at large"}},
+			{Glob: "npcs", Type: "npc"},
+		},
+	}
+
+	if !layer.Includes(filepath.Join(root, "npcs", "mira.md")) {
+		t.Fatalf("expected an included file")
+	}
+	if layer.Includes(filepath.Join(root, "notes", "ideas.md")) {
+		t.Fatalf("expected files outside the include globs to be left out")
+	}
+	if layer.Includes(filepath.Join(root, "npcs", "drafts", "new.md")) {
+		t.Fatalf("expected excluded files to be left out")
+	}
+	if layer.Includes(filepath.Join("elsewhere", "npcs", "mira.md")) {
+		t.Fatalf("expected files outside the layer paths to be left out")
+	}
+
+	rule, ok := layer.RuleFor(filepath.Join(root, "npcs", "villains", "harth.md"))
+	if !ok || rule.Defaults["status"] != "at large" {
+		t.Fatalf("expected the first matching rule, got %#v", rule)
+	}
+	if values := rule.Frontmatter(); values["type"] != "npc" || values["status"] != "at large" {
+		t.Fatalf("unexpected rule frontmatter: %#v", values)
+	}
+	if _, ok := layer.RuleFor(filepath.Join(root, "places", "westport.md")); ok {
+		t.Fatalf("expected no rule for places")
+	}
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PropertyTypeString    = "string"
+	PropertyTypeInteger   = "integer"
+	PropertyTypeNumber    = "number"
+	PropertyTypeBoolean   = "boolean"
+	PropertyTypeDate      = "date"
+	PropertyTypeList      = "list"
+	PropertyTypeEnum      = "enum"
+	PropertyTypeEntityRef = "entity-reference"
+)
+
+var propertyTypes = []string{
+	PropertyTypeString,
+	PropertyTypeInteger,
+	PropertyTypeNumber,
+	PropertyTypeBoolean,
+	PropertyTypeDate,
+	PropertyTypeList,
+	PropertyTypeEnum,
+	PropertyTypeEntityRef,
+}
+
+var dateLayouts = []string{"2006-01-02", time.RFC3339}
+
+// IsValidPropertyType reports whether name is a supported property type. An
+// empty type is accepted and treated as string.
+func IsValidPropertyType(name string) bool {
+	if strings.TrimSpace(name) == "" {
+		return true
+	}
+	for _, propType := range propertyTypes {
+		if strings.EqualFold(propType, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// BaseType returns the lower-cased property type, defaulting to string.
+func (p Property) BaseType() string {
+	propType := strings.ToLower(strings.TrimSpace(p.Type))
+	if propType == "" {
+		return PropertyTypeString
+	}
+	return propType
+}
+
+// Coerce converts a frontmatter value to the Go representation of the
+// property type: string, int, float64, bool, an ISO date string or []any.
+// Values that cannot be represented return an error and should be kept as
+// authored so validation can report them.
+func (p Property) Coerce(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch p.BaseType() {
+	case PropertyTypeString, PropertyTypeEnum, PropertyTypeEntityRef:
+		return coerceString(value)
+	case PropertyTypeInteger:
+		return coerceInteger(value)
+	case PropertyTypeNumber:
+		return coerceNumber(value)
+	case PropertyTypeBoolean:
+		return coerceBoolean(value)
+	case PropertyTypeDate:
+		return coerceDate(value)
+	case PropertyTypeList:
+		if items, ok := value.([]any); ok {
+			return items, nil
+		}
+		if items, ok := value.([]string); ok {
+			out := make([]any, 0, len(items))
+			for _, item := range items {
+				out = append(out, item)
+			}
+			return out, nil
+		}
+		if _, ok := value.(map[string]any); ok {
+			return nil, fmt.Errorf("expected list, got map")
+		}
+		return []any{value}, nil
+	default:
+		return value, nil
+	}
+}
+
+func coerceString(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool, int, int64, float64:
+		return fmt.Sprint(v), nil
+	case time.Time:
+		return formatDate(v), nil
+	default:
+		return nil, fmt.Errorf("expected string, got %T", value)
+	}
+}
+
+func coerceInteger(value any) (any, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("expected integer, got %v", v)
+		}
+		return int(v), nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("expected integer, got %q", v)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("expected integer, got %T", value)
+	}
+}
+
+func coerceNumber(value any) (any, error) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected number, got %q", v)
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("expected number, got %T", value)
+	}
+}
+
+func coerceBoolean(value any) (any, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "on":
+			return true, nil
+		case "false", "no", "off":
+			return false, nil
+		}
+		return nil, fmt.Errorf("expected boolean, got %q", v)
+	default:
+		return nil, fmt.Errorf("expected boolean, got %T", value)
+	}
+}
+
+func coerceDate(value any) (any, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return formatDate(v), nil
+	case string:
+		trimmed := strings.TrimSpace(v)
+		for _, layout := range dateLayouts {
+			if parsed, err := time.Parse(layout, trimmed); err == nil {
+				return formatDate(parsed), nil
+			}
+		}
+		return nil, fmt.Errorf("expected date (YYYY-MM-DD), got %q", v)
+	default:
+		return nil, fmt.Errorf("expected date, got %T", value)
+	}
+}
+
+func formatDate(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339)
+}
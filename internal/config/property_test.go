@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPropertyCoerce(t *testing.T) {
+	tests := []struct {
+		name     string
+		propType string
+		value    any
+		expected any
+		wantErr  bool
+	}{
+		{name: "string from int", propType: "string", value: 42, expected: "42"},
+		{name: "untyped as string", propType: "", value: "guard", expected: "guard"},
+		{name: "string rejects list", propType: "string", value: []any{"a"}, wantErr: true},
+		{name: "integer from string", propType: "integer", value: " 3 ", expected: 3},
+		{name: "integer from whole float", propType: "integer", value: float64(2), expected: 2},
+		{name: "integer rejects word", propType: "integer", value: "two", wantErr: true},
+		{name: "integer rejects fraction", propType: "integer", value: 2.5, wantErr: true},
+		{name: "number from int", propType: "number", value: 3, expected: float64(3)},
+		{name: "number from string", propType: "number", value: "1.25", expected: 1.25},
+		{name: "boolean from string", propType: "boolean", value: "yes", expected: true},
+		{name: "boolean rejects word", propType: "boolean", value: "maybe", wantErr: true},
+		{name: "date from time", propType: "date", value: time.Date(1243, 3, 12, 0, 0, 0, 0, time.UTC), expected: "1243-03-12"},
+		{name: "date from string", propType: "date", value: "2024-01-02", expected: "2024-01-02"},
+		{name: "date rejects prose", propType: "date", value: "12 Rainmoot 1243", wantErr: true},
+		{name: "list wraps scalar", propType: "list", value: "Harbor Ward", expected: []any{"Harbor Ward"}},
+		{name: "list keeps list", propType: "list", value: []any{"a", "b"}, expected: []any{"a", "b"}},
+		{name: "enum from bool", propType: "enum", value: true, expected: "true"},
+		{name: "entity reference", propType: "entity-reference", value: "Westport", expected: "Westport"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Property{Name: "p", Type: tt.propType}.Coerce(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Fatalf("expected %#v, got %#v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -15,12 +15,22 @@ type Schema struct {
 
 	entityIndex map[string]*EntityType
 	relIndex    map[string]*RelationshipType
+	source      []byte
 }
 
+// EntityType is a kind of entity. Once the schema is loaded, Properties and
+// FieldMappings hold the inherited members followed by the declared ones; a
+// declared member replaces an inherited one of the same name.
 type EntityType struct {
-	Name          string         `yaml:"name"`
+	Name string `yaml:"name"`
+	// Extends names the parent types, as one name or a list.
+	Extends TypeList `yaml:"extends"`
+	// Abstract types only exist to be extended; no entity can have one.
+	Abstract      bool           `yaml:"abstract"`
 	Properties    []Property     `yaml:"properties"`
 	FieldMappings []FieldMapping `yaml:"field_mappings"`
+
+	ancestors []string
 }
 
 type Property struct {
@@ -29,18 +39,48 @@ type Property struct {
 	Values   []string `yaml:"values"`
 	Default  string   `yaml:"default"`
 	Required bool     `yaml:"required"`
+	// RenamedFrom is the property's previous name. Ingestion moves stored
+	// values over and reads the old frontmatter key when the new one is unset.
+	RenamedFrom string `yaml:"renamed_from" json:"-"`
+	// InheritedFrom names the type that declared an inherited property.
+	InheritedFrom string `yaml:"-" json:"-"`
 }
 
 type FieldMapping struct {
 	Field        string   `yaml:"field"`
 	Relationship string   `yaml:"relationship"`
 	TargetType   []string `yaml:"target_type"`
+	// InheritedFrom names the type that declared an inherited mapping.
+	InheritedFrom string `yaml:"-" json:"-"`
+}
+
+// TypeList is a list of entity type names that may also be written as a
+// single name.
+type TypeList []string
+
+func (l *TypeList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = nil
+		if strings.TrimSpace(node.Value) != "" && node.Tag != "!!null" {
+			*l = TypeList{node.Value}
+		}
+		return nil
+	}
+	var names []string
+	if err := node.Decode(&names); err != nil {
+		return err
+	}
+	*l = names
+	return nil
 }
 
 type RelationshipType struct {
 	Name      string `yaml:"name"`
 	Inverse   string `yaml:"inverse"`
 	Symmetric bool   `yaml:"symmetric"`
+	// RenamedFrom is the relationship's previous name. Ingestion renames
+	// stored edges.
+	RenamedFrom string `yaml:"renamed_from" json:"-"`
 }
 
 func LoadSchema(path string) (*Schema, error) {
@@ -48,7 +88,11 @@ func LoadSchema(path string) (*Schema, error) {
 	if err != nil {
 		return nil, fmt.Errorf("loading schema: %w", err)
 	}
+	return ParseSchema(data)
+}
 
+// ParseSchema loads a schema from the contents of a schema file.
+func ParseSchema(data []byte) (*Schema, error) {
 	var schema Schema
 	if err := yaml.Unmarshal(data, &schema); err != nil {
 		return nil, fmt.Errorf("loading schema: %w", err)
@@ -57,6 +101,9 @@ func LoadSchema(path string) (*Schema, error) {
 	if err := validateSchema(&schema); err != nil {
 		return nil, fmt.Errorf("loading schema: %w", err)
 	}
+	if err := resolveInheritance(&schema); err != nil {
+		return nil, fmt.Errorf("loading schema: %w", err)
+	}
 
 	schema.entityIndex = make(map[string]*EntityType)
 	for i := range schema.EntityTypes {
@@ -69,6 +116,7 @@ func LoadSchema(path string) (*Schema, error) {
 		rel := &schema.RelationshipTypes[i]
 		schema.relIndex[strings.ToLower(rel.Name)] = rel
 	}
+	schema.source = data
 
 	return &schema, nil
 }
@@ -101,10 +149,30 @@ func validateSchema(s *Schema) error {
 			if _, exists := propNames[name]; exists {
 				return fmt.Errorf("entity type %s has duplicate property: %s", entity.Name, prop.Name)
 			}
+			// Stores address properties with quoted JSON path segments.
+			if strings.Contains(prop.Name, `"`) || strings.Contains(prop.RenamedFrom, `"`) {
+				return fmt.Errorf("entity type %s property %s: names cannot contain double quotes", entity.Name, prop.Name)
+			}
 			propNames[name] = struct{}{}
+			if !IsValidPropertyType(prop.Type) {
+				return fmt.Errorf("entity type %s property %s has unknown type: %s", entity.Name, prop.Name, prop.Type)
+			}
 			if strings.EqualFold(prop.Type, "enum") && len(prop.Values) == 0 {
 				return fmt.Errorf("entity type %s property %s enum has no values", entity.Name, prop.Name)
 			}
+			if prop.Default != "" {
+				if _, err := prop.Coerce(prop.Default); err != nil {
+					return fmt.Errorf("entity type %s property %s has invalid default: %w", entity.Name, prop.Name, err)
+				}
+				if strings.EqualFold(prop.Type, "enum") && !containsStringCI(prop.Values, prop.Default) {
+					return fmt.Errorf("entity type %s property %s default %q is not one of its values", entity.Name, prop.Name, prop.Default)
+				}
+			}
+		}
+		for _, prop := range entity.Properties {
+			if _, exists := propNames[strings.ToLower(prop.RenamedFrom)]; exists {
+				return fmt.Errorf("entity type %s property %s is renamed from %s, which is still declared", entity.Name, prop.Name, prop.RenamedFrom)
+			}
 		}
 	}
 
@@ -119,6 +187,11 @@ func validateSchema(s *Schema) error {
 		}
 		relNames[key] = struct{}{}
 	}
+	for _, rel := range s.RelationshipTypes {
+		if _, exists := relNames[strings.ToLower(rel.RenamedFrom)]; exists {
+			return fmt.Errorf("relationship type %s is renamed from %s, which is still declared", rel.Name, rel.RenamedFrom)
+		}
+	}
 
 	for _, entity := range s.EntityTypes {
 		for _, mapping := range entity.FieldMappings {
@@ -153,6 +226,74 @@ func (s *Schema) RelationshipTypeByName(name string) (*RelationshipType, bool) {
 	return rel, ok
 }
 
+func (e *EntityType) PropertyByName(name string) (*Property, bool) {
+	if e == nil {
+		return nil, false
+	}
+	for i := range e.Properties {
+		if e.Properties[i].Name == name {
+			return &e.Properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// PropertyRenamedFrom returns the property that was previously called name.
+func (e *EntityType) PropertyRenamedFrom(name string) (*Property, bool) {
+	if e == nil || name == "" {
+		return nil, false
+	}
+	for i := range e.Properties {
+		if e.Properties[i].RenamedFrom == name {
+			return &e.Properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// Ancestors returns the names of every type e extends, directly or not,
+// nearest first.
+func (e *EntityType) Ancestors() []string {
+	if e == nil {
+		return nil
+	}
+	return e.ancestors
+}
+
+// IsA reports whether typeName is ancestor or extends it, directly or not.
+// Names are compared case-insensitively, and a type missing from the schema
+// is only itself.
+func (s *Schema) IsA(typeName, ancestor string) bool {
+	if strings.EqualFold(typeName, ancestor) {
+		return true
+	}
+	entity, ok := s.EntityTypeByName(typeName)
+	return ok && containsStringCI(entity.ancestors, ancestor)
+}
+
+// Subtypes returns the names of the types that are name, or extend it, in
+// schema order.
+func (s *Schema) Subtypes(name string) []string {
+	if s == nil {
+		return nil
+	}
+	var names []string
+	for _, entity := range s.EntityTypes {
+		if s.IsA(entity.Name, name) {
+			names = append(names, entity.Name)
+		}
+	}
+	return names
+}
+
+// Source returns the contents the schema was parsed from.
+func (s *Schema) Source() []byte {
+	if s == nil {
+		return nil
+	}
+	return s.source
+}
+
 func (s *Schema) IsValidEntityType(name string) bool {
 	_, ok := s.EntityTypeByName(name)
 	return ok
@@ -166,3 +307,13 @@ func (s *Schema) IsValidRelationshipType(name string) bool {
 func (s *Schema) NodeLabel(entityType string) string {
 	return strings.ToUpper(entityType)
 }
+
+func containsStringCI(values []string, target string) bool {
+	targetLower := strings.ToLower(target)
+	for _, value := range values {
+		if strings.ToLower(value) == targetLower {
+			return true
+		}
+	}
+	return false
+}
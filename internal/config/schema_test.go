@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -38,12 +39,40 @@ func TestLoadSchema(t *testing.T) {
 		}
 	})
 
+	t.Run("unknown property type", func(t *testing.T) {
+		path := writeTempSchema(t, "version: 1\nentity_types:\n  - name: npc\n    properties:\n      - { name: age, type: decimal }\nrelationship_types:\n  - name: RELATED_TO\n")
+		if _, err := LoadSchema(path); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
+	t.Run("invalid property default", func(t *testing.T) {
+		path := writeTempSchema(t, "version: 1\nentity_types:\n  - name: npc\n    properties:\n      - { name: status, type: enum, values: [alive, dead], default: missing }\nrelationship_types:\n  - name: RELATED_TO\n")
+		if _, err := LoadSchema(path); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+
 	t.Run("field mapping references unknown relationship", func(t *testing.T) {
 		path := writeTempSchema(t, "version: 1\nentity_types:\n  - name: npc\n    field_mappings:\n      - { field: faction, relationship: MEMBER_OF }\nrelationship_types:\n  - name: RELATED_TO\n")
 		if _, err := LoadSchema(path); err == nil {
 			t.Fatalf("expected error")
 		}
 	})
+
+	t.Run("property name with a double quote", func(t *testing.T) {
+		path := writeTempSchema(t, "version: 1\nentity_types:\n  - name: npc\n    properties:\n      - { name: 'say \"hi\"', type: string }\nrelationship_types:\n  - name: RELATED_TO\n")
+		if _, err := LoadSchema(path); err == nil || !strings.Contains(err.Error(), "double quotes") {
+			t.Fatalf("expected a double quote error, got %v", err)
+		}
+	})
+
+	t.Run("property renamed from a declared property", func(t *testing.T) {
+		path := writeTempSchema(t, "version: 1\nentity_types:\n  - name: npc\n    properties:\n      - { name: role, type: string, renamed_from: mood }\n      - { name: mood, type: string }\nrelationship_types:\n  - name: RELATED_TO\n")
+		if _, err := LoadSchema(path); err == nil || !strings.Contains(err.Error(), "still declared") {
+			t.Fatalf("expected a renamed_from error, got %v", err)
+		}
+	})
 }
 
 func TestSchemaHelpers(t *testing.T) {
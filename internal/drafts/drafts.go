@@ -0,0 +1,406 @@
+// Package drafts writes entity files on behalf of MCP clients. Markdown stays
+// the source of truth: every change lands in a file inside a layer's drafts
+// path and is then ingested like any hand-made edit.
+package drafts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+	"lorecraft/internal/parser"
+)
+
+// Entity describes a new entity file. Fields holds frontmatter beyond title
+// and type: schema properties, field mapping fields such as location, and the
+// built-in related, tags and aliases lists.
+type Entity struct {
+	Name   string
+	Type   string
+	Fields map[string]any
+	Body   string
+}
+
+// Patch describes changes to an existing entity file. A nil value in Fields
+// removes that field; a nil Body keeps the current body.
+type Patch struct {
+	Fields map[string]any
+	Body   *string
+}
+
+// Create validates entity against the schema, writes it to a new file in the
+// drafts path of the named layer and ingests that file. It returns the path
+// written. Nothing is left on disk if ingestion fails.
+func Create(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema, db ingest.Store, layerName string, entity Entity) (string, error) {
+	layer, ok := cfg.LayerByName(layerName)
+	if !ok {
+		return "", fmt.Errorf("unknown layer: %s", layerName)
+	}
+	if layer.Drafts == "" {
+		return "", fmt.Errorf("layer %s has no drafts path", layer.Name)
+	}
+	name := strings.TrimSpace(entity.Name)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	entityType, ok := schema.EntityTypeByName(entity.Type)
+	if !ok {
+		return "", fmt.Errorf("unknown entity type: %s", entity.Type)
+	}
+	if entityType.Abstract {
+		return "", fmt.Errorf("entity type %s is abstract", entityType.Name)
+	}
+	if err := checkFields(entityType, entity.Fields); err != nil {
+		return "", err
+	}
+	if err := checkRequired(entityType, entity.Fields); err != nil {
+		return "", err
+	}
+
+	if err := db.EnsureSchema(ctx, schema); err != nil {
+		return "", fmt.Errorf("ensure schema: %w", err)
+	}
+	existing, err := db.ListEntities(ctx, "", layer.Name, "")
+	if err != nil {
+		return "", err
+	}
+	// db sees entities hidden from the caller's audience, so the error only
+	// echoes the name as given and never confirms what it matched.
+	for _, summary := range existing {
+		if strings.EqualFold(summary.Name, name) {
+			return "", fmt.Errorf("name %s is not available in layer %s", name, layer.Name)
+		}
+	}
+
+	path := filepath.Join(layer.Drafts, slug(name)+".md")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("file %s already exists", path)
+	}
+
+	front := &yaml.Node{Kind: yaml.MappingNode}
+	if err := setField(front, "title", name); err != nil {
+		return "", err
+	}
+	if err := setField(front, "type", entityType.Name); err != nil {
+		return "", err
+	}
+	for _, key := range fieldOrder(entityType, entity.Fields) {
+		if err := setField(front, key, entity.Fields[key]); err != nil {
+			return "", err
+		}
+	}
+	content, err := render(front, formatBody(entity.Body))
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(layer.Drafts, 0o755); err != nil {
+		return "", fmt.Errorf("creating drafts directory: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := ingestFile(ctx, cfg, schema, db, path); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+// Update applies patch to the file at path, which must lie inside the drafts
+// path of layerName, and ingests it. Frontmatter keys that the patch does not
+// touch keep their order, formatting and comments. The previous contents are
+// restored if ingestion fails.
+func Update(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema, db ingest.Store, layerName, path string, patch Patch) error {
+	layer, ok := cfg.LayerByName(layerName)
+	if !ok {
+		return fmt.Errorf("unknown layer: %s", layerName)
+	}
+	if !layer.InDrafts(path) {
+		return fmt.Errorf("%s is not inside the drafts path of layer %s; only drafts can be edited", path, layer.Name)
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if entities, err := parser.ParseAll(original); err == nil && len(entities) > 1 {
+		return fmt.Errorf("%s defines %d entities; only files with a single entity can be edited", path, len(entities))
+	}
+	yamlBytes, body, err := parser.SplitFrontmatter(original)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(yamlBytes, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("reading %s: %w", path, parser.ErrInvalidYAML)
+	}
+	front := doc.Content[0]
+
+	var current map[string]any
+	if err := front.Decode(&current); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	rule, _ := layer.RuleFor(path)
+	typeName, _ := current["type"].(string)
+	if typeName == "" {
+		typeName = rule.Type
+	}
+	entityType, ok := schema.EntityTypeByName(typeName)
+	if !ok {
+		return fmt.Errorf("unknown entity type: %s", typeName)
+	}
+	if err := checkFields(entityType, patch.Fields); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(patch.Fields))
+	for key := range patch.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := patch.Fields[key]
+		if value == nil {
+			removeField(front, key)
+			delete(current, key)
+			continue
+		}
+		if err := setField(front, key, value); err != nil {
+			return err
+		}
+		current[key] = value
+	}
+	// Values the layer's path rule supplies count as set.
+	for key, value := range rule.Defaults {
+		if _, ok := current[key]; !ok {
+			current[key] = value
+		}
+	}
+	if err := checkRequired(entityType, current); err != nil {
+		return err
+	}
+
+	if patch.Body != nil {
+		body = formatBody(*patch.Body)
+	}
+	content, err := render(front, body)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := ingestFile(ctx, cfg, schema, db, path); err != nil {
+		if restoreErr := os.WriteFile(path, original, 0o644); restoreErr != nil {
+			return errors.Join(err, fmt.Errorf("restoring %s: %w", path, restoreErr))
+		}
+		return err
+	}
+	return nil
+}
+
+// ingestFile runs an atomic incremental ingestion limited to path, so a file
+// that fails to ingest leaves the database as it was.
+func ingestFile(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema, db ingest.Store, path string) error {
+	result, err := ingest.Run(ctx, cfg, schema, db, ingest.Options{Files: []string{path}, Atomic: true})
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("ingesting %s: %w", path, errors.Join(result.Errors...))
+	}
+	return nil
+}
+
+// checkFields rejects fields the entity type does not declare and property
+// values that do not fit their declared type.
+func checkFields(entityType *config.EntityType, fields map[string]any) error {
+	for key, value := range fields {
+		switch key {
+		case "title", "type":
+			return fmt.Errorf("field %s cannot be set", key)
+		case "tags", "related", "aliases":
+			if value != nil && !isStringList(value) {
+				return fmt.Errorf("field %s must be a string or list of strings", key)
+			}
+			continue
+		}
+		if isFieldMapping(entityType, key) {
+			if value != nil && !isStringList(value) {
+				return fmt.Errorf("field %s must name an entity or list of entities", key)
+			}
+			continue
+		}
+		prop, ok := entityType.PropertyByName(key)
+		if !ok {
+			return fmt.Errorf("entity type %s has no property or field mapping %s", entityType.Name, key)
+		}
+		if value == nil {
+			continue
+		}
+		coerced, err := prop.Coerce(value)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", key, err)
+		}
+		if prop.BaseType() == config.PropertyTypeEnum && !containsFold(prop.Values, fmt.Sprint(coerced)) {
+			return fmt.Errorf("property %s must be one of %s", key, strings.Join(prop.Values, ", "))
+		}
+	}
+	return nil
+}
+
+// checkRequired reports the first required property that fields leave unset
+// and the schema cannot default.
+func checkRequired(entityType *config.EntityType, fields map[string]any) error {
+	for _, prop := range entityType.Properties {
+		if !prop.Required || prop.Default != "" {
+			continue
+		}
+		if value, ok := fields[prop.Name]; !ok || value == nil {
+			return fmt.Errorf("property %s is required for %s", prop.Name, entityType.Name)
+		}
+	}
+	return nil
+}
+
+// fieldOrder lists the keys of fields in the order the schema declares them,
+// followed by related, tags and aliases.
+func fieldOrder(entityType *config.EntityType, fields map[string]any) []string {
+	var keys []string
+	add := func(key string) {
+		if value, ok := fields[key]; ok && value != nil {
+			keys = append(keys, key)
+		}
+	}
+	for _, prop := range entityType.Properties {
+		add(prop.Name)
+	}
+	for _, mapping := range entityType.FieldMappings {
+		add(mapping.Field)
+	}
+	add("related")
+	add("tags")
+	add("aliases")
+	return keys
+}
+
+func setField(mapping *yaml.Node, key string, value any) error {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("encoding field %s: %w", key, err)
+	}
+	if node.Kind == yaml.SequenceNode {
+		node.Style = yaml.FlowStyle
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = node
+			return nil
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, node)
+	return nil
+}
+
+func removeField(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func render(front *yaml.Node, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(front); err != nil {
+		return nil, fmt.Errorf("encoding frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding frontmatter: %w", err)
+	}
+	buf.WriteString("---\n")
+	buf.WriteString(body)
+	return buf.Bytes(), nil
+}
+
+// formatBody separates body from the frontmatter by a blank line and ends it
+// with a newline, as hand-written lore files do.
+func formatBody(body string) string {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return ""
+	}
+	return "\n" + body + "\n"
+}
+
+// slug turns an entity name into a file name: lower-case letters and digits
+// separated by single hyphens.
+func slug(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	if b.Len() == 0 {
+		return "entity"
+	}
+	return b.String()
+}
+
+func isFieldMapping(entityType *config.EntityType, key string) bool {
+	for _, mapping := range entityType.FieldMappings {
+		if mapping.Field == key {
+			return true
+		}
+	}
+	return false
+}
+
+func isStringList(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return true
+	case []string:
+		return true
+	case []any:
+		for _, item := range v {
+			if _, ok := item.(string); !ok {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package drafts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/store/sqlite"
+)
+
+func newProject(t *testing.T) (*config.ProjectConfig, *config.Schema, *sqlite.Client) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "westlands",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers: []config.Layer{{
+			Name:      "setting",
+			Paths:     []string{dir},
+			Canonical: true,
+			Drafts:    filepath.Join(dir, "drafts"),
+		}},
+	}
+	schema, err := config.LoadSchema(filepath.Join("..", "..", "example", "schema.yaml"))
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+	client, err := sqlite.New(ctx, cfg.Database.DSN, cfg)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { client.Close(ctx) })
+	if err := os.WriteFile(filepath.Join(dir, "westport.md"), []byte("---\ntitle: Westport\ntype: settlement\n---\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	return cfg, schema, client
+}
+
+func TestCreate_WritesAndIngestsDraft(t *testing.T) {
+	ctx := context.Background()
+	cfg, schema, client := newProject(t)
+
+	path, err := Create(ctx, cfg, schema, client, "Setting", Entity{
+		Name: "Mira Voss",
+		Type: "npc",
+		Fields: map[string]any{
+			"tags":     []any{"smuggler"},
+			"location": "Westport",
+			"role":     "Harbor pilot",
+		},
+		Body: "Knows every reef in the bay.",
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if path != filepath.Join(cfg.Layers[0].Drafts, "mira-voss.md") {
+		t.Fatalf("unexpected path: %s", path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	expected := "---\ntitle: Mira Voss\ntype: npc\nrole: Harbor pilot\nlocation: Westport\ntags: [smuggler]\n---\n\nKnows every reef in the bay.\n"
+	if string(content) != expected {
+		t.Fatalf("unexpected file:\n%s", content)
+	}
+
+	mira, err := client.GetEntity(ctx, "Mira Voss", "npc")
+	if err != nil || mira == nil {
+		t.Fatalf("expected ingested entity, got %v %v", mira, err)
+	}
+	if mira.Properties["status"] != "alive" {
+		t.Fatalf("expected schema default, got %#v", mira.Properties)
+	}
+	rels, err := client.GetRelationships(ctx, "Mira Voss", "LOCATED_IN", "outgoing", 1)
+	if err != nil || len(rels) != 1 || rels[0].To.Name != "Westport" {
+		t.Fatalf("expected LOCATED_IN Westport, got %#v %v", rels, err)
+	}
+
+	_, err = Create(ctx, cfg, schema, client, "setting", Entity{Name: "mira voss", Type: "npc"})
+	if err == nil || !strings.Contains(err.Error(), "mira voss") || strings.Contains(err.Error(), "Mira Voss") {
+		t.Fatalf("expected the duplicate to be rejected echoing only the given name, got %v", err)
+	}
+}
+
+func TestCreate_RejectsInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	cfg, schema, client := newProject(t)
+
+	cases := map[string]Entity{
+		"unknown type":     {Name: "X", Type: "dragon"},
+		"unknown field":    {Name: "X", Type: "npc", Fields: map[string]any{"hair": "red"}},
+		"enum value":       {Name: "X", Type: "npc", Fields: map[string]any{"status": "asleep"}},
+		"mapping value":    {Name: "X", Type: "npc", Fields: map[string]any{"location": 3}},
+		"title override":   {Name: "X", Type: "npc", Fields: map[string]any{"title": "Y"}},
+		"missing name":     {Type: "npc"},
+		"property as list": {Name: "X", Type: "settlement", Fields: map[string]any{"government": map[string]any{"a": 1}}},
+	}
+	for name, entity := range cases {
+		if _, err := Create(ctx, cfg, schema, client, "setting", entity); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+	if _, err := os.Stat(cfg.Layers[0].Drafts); !os.IsNotExist(err) {
+		t.Fatalf("expected no drafts to be written, got %v", err)
+	}
+
+	cfg.Layers[0].Drafts = ""
+	if _, err := Create(ctx, cfg, schema, client, "setting", Entity{Name: "X", Type: "npc"}); err == nil {
+		t.Fatalf("expected layer without drafts path to be rejected")
+	}
+}
+
+func TestUpdate_PatchesDraft(t *testing.T) {
+	ctx := context.Background()
+	cfg, schema, client := newProject(t)
+
+	path := filepath.Join(cfg.Layers[0].Drafts, "mira.md")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	original := "---\ntitle: Mira Voss\ntype: npc\n# pilot for hire\nrole: Harbor pilot\nstatus: alive\n---\n\nKnows every reef.\n\n```gm\nWorks for the Iron Tide.\n```\n"
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	err := Update(ctx, cfg, schema, client, "setting", path, Patch{
+		Fields: map[string]any{"status": "dead", "role": nil, "location": "Westport"},
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	expected := "---\ntitle: Mira Voss\ntype: npc\nstatus: dead\nlocation: Westport\n---\n\nKnows every reef.\n\n```gm\nWorks for the Iron Tide.\n```\n"
+	if string(content) != expected {
+		t.Fatalf("unexpected file:\n%s", content)
+	}
+	mira, err := client.GetEntity(ctx, "Mira Voss", "npc")
+	if err != nil || mira == nil {
+		t.Fatalf("get entity: %v %v", mira, err)
+	}
+	if mira.Properties["status"] != "dead" || mira.Properties["role"] != nil || mira.Secret != "Works for the Iron Tide." {
+		t.Fatalf("unexpected entity after update: %#v", mira)
+	}
+
+	body := "Drowned in the harbor."
+	if err := Update(ctx, cfg, schema, client, "setting", path, Patch{Body: &body}); err != nil {
+		t.Fatalf("update body: %v", err)
+	}
+	content, _ = os.ReadFile(path)
+	if !strings.HasSuffix(string(content), "---\n\nDrowned in the harbor.\n") {
+		t.Fatalf("unexpected body:\n%s", content)
+	}
+
+	if err := Update(ctx, cfg, schema, client, "setting", path, Patch{Fields: map[string]any{"status": "asleep"}}); err == nil {
+		t.Fatalf("expected invalid enum to be rejected")
+	}
+	outside := filepath.Join(cfg.Layers[0].Paths[0], "westport.md")
+	if err := Update(ctx, cfg, schema, client, "setting", outside, Patch{Fields: map[string]any{"government": "council"}}); err == nil {
+		t.Fatalf("expected files outside drafts to be rejected")
+	}
+
+	folk := filepath.Join(cfg.Layers[0].Drafts, "folk.md")
+	if err := os.WriteFile(folk, []byte("---\ntitle: Tomas Brine\ntype: npc\n---\n\n---\ntitle: Old Nessa\ntype: npc\n---\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := Update(ctx, cfg, schema, client, "setting", folk, Patch{Fields: map[string]any{"status": "dead"}}); err == nil || !strings.Contains(err.Error(), "defines 2 entities") {
+		t.Fatalf("expected files with several entities to be rejected, got %v", err)
+	}
+}
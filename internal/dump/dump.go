@@ -0,0 +1,258 @@
+// Package dump converts a lorecraft database to and from a portable JSON
+// document. It only uses the store.Store interface, so a dump taken from one
+// backend restores into the other.
+package dump
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"lorecraft/internal/store"
+)
+
+// Version is the document format written by Build. Restore accepts documents
+// up to this version.
+const Version = 1
+
+type Document struct {
+	Version   int    `json:"version"`
+	Project   string `json:"project,omitempty"`
+	Lorecraft string `json:"lorecraft,omitempty"`
+	// Audience names the audience the document was exported for. Entities
+	// hidden from it and all secrets are missing from such a document.
+	Audience   string    `json:"audience,omitempty"`
+	ExportedAt time.Time `json:"exported_at"`
+	Entities   []Entity  `json:"entities"`
+	Edges      []Edge    `json:"edges"`
+	// Events mirrors the timeline for readers of the file. The events rows
+	// are derived from event entities' properties, so Restore rebuilds them
+	// from Entities rather than reading this section.
+	Events []Event `json:"events"`
+}
+
+// Entity carries the stored entity together with the ingestion metadata that
+// incremental ingest relies on: the source file and the anchor of the section
+// defining the entity, its hash and the properties filled from schema defaults.
+type Entity struct {
+	Name         string         `json:"name"`
+	EntityType   string         `json:"type"`
+	Layer        string         `json:"layer"`
+	SourceFile   string         `json:"source_file,omitempty"`
+	SourceAnchor string         `json:"source_anchor,omitempty"`
+	SourceHash   string         `json:"source_hash,omitempty"`
+	Tags         []string       `json:"tags"`
+	Aliases      []string       `json:"aliases,omitempty"`
+	Properties   map[string]any `json:"properties"`
+	Defaulted    []string       `json:"defaulted,omitempty"`
+	Body         string         `json:"body,omitempty"`
+	Secret       string         `json:"secret,omitempty"`
+}
+
+// Edge is a stored relationship. Targets that no file defines are recreated
+// as placeholders on restore.
+type Edge struct {
+	From      string `json:"from"`
+	FromLayer string `json:"from_layer"`
+	To        string `json:"to"`
+	ToLayer   string `json:"to_layer"`
+	Type      string `json:"type"`
+}
+
+type Event struct {
+	Name         string              `json:"name"`
+	Layer        string              `json:"layer"`
+	Session      int                 `json:"session"`
+	DateInWorld  string              `json:"date_in_world,omitempty"`
+	Participants []string            `json:"participants,omitempty"`
+	Location     []string            `json:"location,omitempty"`
+	Consequences []store.Consequence `json:"consequences,omitempty"`
+}
+
+// Metadata identifies where a document came from.
+type Metadata struct {
+	Project   string
+	Lorecraft string
+	// Audience is the audience db is restricted to, if any.
+	Audience string
+}
+
+// Build reads every entity, edge and event visible through db.
+func Build(ctx context.Context, db store.Store, meta Metadata) (*Document, error) {
+	doc := &Document{
+		Version:    Version,
+		Project:    meta.Project,
+		Lorecraft:  meta.Lorecraft,
+		Audience:   meta.Audience,
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		Entities:   []Entity{},
+		Edges:      []Edge{},
+		Events:     []Event{},
+	}
+
+	entities, err := db.ListEntitiesWithProperties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	layers := map[string]bool{}
+	for _, e := range entities {
+		doc.Entities = append(doc.Entities, Entity{
+			Name:         e.Name,
+			EntityType:   e.EntityType,
+			Layer:        e.Layer,
+			SourceFile:   e.SourceFile,
+			SourceAnchor: e.SourceAnchor,
+			SourceHash:   e.SourceHash,
+			Tags:         e.Tags,
+			Aliases:      e.Aliases,
+			Properties:   e.Properties,
+			Defaulted:    e.Defaulted,
+			Body:         e.Body,
+			Secret:       e.Secret,
+		})
+		layers[e.Layer] = true
+	}
+
+	edges, err := db.ListEdges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing edges: %w", err)
+	}
+	for _, e := range edges {
+		doc.Edges = append(doc.Edges, Edge{
+			From:      e.From.Name,
+			FromLayer: e.From.Layer,
+			To:        e.To.Name,
+			ToLayer:   e.To.Layer,
+			Type:      e.Type,
+		})
+	}
+
+	for _, layer := range sortedKeys(layers) {
+		events, err := db.GetTimeline(ctx, layer, "", 0, 0)
+		if err != nil {
+			return nil, fmt.Errorf("reading timeline for %s: %w", layer, err)
+		}
+		for _, e := range events {
+			doc.Events = append(doc.Events, Event{
+				Name:         e.Name,
+				Layer:        e.Layer,
+				Session:      e.Session,
+				DateInWorld:  e.DateInWorld,
+				Participants: e.Participants,
+				Location:     e.Location,
+				Consequences: e.Consequences,
+			})
+		}
+	}
+	return doc, nil
+}
+
+// Write encodes doc as indented JSON.
+func Write(w io.Writer, doc *Document) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// Read decodes a document and rejects versions newer than this build knows.
+func Read(r io.Reader) (*Document, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding dump: %w", err)
+	}
+	if doc.Version < 1 {
+		return nil, fmt.Errorf("dump has no version")
+	}
+	if doc.Version > Version {
+		return nil, fmt.Errorf("dump version %d is newer than supported version %d", doc.Version, Version)
+	}
+	return &doc, nil
+}
+
+// ErrFiltered is returned by Restore for a document exported for an audience.
+var ErrFiltered = errors.New("dump was exported for an audience")
+
+type Options struct {
+	// AllowFiltered restores a document exported for an audience, removing
+	// the entities hidden from it and clearing secrets in its layers.
+	AllowFiltered bool
+}
+
+type Result struct {
+	Entities     int
+	Edges        int
+	NodesRemoved int64
+}
+
+// Restore writes doc into db in one transaction. Layers present in the
+// document end up as they were when it was taken: entities from source files
+// the document does not list are removed, as a full ingest would. Layers the
+// document does not mention are left alone. A document exported for an
+// audience is refused unless options allow it, since restoring it would drop
+// everything hidden from that audience.
+func Restore(ctx context.Context, db store.Store, doc *Document, options Options) (Result, error) {
+	var result Result
+	if doc.Audience != "" && !options.AllowFiltered {
+		return result, fmt.Errorf("%w: %s; restoring it would remove the entities and secrets hidden from it", ErrFiltered, doc.Audience)
+	}
+	err := db.InTransaction(ctx, func(tx store.Store) error {
+		result = Result{}
+		files := map[string][]string{}
+		for _, e := range doc.Entities {
+			err := tx.UpsertEntity(ctx, store.EntityInput{
+				Name:         e.Name,
+				EntityType:   e.EntityType,
+				Layer:        e.Layer,
+				SourceFile:   e.SourceFile,
+				SourceAnchor: e.SourceAnchor,
+				SourceHash:   e.SourceHash,
+				Properties:   e.Properties,
+				Tags:         e.Tags,
+				Aliases:      e.Aliases,
+				Body:         e.Body,
+				Secret:       e.Secret,
+				Defaulted:    e.Defaulted,
+			})
+			if err != nil {
+				return fmt.Errorf("restoring %s: %w", e.Name, err)
+			}
+			result.Entities++
+			if e.SourceFile != "" {
+				files[e.Layer] = append(files[e.Layer], store.SourceKey(e.SourceFile, e.SourceAnchor))
+			}
+		}
+
+		for _, e := range doc.Edges {
+			if err := tx.UpsertRelationship(ctx, e.From, e.FromLayer, e.To, e.ToLayer, e.Type); err != nil {
+				return fmt.Errorf("restoring %s -[%s]-> %s: %w", e.From, e.Type, e.To, err)
+			}
+			result.Edges++
+		}
+
+		for _, layer := range sortedKeys(files) {
+			removed, err := tx.RemoveStaleNodes(ctx, layer, files[layer])
+			if err != nil {
+				return fmt.Errorf("removing stale nodes in %s: %w", layer, err)
+			}
+			result.NodesRemoved += removed
+		}
+		if _, err := tx.RemoveOrphanedPlaceholders(ctx); err != nil {
+			return fmt.Errorf("removing orphaned placeholders: %w", err)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,203 @@
+package dump
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+	"lorecraft/internal/store"
+	"lorecraft/internal/store/sqlite"
+)
+
+func exampleConfig(t *testing.T) *config.ProjectConfig {
+	t.Helper()
+	exampleDir := filepath.Join("..", "..", "example")
+	return &config.ProjectConfig{
+		Project:  "westlands",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers: []config.Layer{
+			{Name: "setting", Paths: []string{filepath.Join(exampleDir, "lore")}, Canonical: true},
+			{Name: "campaign-shadow-war", Paths: []string{filepath.Join(exampleDir, "campaigns", "shadow-war")}, DependsOn: []string{"setting"}},
+		},
+	}
+}
+
+func openEmpty(t *testing.T, cfg *config.ProjectConfig, schema *config.Schema) *sqlite.Client {
+	t.Helper()
+	ctx := context.Background()
+	client, err := sqlite.New(ctx, "sqlite://"+filepath.Join(t.TempDir(), "copy.db"), cfg)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { client.Close(ctx) })
+	if err := client.EnsureSchema(ctx, schema); err != nil {
+		t.Fatalf("ensure schema: %v", err)
+	}
+	return client
+}
+
+func ingestExample(t *testing.T) (*sqlite.Client, *config.ProjectConfig, *config.Schema) {
+	t.Helper()
+	ctx := context.Background()
+	cfg := exampleConfig(t)
+	schema, err := config.LoadSchema(filepath.Join("..", "..", "example", "schema.yaml"))
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+	client, err := sqlite.New(ctx, cfg.Database.DSN, cfg)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { client.Close(ctx) })
+
+	result, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{Full: true})
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("ingest errors: %v", result.Errors)
+	}
+	return client, cfg, schema
+}
+
+func TestRestore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source, cfg, schema := ingestExample(t)
+
+	doc, err := Build(ctx, source, Metadata{Project: "westlands", Lorecraft: "test"})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if len(doc.Entities) != 9 || len(doc.Edges) == 0 || len(doc.Events) == 0 {
+		t.Fatalf("expected entities, edges and events, got %d, %d and %d", len(doc.Entities), len(doc.Edges), len(doc.Events))
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	target := openEmpty(t, cfg, schema)
+	result, err := Restore(ctx, target, read, Options{})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.Entities != len(doc.Entities) || result.Edges != len(doc.Edges) {
+		t.Fatalf("unexpected restore counts: %#v", result)
+	}
+
+	copied, err := Build(ctx, target, Metadata{Project: "westlands", Lorecraft: "test"})
+	if err != nil {
+		t.Fatalf("build copy: %v", err)
+	}
+	// Compare the encoded documents: that is what a second export would
+	// produce, and it ignores nil versus empty slices.
+	copied.ExportedAt = doc.ExportedAt
+	var want, got bytes.Buffer
+	if err := Write(&want, doc); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := Write(&got, copied); err != nil {
+		t.Fatalf("write copy: %v", err)
+	}
+	if got.String() != want.String() {
+		t.Fatalf("document differs after restore:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+
+	hashes, err := target.GetLayerHashes(ctx, "setting")
+	if err != nil {
+		t.Fatalf("layer hashes: %v", err)
+	}
+	if len(hashes) == 0 {
+		t.Fatalf("expected ingestion hashes to be restored")
+	}
+}
+
+func TestRestore_RemovesStaleEntities(t *testing.T) {
+	ctx := context.Background()
+	source, cfg, schema := ingestExample(t)
+	doc, err := Build(ctx, source, Metadata{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	target := openEmpty(t, cfg, schema)
+	err = target.UpsertEntity(ctx, store.EntityInput{
+		Name:       "Forgotten Tower",
+		EntityType: "settlement",
+		Layer:      "setting",
+		SourceFile: "lore/forgotten-tower.md",
+		SourceHash: "abc",
+	})
+	if err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+
+	result, err := Restore(ctx, target, doc, Options{})
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.NodesRemoved != 1 {
+		t.Fatalf("expected the stale entity to be removed, got %#v", result)
+	}
+	tower, err := target.GetEntity(ctx, "Forgotten Tower", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if tower != nil {
+		t.Fatalf("stale entity survived the restore")
+	}
+}
+
+func TestBuild_HonoursVisibility(t *testing.T) {
+	ctx := context.Background()
+	source, _, _ := ingestExample(t)
+	player := source.WithVisibility(&store.Visibility{Property: "visibility", Hidden: []string{"gm"}})
+
+	doc, err := Build(ctx, player, Metadata{Audience: "player"})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if doc.Audience != "player" {
+		t.Fatalf("expected the audience to be recorded, got %q", doc.Audience)
+	}
+	for _, e := range doc.Entities {
+		if e.Name == "Bureau Director Lysa Quent" || e.Secret != "" {
+			t.Fatalf("hidden entity or secret dumped: %#v", e)
+		}
+	}
+
+	if _, err := Restore(ctx, source, doc, Options{}); !errors.Is(err, ErrFiltered) {
+		t.Fatalf("expected a filtered dump to be refused, got %v", err)
+	}
+	lysa, err := source.GetEntity(ctx, "Bureau Director Lysa Quent", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if lysa == nil {
+		t.Fatalf("refused restore removed a hidden entity")
+	}
+	if _, err := Restore(ctx, source, doc, Options{AllowFiltered: true}); err != nil {
+		t.Fatalf("restore with AllowFiltered: %v", err)
+	}
+}
+
+func TestRead_RejectsNewerVersion(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"version": 99, "entities": []}`))
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+	if _, err := Read(strings.NewReader(`{"entities": []}`)); err == nil {
+		t.Fatalf("expected an error for a document without a version")
+	}
+}
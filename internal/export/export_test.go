@@ -0,0 +1,45 @@
+package export
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+	"lorecraft/internal/store/sqlite"
+)
+
+func ingestExample(t *testing.T) (*sqlite.Client, *config.Schema) {
+	t.Helper()
+	ctx := context.Background()
+	exampleDir := filepath.Join("..", "..", "example")
+
+	cfg := &config.ProjectConfig{
+		Project:  "westlands",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers: []config.Layer{
+			{Name: "setting", Paths: []string{filepath.Join(exampleDir, "lore")}, Canonical: true},
+			{Name: "campaign-shadow-war", Paths: []string{filepath.Join(exampleDir, "campaigns", "shadow-war")}, DependsOn: []string{"setting"}},
+		},
+	}
+	schema, err := config.LoadSchema(filepath.Join(exampleDir, "schema.yaml"))
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+	client, err := sqlite.New(ctx, cfg.Database.DSN, cfg)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { client.Close(ctx) })
+
+	result, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{Full: true})
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("ingest errors: %v", result.Errors)
+	}
+	return client, schema
+}
@@ -0,0 +1,336 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/store"
+)
+
+// Graph formats accepted by WriteGraph.
+const (
+	GraphDOT     = "dot"
+	GraphGraphML = "graphml"
+	GraphMermaid = "mermaid"
+	GraphJSON    = "json"
+)
+
+// GraphFormats lists the formats WriteGraph understands.
+var GraphFormats = []string{GraphDOT, GraphGraphML, GraphMermaid, GraphJSON}
+
+// nodeColors are assigned to entity types in schema order.
+var nodeColors = []string{
+	"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3",
+	"#fdb462", "#b3de69", "#fccde5", "#bc80bd", "#ccebc5",
+}
+
+const placeholderColor = "#d9d9d9"
+
+// GraphOptions selects the part of the graph to export. Without Root, every
+// entity and edge that passes the filters is included, except that a
+// relationship type filter drops entities without such an edge. With Root,
+// the graph is the neighbourhood GetRelationships reaches from Root within
+// Depth hops; the layer and type filters then apply to every entity but the
+// root.
+type GraphOptions struct {
+	Layer      string
+	EntityType string
+	RelType    string
+	Root       string
+	Depth      int
+}
+
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	EntityType  string `json:"type"`
+	Layer       string `json:"layer"`
+	Color       string `json:"color"`
+	Placeholder bool   `json:"placeholder,omitempty"`
+}
+
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+// BuildGraph collects the nodes and edges selected by opts.
+func BuildGraph(ctx context.Context, db store.Store, schema *config.Schema, opts GraphOptions) (*Graph, error) {
+	b := &graphBuilder{schema: schema, opts: opts, ids: map[string]string{}, edges: map[GraphEdge]bool{}}
+	if opts.Root != "" {
+		if err := b.traverse(ctx, db); err != nil {
+			return nil, err
+		}
+	} else if err := b.collect(ctx, db); err != nil {
+		return nil, err
+	}
+	return &b.graph, nil
+}
+
+type graphBuilder struct {
+	schema *config.Schema
+	opts   GraphOptions
+	ids    map[string]string
+	edges  map[GraphEdge]bool
+	graph  Graph
+}
+
+func (b *graphBuilder) collect(ctx context.Context, db store.Store) error {
+	entities, err := db.ListEntitiesWithProperties(ctx)
+	if err != nil {
+		return err
+	}
+	edges, err := db.ListEdges(ctx)
+	if err != nil {
+		return fmt.Errorf("listing edges: %w", err)
+	}
+
+	storedType := b.relationshipTypes().StoredType(b.opts.RelType)
+	// Filtering by relationship type asks about those edges, so entities
+	// without one are left out rather than drawn unconnected.
+	if storedType == "" {
+		for _, entity := range entities {
+			ref := store.EntityRef{Name: entity.Name, EntityType: entity.EntityType, Layer: entity.Layer}
+			if b.matches(ref) {
+				b.node(ref, false)
+			}
+		}
+	}
+	for _, edge := range edges {
+		if storedType != "" && !strings.EqualFold(edge.Type, storedType) {
+			continue
+		}
+		if !b.matches(edge.From) || !b.matches(edge.To) {
+			continue
+		}
+		b.edge(b.node(edge.From, false), b.node(edge.To, edge.Placeholder), edge.Type)
+	}
+	return nil
+}
+
+func (b *graphBuilder) traverse(ctx context.Context, db store.Store) error {
+	root, err := db.GetEntity(ctx, b.opts.Root, "")
+	if err != nil {
+		return err
+	}
+	if root == nil {
+		return fmt.Errorf("entity not found: %s", b.opts.Root)
+	}
+	depth := b.opts.Depth
+	if depth == 0 {
+		depth = 1
+	}
+	rels, err := db.GetRelationships(ctx, root.Name, b.opts.RelType, "both", depth)
+	if err != nil {
+		return err
+	}
+
+	rootRef := store.EntityRef{Name: root.Name, EntityType: root.EntityType, Layer: root.Layer}
+	b.node(rootRef, false)
+	rootKey := entityKey(root.Layer, root.Name)
+	kept := func(ref store.EntityRef) bool {
+		return entityKey(ref.Layer, ref.Name) == rootKey || b.matches(ref)
+	}
+	for _, rel := range rels {
+		if !kept(rel.From) || !kept(rel.To) {
+			continue
+		}
+		// Relationships are reported from the traversed entity's side;
+		// incoming ones point the other way.
+		from, to := rel.From, rel.To
+		if rel.Direction == "incoming" {
+			from, to = to, from
+		}
+		b.edge(b.node(from, false), b.node(to, false), rel.Type)
+	}
+	return nil
+}
+
+func (b *graphBuilder) matches(ref store.EntityRef) bool {
+	if b.opts.Layer != "" && !strings.EqualFold(ref.Layer, b.opts.Layer) {
+		return false
+	}
+	if b.opts.EntityType != "" && !b.schema.IsA(ref.EntityType, b.opts.EntityType) {
+		return false
+	}
+	return true
+}
+
+func (b *graphBuilder) node(ref store.EntityRef, placeholder bool) string {
+	key := entityKey(ref.Layer, ref.Name)
+	if id, ok := b.ids[key]; ok {
+		return id
+	}
+	id := fmt.Sprintf("n%d", len(b.ids)+1)
+	b.ids[key] = id
+	color := placeholderColor
+	if !placeholder {
+		color = b.color(ref.EntityType)
+	}
+	b.graph.Nodes = append(b.graph.Nodes, GraphNode{
+		ID:          id,
+		Name:        ref.Name,
+		EntityType:  ref.EntityType,
+		Layer:       ref.Layer,
+		Color:       color,
+		Placeholder: placeholder,
+	})
+	return id
+}
+
+func (b *graphBuilder) edge(from, to, relType string) {
+	edge := GraphEdge{From: from, To: to, Type: relType}
+	if b.edges[edge] {
+		return
+	}
+	b.edges[edge] = true
+	b.graph.Edges = append(b.graph.Edges, edge)
+}
+
+func (b *graphBuilder) color(entityType string) string {
+	for i, declared := range b.schema.EntityTypes {
+		if strings.EqualFold(declared.Name, entityType) {
+			return nodeColors[i%len(nodeColors)]
+		}
+	}
+	return placeholderColor
+}
+
+func (b *graphBuilder) relationshipTypes() store.RelationshipTypes {
+	types := make([]store.RelationshipType, 0, len(b.schema.RelationshipTypes))
+	for _, rel := range b.schema.RelationshipTypes {
+		types = append(types, store.RelationshipType{Name: rel.Name, Inverse: rel.Inverse, Symmetric: rel.Symmetric})
+	}
+	return store.NewRelationshipTypes(types)
+}
+
+// WriteGraph writes g to w in the given format.
+func WriteGraph(w io.Writer, g *Graph, format string) error {
+	switch strings.ToLower(format) {
+	case GraphDOT:
+		return writeDOT(w, g)
+	case GraphGraphML:
+		return writeGraphML(w, g)
+	case GraphMermaid:
+		return writeMermaid(w, g)
+	case GraphJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(g)
+	default:
+		return fmt.Errorf("unknown graph format %q (expected %s)", format, strings.Join(GraphFormats, ", "))
+	}
+}
+
+func writeDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph lore {\n")
+	b.WriteString("  node [shape=box, style=\"filled,rounded\"];\n")
+	for _, node := range g.Nodes {
+		style := ""
+		if node.Placeholder {
+			style = ", style=\"filled,rounded,dashed\""
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, fillcolor=%s, tooltip=%s%s];\n",
+			node.ID, dotString(node.Name), dotString(node.Color), dotString(node.EntityType+" · "+node.Layer), style)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", edge.From, edge.To, dotString(edge.Type))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+func writeGraphML(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	for _, key := range []struct{ id, target, name string }{
+		{"label", "node", "label"},
+		{"type", "node", "type"},
+		{"layer", "node", "layer"},
+		{"color", "node", "color"},
+		{"placeholder", "node", "placeholder"},
+		{"rel_type", "edge", "rel_type"},
+	} {
+		fmt.Fprintf(&b, `  <key id="%s" for="%s" attr.name="%s" attr.type="string"/>`+"\n", key.id, key.target, key.name)
+	}
+	b.WriteString(`  <graph id="lore" edgedefault="directed">` + "\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, `    <node id="%s">`+"\n", node.ID)
+		writeGraphMLData(&b, "label", node.Name)
+		writeGraphMLData(&b, "type", node.EntityType)
+		writeGraphMLData(&b, "layer", node.Layer)
+		writeGraphMLData(&b, "color", node.Color)
+		if node.Placeholder {
+			writeGraphMLData(&b, "placeholder", "true")
+		}
+		b.WriteString("    </node>\n")
+	}
+	for i, edge := range g.Edges {
+		fmt.Fprintf(&b, `    <edge id="e%d" source="%s" target="%s">`+"\n", i+1, edge.From, edge.To)
+		writeGraphMLData(&b, "rel_type", edge.Type)
+		b.WriteString("    </edge>\n")
+	}
+	b.WriteString("  </graph>\n</graphml>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeGraphMLData(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, `      <data key="%s">`, key)
+	xml.EscapeText(b, []byte(value))
+	b.WriteString("</data>\n")
+}
+
+func writeMermaid(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	classes := map[string][]string{}
+	colors := map[string]string{}
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", node.ID, mermaidText(node.Name))
+		class := "placeholder"
+		if !node.Placeholder {
+			class = "type_" + strings.ReplaceAll(slug(node.EntityType), "-", "_")
+		}
+		classes[class] = append(classes[class], node.ID)
+		colors[class] = node.Color
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", edge.From, mermaidText(edge.Type), edge.To)
+	}
+	names := make([]string, 0, len(classes))
+	for class := range classes {
+		names = append(names, class)
+	}
+	sort.Strings(names)
+	for _, class := range names {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", class, colors[class])
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(classes[class], ","), class)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidText escapes characters that end a mermaid label.
+func mermaidText(value string) string {
+	return strings.NewReplacer(`"`, "#quot;", "|", "#124;", "\n", " ").Replace(value)
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func edgeLabels(g *Graph) map[string]bool {
+	names := map[string]string{}
+	for _, node := range g.Nodes {
+		names[node.ID] = node.Name
+	}
+	labels := map[string]bool{}
+	for _, edge := range g.Edges {
+		labels[names[edge.From]+" -"+edge.Type+"-> "+names[edge.To]] = true
+	}
+	return labels
+}
+
+func TestBuildGraph_Filters(t *testing.T) {
+	ctx := context.Background()
+	client, schema := ingestExample(t)
+
+	full, err := BuildGraph(ctx, client, schema, GraphOptions{})
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+	if len(full.Nodes) != 9 || !edgeLabels(full)["Westport -PART_OF-> The Westlands"] {
+		t.Fatalf("unexpected full graph: %#v", full)
+	}
+
+	members, err := BuildGraph(ctx, client, schema, GraphOptions{RelType: "has_member"})
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+	if len(members.Edges) != 1 || len(members.Nodes) != 2 || !edgeLabels(members)["Bureau Director Lysa Quent -MEMBER_OF-> Bureau of Civic Affairs"] {
+		t.Fatalf("expected only MEMBER_OF edges and their ends: %#v", members)
+	}
+
+	npcs, err := BuildGraph(ctx, client, schema, GraphOptions{EntityType: "npc"})
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+	for _, node := range npcs.Nodes {
+		if node.EntityType != "npc" {
+			t.Fatalf("expected only npcs: %#v", npcs.Nodes)
+		}
+	}
+	if !edgeLabels(npcs)["Overlord Rellan Harth -RELATED_TO-> Bureau Director Lysa Quent"] {
+		t.Fatalf("expected edges between npcs: %#v", npcs.Edges)
+	}
+
+	local, err := BuildGraph(ctx, client, schema, GraphOptions{Root: "westport", Depth: 1, EntityType: "npc"})
+	if err != nil {
+		t.Fatalf("build graph: %v", err)
+	}
+	labels := edgeLabels(local)
+	if len(local.Nodes) != 3 || !labels["Overlord Rellan Harth -LOCATED_IN-> Westport"] || !labels["Bureau Director Lysa Quent -LOCATED_IN-> Westport"] {
+		t.Fatalf("unexpected neighbourhood: %#v", local)
+	}
+
+	if _, err := BuildGraph(ctx, client, schema, GraphOptions{Root: "Nowhere"}); err == nil {
+		t.Fatalf("expected unknown root to fail")
+	}
+}
+
+func TestWriteGraph_Formats(t *testing.T) {
+	g := &Graph{
+		Nodes: []GraphNode{
+			{ID: "n1", Name: `The "Iron" Tide`, EntityType: "faction", Layer: "setting", Color: "#fb8072"},
+			{ID: "n2", Name: "Westport & Co", EntityType: "settlement", Layer: "setting", Color: "#d9d9d9", Placeholder: true},
+		},
+		Edges: []GraphEdge{{From: "n1", To: "n2", Type: "OPERATES_IN"}},
+	}
+	cases := map[string][]string{
+		GraphDOT: {
+			`n1 [label="The \"Iron\" Tide", fillcolor="#fb8072", tooltip="faction · setting"];`,
+			`n2 [label="Westport & Co", fillcolor="#d9d9d9", tooltip="settlement · setting", style="filled,rounded,dashed"];`,
+			`n1 -> n2 [label="OPERATES_IN"];`,
+		},
+		GraphGraphML: {
+			`<data key="label">The &#34;Iron&#34; Tide</data>`,
+			`<data key="label">Westport &amp; Co</data>`,
+			`<edge id="e1" source="n1" target="n2">`,
+			`<data key="rel_type">OPERATES_IN</data>`,
+		},
+		GraphMermaid: {
+			`n1["The #quot;Iron#quot; Tide"]`,
+			`n1 -->|OPERATES_IN| n2`,
+			`classDef type_faction fill:#fb8072`,
+			`class n2 placeholder`,
+		},
+	}
+	for format, wants := range cases {
+		var buf bytes.Buffer
+		if err := WriteGraph(&buf, g, format); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(buf.String(), want) {
+				t.Fatalf("%s: expected %q in:\n%s", format, want, buf.String())
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGraph(&buf, g, GraphJSON); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	var decoded Graph
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Nodes) != 2 || decoded.Edges[0].Type != "OPERATES_IN" {
+		t.Fatalf("unexpected json: %s %v", buf.String(), err)
+	}
+
+	if err := WriteGraph(&buf, g, "svg"); err == nil {
+		t.Fatalf("expected unknown format to be rejected")
+	}
+}
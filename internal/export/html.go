@@ -0,0 +1,510 @@
+// Package export writes the contents of a store to formats meant for other
+// tools and readers.
+package export
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/store"
+)
+
+//go:embed site
+var siteFS embed.FS
+
+var siteTemplates = template.Must(template.New("").ParseFS(siteFS, "site/layout.html"))
+
+// HTMLOptions controls a static site export.
+type HTMLOptions struct {
+	// Title is shown in the header of every page.
+	Title string
+	// Secrets includes GM notes on entity pages. Leave it off for sites that
+	// players will read.
+	Secrets bool
+}
+
+// HTMLResult counts the pages written by HTML.
+type HTMLResult struct {
+	Entities int
+	Types    int
+	Tags     int
+}
+
+// HTML renders every entity in db into a static site in dir: a page per
+// entity, an index page per type and tag, and a search page backed by a
+// generated script so the site works straight from the file system. Hidden
+// entities are left out when db is restricted to an audience.
+func HTML(ctx context.Context, db store.Store, schema *config.Schema, dir string, opts HTMLOptions) (HTMLResult, error) {
+	entities, err := db.ListEntitiesWithProperties(ctx)
+	if err != nil {
+		return HTMLResult{}, err
+	}
+	edges, err := db.ListEdges(ctx)
+	if err != nil {
+		return HTMLResult{}, fmt.Errorf("listing edges: %w", err)
+	}
+
+	site := newSite(opts.Title, schema, entities)
+	for _, sub := range []string{"", "entities", "types", "tags"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return HTMLResult{}, fmt.Errorf("creating %s: %w", filepath.Join(dir, sub), err)
+		}
+	}
+	for _, asset := range []string{"style.css", "search.js"} {
+		content, err := siteFS.ReadFile("site/" + asset)
+		if err != nil {
+			return HTMLResult{}, err
+		}
+		if err := writeFile(dir, asset, content); err != nil {
+			return HTMLResult{}, err
+		}
+	}
+	index, err := site.searchIndex()
+	if err != nil {
+		return HTMLResult{}, err
+	}
+	if err := writeFile(dir, "search-index.js", index); err != nil {
+		return HTMLResult{}, err
+	}
+
+	for i := range entities {
+		entity := &entities[i]
+		data := site.entityPage(entity, edges, opts.Secrets)
+		if err := site.render(dir, site.pages[entityKey(entity.Layer, entity.Name)], "entity.html", data); err != nil {
+			return HTMLResult{}, err
+		}
+	}
+
+	types := site.groupPages(site.types, site.typeHref)
+	for _, group := range types {
+		data := page{Site: site.title, Title: group.Name, Root: "../", Entities: site.links(site.types[strings.ToLower(group.Name)])}
+		if err := site.render(dir, group.Href, "list.html", data); err != nil {
+			return HTMLResult{}, err
+		}
+	}
+	if err := site.render(dir, "types/index.html", "list.html", page{Site: site.title, Title: "Types", Root: "../", Groups: types}); err != nil {
+		return HTMLResult{}, err
+	}
+
+	tags := site.groupPages(site.tags, site.tagHref)
+	for _, group := range tags {
+		data := page{Site: site.title, Title: group.Name, Root: "../", Entities: site.links(site.tags[strings.ToLower(group.Name)])}
+		if err := site.render(dir, group.Href, "list.html", data); err != nil {
+			return HTMLResult{}, err
+		}
+	}
+	if err := site.render(dir, "tags/index.html", "list.html", page{Site: site.title, Title: "Tags", Groups: tags, Root: "../"}); err != nil {
+		return HTMLResult{}, err
+	}
+
+	home := page{Site: site.title, Title: site.title, Entities: site.links(site.entities), Types: types}
+	if err := site.render(dir, "index.html", "index.html", home); err != nil {
+		return HTMLResult{}, err
+	}
+
+	return HTMLResult{Entities: len(entities), Types: len(types), Tags: len(tags)}, nil
+}
+
+type page struct {
+	Site     string
+	Title    string
+	Root     string
+	Entities []entityLink
+	Types    []group
+	Groups   []group
+	Entity   *entityView
+}
+
+type entityLink struct {
+	Name  string
+	Type  string
+	Layer string
+	Href  string
+}
+
+type group struct {
+	Name  string
+	Href  string
+	Count int
+}
+
+type entityView struct {
+	Type       string
+	TypeHref   string
+	Layer      string
+	Tags       []group
+	Properties []propertyRow
+	Body       template.HTML
+	Secret     template.HTML
+	Outgoing   []relationshipGroup
+	Incoming   []relationshipGroup
+}
+
+type propertyRow struct {
+	Name      string
+	Value     string
+	Defaulted bool
+}
+
+type relationshipGroup struct {
+	Type    string
+	Targets []entityRef
+}
+
+// entityRef links to another entity from an entity page. Href already
+// includes the path back to the site root and is empty for placeholders.
+type entityRef struct {
+	Name string
+	Type string
+	Href string
+}
+
+type searchEntry struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	Layer string   `json:"layer"`
+	Tags  []string `json:"tags"`
+	Href  string   `json:"href"`
+	Text  string   `json:"text"`
+}
+
+// site holds the page assigned to each entity, type and tag, so that links
+// can be resolved before any page is written.
+type site struct {
+	title    string
+	schema   *config.Schema
+	entities []*store.Entity
+	pages    map[string]string
+	byName   map[string]string
+	byFile   map[string]string
+	types    map[string][]*store.Entity
+	tags     map[string][]*store.Entity
+	groupKey map[string]string
+	taken    map[string]bool
+}
+
+func newSite(title string, schema *config.Schema, entities []store.Entity) *site {
+	if strings.TrimSpace(title) == "" {
+		title = "Lore"
+	}
+	s := &site{
+		title:    title,
+		schema:   schema,
+		pages:    map[string]string{},
+		byName:   map[string]string{},
+		byFile:   map[string]string{},
+		types:    map[string][]*store.Entity{},
+		tags:     map[string][]*store.Entity{},
+		groupKey: map[string]string{},
+		taken:    map[string]bool{"types/index.html": true, "tags/index.html": true},
+	}
+	for i := range entities {
+		entity := &entities[i]
+		href := s.claim("entities", entity.Name)
+		s.entities = append(s.entities, entity)
+		s.pages[entityKey(entity.Layer, entity.Name)] = href
+		if _, ok := s.byName[strings.ToLower(entity.Name)]; !ok {
+			s.byName[strings.ToLower(entity.Name)] = href
+		}
+		if entity.SourceFile != "" {
+			file := filepath.Clean(entity.SourceFile)
+			s.byFile[store.SourceKey(file, entity.SourceAnchor)] = href
+			if _, ok := s.byFile[file]; !ok {
+				s.byFile[file] = href
+			}
+		}
+		typeKey := strings.ToLower(entity.EntityType)
+		s.types[typeKey] = append(s.types[typeKey], entity)
+		for _, tag := range entity.Tags {
+			tagKey := strings.ToLower(tag)
+			s.tags[tagKey] = append(s.tags[tagKey], entity)
+		}
+	}
+	s.claimGroups("types", s.types)
+	s.claimGroups("tags", s.tags)
+	return s
+}
+
+// claim reserves a unique page path in dir for name.
+func (s *site) claim(dir, name string) string {
+	base := dir + "/" + slug(name)
+	href := base + ".html"
+	for i := 2; s.taken[href]; i++ {
+		href = fmt.Sprintf("%s-%d.html", base, i)
+	}
+	s.taken[href] = true
+	return href
+}
+
+func (s *site) typeHref(name string) string {
+	return s.groupHref("types", name)
+}
+
+func (s *site) tagHref(name string) string {
+	return s.groupHref("tags", name)
+}
+
+func (s *site) groupHref(dir, name string) string {
+	return s.groupKey[dir+"/"+strings.ToLower(name)]
+}
+
+// claimGroups reserves a page for every group in members, in name order so
+// that the pages do not change between exports.
+func (s *site) claimGroups(dir string, members map[string][]*store.Entity) {
+	for _, key := range sortedKeys(members) {
+		s.groupKey[dir+"/"+key] = s.claim(dir, key)
+	}
+}
+
+// groupPages lists the groups in members by name, each spelled as its first
+// member spells it.
+func (s *site) groupPages(members map[string][]*store.Entity, href func(string) string) []group {
+	groups := make([]group, 0, len(members))
+	for _, key := range sortedKeys(members) {
+		entities := members[key]
+		name := spelling(entities[0], key)
+		groups = append(groups, group{Name: name, Href: href(name), Count: len(entities)})
+	}
+	return groups
+}
+
+func sortedKeys(members map[string][]*store.Entity) []string {
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// spelling returns how entity spells the group key: its type, or the
+// matching tag.
+func spelling(entity *store.Entity, key string) string {
+	if strings.EqualFold(entity.EntityType, key) {
+		return entity.EntityType
+	}
+	for _, tag := range entity.Tags {
+		if strings.EqualFold(tag, key) {
+			return tag
+		}
+	}
+	return key
+}
+
+func (s *site) links(entities []*store.Entity) []entityLink {
+	links := make([]entityLink, 0, len(entities))
+	for _, entity := range entities {
+		links = append(links, entityLink{
+			Name:  entity.Name,
+			Type:  entity.EntityType,
+			Layer: entity.Layer,
+			Href:  s.pages[entityKey(entity.Layer, entity.Name)],
+		})
+	}
+	return links
+}
+
+func (s *site) entityPage(entity *store.Entity, edges []store.Edge, secrets bool) page {
+	const root = "../"
+	md := markdown{
+		wiki: func(name string) (string, bool) {
+			href, ok := s.byName[strings.ToLower(name)]
+			return root + href, ok
+		},
+		file: func(path string) (string, bool) {
+			path, anchor, _ := strings.Cut(path, "#")
+			file := filepath.Clean(filepath.Join(filepath.Dir(entity.SourceFile), path))
+			href, ok := s.byFile[store.SourceKey(file, strings.ToLower(anchor))]
+			if !ok {
+				href, ok = s.byFile[file]
+			}
+			return root + href, ok
+		},
+	}
+
+	view := &entityView{
+		Type:     entity.EntityType,
+		TypeHref: s.typeHref(entity.EntityType),
+		Layer:    entity.Layer,
+		Body:     template.HTML(md.render(entity.Body)),
+	}
+	for _, tag := range entity.Tags {
+		view.Tags = append(view.Tags, group{Name: tag, Href: s.tagHref(tag)})
+	}
+	view.Properties = s.propertyRows(entity)
+	if secrets && strings.TrimSpace(entity.Secret) != "" {
+		view.Secret = template.HTML(md.render(entity.Secret))
+	}
+
+	key := entityKey(entity.Layer, entity.Name)
+	ref := func(end store.EntityRef, placeholder bool) entityRef {
+		r := entityRef{Name: end.Name, Type: end.EntityType}
+		if href, ok := s.pages[entityKey(end.Layer, end.Name)]; ok && !placeholder {
+			r.Href = root + href
+		}
+		return r
+	}
+	for _, edge := range edges {
+		if entityKey(edge.From.Layer, edge.From.Name) == key {
+			view.Outgoing = addToGroup(view.Outgoing, edge.Type, ref(edge.To, edge.Placeholder))
+		}
+		if entityKey(edge.To.Layer, edge.To.Name) == key {
+			// A symmetric edge reads the same from both ends, so it is listed
+			// with the entity's own relationships.
+			if rel, ok := s.schema.RelationshipTypeByName(edge.Type); ok && rel.Symmetric {
+				view.Outgoing = addToGroup(view.Outgoing, edge.Type, ref(edge.From, false))
+				continue
+			}
+			view.Incoming = addToGroup(view.Incoming, s.incomingLabel(edge.Type), ref(edge.From, false))
+		}
+	}
+
+	return page{Site: s.title, Title: entity.Name, Root: root, Entity: view}
+}
+
+// incomingLabel names an edge seen from its target: the inverse declared in
+// the schema, or the type itself.
+func (s *site) incomingLabel(relType string) string {
+	if rel, ok := s.schema.RelationshipTypeByName(relType); ok && rel.Inverse != "" {
+		return rel.Inverse
+	}
+	return relType
+}
+
+func addToGroup(groups []relationshipGroup, relType string, target entityRef) []relationshipGroup {
+	for i := range groups {
+		if groups[i].Type != relType {
+			continue
+		}
+		for _, existing := range groups[i].Targets {
+			if existing == target {
+				return groups
+			}
+		}
+		groups[i].Targets = append(groups[i].Targets, target)
+		return groups
+	}
+	return append(groups, relationshipGroup{Type: relType, Targets: []entityRef{target}})
+}
+
+// propertyRows lists properties in schema order, followed by any the schema
+// does not declare.
+func (s *site) propertyRows(entity *store.Entity) []propertyRow {
+	defaulted := map[string]bool{}
+	for _, name := range entity.Defaulted {
+		defaulted[name] = true
+	}
+	seen := map[string]bool{}
+	var rows []propertyRow
+	add := func(name string) {
+		value, ok := entity.Properties[name]
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		rows = append(rows, propertyRow{Name: name, Value: formatValue(value), Defaulted: defaulted[name]})
+	}
+	if entityType, ok := s.schema.EntityTypeByName(entity.EntityType); ok {
+		for _, prop := range entityType.Properties {
+			add(prop.Name)
+		}
+	}
+	rest := make([]string, 0, len(entity.Properties))
+	for name := range entity.Properties {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		add(name)
+	}
+	return rows
+}
+
+func (s *site) searchIndex() ([]byte, error) {
+	entries := make([]searchEntry, 0, len(s.entities))
+	for _, entity := range s.entities {
+		entries = append(entries, searchEntry{
+			Name:  entity.Name,
+			Type:  entity.EntityType,
+			Layer: entity.Layer,
+			Tags:  entity.Tags,
+			Href:  s.pages[entityKey(entity.Layer, entity.Name)],
+			Text:  plainText(entity.Body),
+		})
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("encoding search index: %w", err)
+	}
+	return []byte("window.loreSearchIndex = " + string(data) + ";\n"), nil
+}
+
+func (s *site) render(dir, path, name string, data page) error {
+	tmpl, err := template.Must(siteTemplates.Clone()).ParseFS(siteFS, "site/"+name)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return fmt.Errorf("rendering %s: %w", path, err)
+	}
+	return writeFile(dir, path, buf.Bytes())
+}
+
+func writeFile(dir, path string, content []byte) error {
+	full := filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.WriteFile(full, content, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", full, err)
+	}
+	return nil
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, formatValue(item))
+		}
+		return strings.Join(parts, ", ")
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func entityKey(layer, name string) string {
+	return strings.ToLower(layer) + "\x00" + strings.ToLower(name)
+}
+
+// slug turns a name into a file name: lower-case letters and digits separated
+// by single hyphens.
+func slug(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	if b.Len() == 0 {
+		return "page"
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lorecraft/internal/store"
+)
+
+func readPage(t *testing.T, dir, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(content)
+}
+
+func TestHTML_WritesLinkedSite(t *testing.T) {
+	ctx := context.Background()
+	client, schema := ingestExample(t)
+	dir := t.TempDir()
+
+	result, err := HTML(ctx, client, schema, dir, HTMLOptions{Title: "Westlands", Secrets: true})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if result.Entities != 9 || result.Types == 0 || result.Tags == 0 {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	rellan := readPage(t, dir, "entities/overlord-rellan-harth.html")
+	for _, want := range []string{
+		"<h1>Overlord Rellan Harth</h1>",
+		`<a href="../types/npc.html">npc</a>`,
+		"<tr><th>role</th><td>Overlord of the Westlands</td></tr>",
+		`<a href="../entities/westport.html">Westport</a>`,
+		"<h2>GM notes</h2>",
+	} {
+		if !strings.Contains(rellan, want) {
+			t.Fatalf("expected %q in entity page:\n%s", want, rellan)
+		}
+	}
+	if strings.Count(rellan, "bureau-director-lysa-quent.html") != 1 {
+		t.Fatalf("expected symmetric edge to be listed once:\n%s", rellan)
+	}
+
+	westport := readPage(t, dir, "entities/westport.html")
+	if !strings.Contains(westport, "<h2>Referenced by</h2>") || !strings.Contains(westport, "overlord-rellan-harth.html") {
+		t.Fatalf("expected incoming edges on westport:\n%s", westport)
+	}
+	if !strings.Contains(readPage(t, dir, "types/npc.html"), "../entities/selin-hale.html") {
+		t.Fatalf("expected type page to list npcs")
+	}
+	if !strings.Contains(readPage(t, dir, "tags/index.html"), "../tags/politics.html") {
+		t.Fatalf("expected tag index to link tag pages")
+	}
+	if index := readPage(t, dir, "search-index.js"); !strings.HasPrefix(index, "window.loreSearchIndex = [") || !strings.Contains(index, `"href":"entities/westport.html"`) {
+		t.Fatalf("unexpected search index: %s", index)
+	}
+}
+
+func TestHTML_HonoursVisibility(t *testing.T) {
+	ctx := context.Background()
+	client, schema := ingestExample(t)
+	dir := t.TempDir()
+
+	player := client.WithVisibility(&store.Visibility{Property: "visibility", Hidden: []string{"gm"}})
+	if _, err := HTML(ctx, player, schema, dir, HTMLOptions{Secrets: true}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "entities", "bureau-director-lysa-quent.html")); !os.IsNotExist(err) {
+		t.Fatalf("expected no page for a hidden entity, got %v", err)
+	}
+	rellan := readPage(t, dir, "entities/overlord-rellan-harth.html")
+	if strings.Contains(rellan, "Lysa") || strings.Contains(rellan, "GM notes") {
+		t.Fatalf("hidden entity or secret leaked:\n%s", rellan)
+	}
+	if strings.Contains(readPage(t, dir, "search-index.js"), "Lysa Quent") {
+		t.Fatalf("hidden entity in search index")
+	}
+}
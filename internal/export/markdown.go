@@ -0,0 +1,227 @@
+package export
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern    = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern     = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedPattern    = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	rulePattern       = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	inlineTokenRegexp = regexp.MustCompile("`[^`\n]+`" + `|\[\[([^\[\]|]+?)(?:\|([^\[\]]*))?\]\]|(!?)\[([^\[\]]*)\]\(\s*<?([^()\s<>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	strongPattern     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emphasisPattern   = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+)
+
+// markdown renders the subset of markdown that lore files use: headings,
+// paragraphs, lists, block quotes, rules, fenced code, emphasis, code spans,
+// links and wiki links. Links are resolved through wiki and file; a link
+// that resolves to nothing is rendered as its text.
+type markdown struct {
+	wiki func(name string) (string, bool)
+	file func(path string) (string, bool)
+}
+
+func (m markdown) render(body string) string {
+	var out strings.Builder
+	var paragraph []string
+	var items []string
+	listTag := ""
+	var quote []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + m.inline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	flushList := func() {
+		if len(items) > 0 {
+			out.WriteString("<" + listTag + ">\n")
+			for _, item := range items {
+				out.WriteString("<li>" + m.inline(item) + "</li>\n")
+			}
+			out.WriteString("</" + listTag + ">\n")
+			items = nil
+		}
+	}
+	flushQuote := func() {
+		if len(quote) > 0 {
+			out.WriteString("<blockquote>\n" + m.render(strings.Join(quote, "\n")) + "</blockquote>\n")
+			quote = nil
+		}
+	}
+	flush := func() {
+		flushParagraph()
+		flushList()
+		flushQuote()
+	}
+
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if fence := fenceMarker(trimmed); fence != "" {
+			flush()
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) && strings.Trim(strings.TrimSpace(lines[i]), fence[:1]) == "" {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			flushParagraph()
+			flushList()
+			quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(trimmed, ">"), " "))
+			continue
+		}
+		flushQuote()
+
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			flushList()
+		case headingPattern.MatchString(trimmed):
+			flush()
+			match := headingPattern.FindStringSubmatch(trimmed)
+			// The page title is the only h1, so body headings start at h2.
+			level := min(len(match[1])+1, 6)
+			tag := "h" + string(rune('0'+level))
+			out.WriteString("<" + tag + ">" + m.inline(match[2]) + "</" + tag + ">\n")
+		case rulePattern.MatchString(trimmed):
+			flush()
+			out.WriteString("<hr>\n")
+		case bulletPattern.MatchString(line):
+			flushParagraph()
+			if listTag != "ul" {
+				flushList()
+			}
+			listTag = "ul"
+			items = append(items, bulletPattern.FindStringSubmatch(line)[1])
+		case orderedPattern.MatchString(line):
+			flushParagraph()
+			if listTag != "ol" {
+				flushList()
+			}
+			listTag = "ol"
+			items = append(items, orderedPattern.FindStringSubmatch(line)[1])
+		case len(items) > 0 && line != trimmed:
+			// An indented line continues the previous list item.
+			items[len(items)-1] += " " + trimmed
+		default:
+			flushList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// inline renders code spans, links and emphasis in text.
+func (m markdown) inline(text string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range inlineTokenRegexp.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(emphasis(html.EscapeString(text[last:loc[0]])))
+		last = loc[1]
+		token := text[loc[0]:loc[1]]
+		group := func(n int) string {
+			if loc[2*n] < 0 {
+				return ""
+			}
+			return text[loc[2*n]:loc[2*n+1]]
+		}
+
+		switch {
+		case strings.HasPrefix(token, "`"):
+			out.WriteString("<code>" + html.EscapeString(strings.Trim(token, "`")) + "</code>")
+		case strings.HasPrefix(token, "[["):
+			target := strings.TrimSpace(group(1))
+			label := strings.TrimSpace(group(2))
+			if label == "" {
+				label = target
+			}
+			out.WriteString(m.link(m.wiki, target, emphasis(html.EscapeString(label))))
+		case group(3) == "!":
+			src := group(5)
+			if isExternal(src) {
+				out.WriteString(`<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(group(4)) + `">`)
+			} else {
+				out.WriteString(html.EscapeString(group(4)))
+			}
+		default:
+			label := emphasis(html.EscapeString(group(4)))
+			href := group(5)
+			if isExternal(href) {
+				out.WriteString(`<a href="` + html.EscapeString(href) + `">` + label + `</a>`)
+			} else {
+				out.WriteString(m.link(m.file, href, label))
+			}
+		}
+	}
+	out.WriteString(emphasis(html.EscapeString(text[last:])))
+	return strings.ReplaceAll(out.String(), "\n", " ")
+}
+
+func (m markdown) link(resolve func(string) (string, bool), target, label string) string {
+	if resolve != nil {
+		if href, ok := resolve(target); ok {
+			return `<a href="` + html.EscapeString(href) + `">` + label + `</a>`
+		}
+	}
+	return `<span class="unresolved">` + label + `</span>`
+}
+
+// emphasis applies strong and emphasis markers to already escaped text.
+func emphasis(escaped string) string {
+	escaped = strongPattern.ReplaceAllStringFunc(escaped, func(match string) string {
+		return "<strong>" + match[2:len(match)-2] + "</strong>"
+	})
+	return emphasisPattern.ReplaceAllStringFunc(escaped, func(match string) string {
+		return "<em>" + match[1:len(match)-1] + "</em>"
+	})
+}
+
+func fenceMarker(line string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+func isExternal(href string) bool {
+	lower := strings.ToLower(href)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:")
+}
+
+// plainText strips markdown down to words for the search index.
+func plainText(body string) string {
+	text := inlineTokenRegexp.ReplaceAllStringFunc(body, func(token string) string {
+		match := inlineTokenRegexp.FindStringSubmatch(token)
+		switch {
+		case strings.HasPrefix(token, "`"):
+			return strings.Trim(token, "`")
+		case strings.HasPrefix(token, "[["):
+			if match[2] != "" {
+				return match[2]
+			}
+			return match[1]
+		default:
+			return match[4]
+		}
+	})
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return strings.ContainsRune(" \t\n\r#*_>`~|", r)
+	}), " ")
+}
@@ -0,0 +1,28 @@
+package export
+
+import "testing"
+
+func TestMarkdown_Render(t *testing.T) {
+	md := markdown{
+		wiki: func(name string) (string, bool) { return "westport.html", name == "Westport" },
+		file: func(path string) (string, bool) { return "bureau.html", path == "../factions/bureau.md" },
+	}
+	body := "# Harbor\n\nThe **harbor** of [[Westport]] and [[Nowhere|somewhere]].\nSee [the bureau](../factions/bureau.md) and [maps](https://example.com/a?b=1&c=2).\n\n- one\n- `two <b>`\n\n1. first\n\n> quoted *text*\n\n```\n<raw> [[Westport]]\n```\n"
+	expected := "<h2>Harbor</h2>\n" +
+		`<p>The <strong>harbor</strong> of <a href="westport.html">Westport</a> and <span class="unresolved">somewhere</span>. See <a href="bureau.html">the bureau</a> and <a href="https://example.com/a?b=1&amp;c=2">maps</a>.</p>` + "\n" +
+		"<ul>\n<li>one</li>\n<li><code>two &lt;b&gt;</code></li>\n</ul>\n" +
+		"<ol>\n<li>first</li>\n</ol>\n" +
+		"<blockquote>\n<p>quoted <em>text</em></p>\n</blockquote>\n" +
+		"<pre><code>&lt;raw&gt; [[Westport]]</code></pre>\n"
+	if got := md.render(body); got != expected {
+		t.Fatalf("unexpected html:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestMarkdown_EscapesHTML(t *testing.T) {
+	got := markdown{}.render(`<script>alert(1)</script> [x](javascript:alert(1))`)
+	expected := "<p>&lt;script&gt;alert(1)&lt;/script&gt; [x](javascript:alert(1))</p>\n"
+	if got != expected {
+		t.Fatalf("unexpected html: %s", got)
+	}
+}
@@ -0,0 +1,53 @@
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var index = window.loreSearchIndex || [];
+
+  function escape(text) {
+    var div = document.createElement("div");
+    div.textContent = text;
+    return div.innerHTML;
+  }
+
+  function score(entry, terms) {
+    var name = entry.name.toLowerCase();
+    var meta = (entry.type + " " + entry.layer + " " + entry.tags.join(" ")).toLowerCase();
+    var text = entry.text.toLowerCase();
+    var total = 0;
+    for (var i = 0; i < terms.length; i++) {
+      var term = terms[i];
+      if (name.indexOf(term) >= 0) {
+        total += 10;
+      } else if (meta.indexOf(term) >= 0) {
+        total += 3;
+      } else if (text.indexOf(term) >= 0) {
+        total += 1;
+      } else {
+        return 0;
+      }
+    }
+    return total;
+  }
+
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    if (terms.length === 0) {
+      results.innerHTML = "";
+      return;
+    }
+    var matches = [];
+    for (var i = 0; i < index.length; i++) {
+      var s = score(index[i], terms);
+      if (s > 0) {
+        matches.push({ entry: index[i], score: s });
+      }
+    }
+    matches.sort(function (a, b) {
+      return b.score - a.score || a.entry.name.localeCompare(b.entry.name);
+    });
+    results.innerHTML = matches.slice(0, 50).map(function (m) {
+      return '<li><a href="' + escape(m.entry.href) + '">' + escape(m.entry.name) + '</a> <span class="meta">' +
+        escape(m.entry.type) + " · " + escape(m.entry.layer) + "</span></li>";
+    }).join("");
+  });
+})();
@@ -5,10 +5,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"lorecraft/internal/config"
@@ -20,59 +22,153 @@ type Result struct {
 	NodesUpserted int
 	EdgesUpserted int
 	NodesRemoved  int
-	FilesSkipped  int
-	Errors        []error
+	// FilesSkipped counts the files left unparsed because they are unchanged
+	// since the last run, and the files without frontmatter or a type.
+	FilesSkipped int
+	// EntitiesSkipped counts the entity sections of changed files left alone
+	// because they are unchanged or their type is not in the schema.
+	EntitiesSkipped int
+	Errors          []error
+	// Warnings report problems that did not stop an entity from being
+	// ingested, such as a type that contradicts the layer's path rules.
+	Warnings []string
+	// Reingested lists the entity types ingested again in full because their
+	// definition changed since the last complete ingestion.
+	Reingested []string
+	// Renamed counts the stored properties and edges moved to new names
+	// declared with renamed_from.
+	Renamed int
+	// RolledBack is set when an atomic run hit errors and none of its changes
+	// were committed.
+	RolledBack bool
 }
 
 type Options struct {
 	Full bool
+	// Files restricts parsing to the listed paths. Every layer is still walked
+	// so entities from deleted files are removed. Nil means all files.
+	Files []string
+	// Atomic runs ingestion in a single transaction that is committed only if
+	// no file or edge failed, so a partial run never reaches the database.
+	Atomic bool
 }
 
+var errRollback = errors.New("ingestion failed; rolling back")
+
 type processedDoc struct {
 	doc   *parser.Document
 	layer config.Layer
 }
 
 func Run(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema, db Store, options Options) (*Result, error) {
+	if !options.Atomic {
+		return run(ctx, cfg, schema, db, options)
+	}
+
+	var result *Result
+	err := db.InTransaction(ctx, func(tx store.Store) error {
+		var err error
+		result, err = run(ctx, cfg, schema, tx, options)
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) > 0 {
+			return errRollback
+		}
+		return nil
+	})
+	if errors.Is(err, errRollback) {
+		result.RolledBack = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func run(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema, db Store, options Options) (*Result, error) {
 	if err := db.EnsureSchema(ctx, schema); err != nil {
 		return nil, fmt.Errorf("ensure schema: %w", err)
 	}
 
+	for _, layer := range cfg.Layers {
+		for _, rule := range layer.Rules {
+			if rule.Type == "" {
+				continue
+			}
+			entityType, ok := schema.EntityTypeByName(rule.Type)
+			if !ok {
+				return nil, fmt.Errorf("layer %s rule %s: unknown entity type %s", layer.Name, rule.Glob, rule.Type)
+			}
+			if entityType.Abstract {
+				return nil, fmt.Errorf("layer %s rule %s: entity type %s is abstract", layer.Name, rule.Glob, rule.Type)
+			}
+		}
+	}
+
 	result := &Result{}
+	reingest, schemaUnchanged, err := applySchemaChanges(ctx, schema, db, result)
+	if err != nil {
+		return nil, err
+	}
+
 	var processed []processedDoc
-	layerFiles := make(map[string][]string)
+	var only map[string]struct{}
+	if options.Files != nil {
+		only = make(map[string]struct{}, len(options.Files))
+		for _, path := range options.Files {
+			only[filepath.Clean(path)] = struct{}{}
+		}
+	}
+	layerSources := make(map[string][]string)
 
 	for _, layer := range cfg.Layers {
-		var existingHashes map[string]string
-		if !options.Full {
-			var err error
-			existingHashes, err = db.GetLayerHashes(ctx, layer.Name)
-			if err != nil {
-				return nil, fmt.Errorf("get layer hashes for %s: %w", layer.Name, err)
-			}
+		existingHashes, err := db.GetLayerHashes(ctx, layer.Name)
+		if err != nil {
+			return nil, fmt.Errorf("get layer hashes for %s: %w", layer.Name, err)
 		}
 
-		files, err := walkMarkdownFiles(layer.Paths, cfg.Exclude)
+		files, err := walkMarkdownFiles(layer, cfg.Exclude)
 		if err != nil {
 			return nil, fmt.Errorf("walking files for layer %s: %w", layer.Name, err)
 		}
-		layerFiles[layer.Name] = files
 
 		for _, path := range files {
-			hash, err := computeHash(path)
+			if only != nil {
+				if _, ok := only[filepath.Clean(path)]; !ok {
+					layerSources[layer.Name] = append(layerSources[layer.Name], storedSources(existingHashes, path)...)
+					continue
+				}
+			}
+
+			rule, ruled := layer.RuleFor(path)
+			var defaults map[string]any
+			if ruled {
+				defaults = rule.Frontmatter()
+			}
+			data, err := os.ReadFile(path)
 			if err != nil {
-				result.Errors = append(result.Errors, fmt.Errorf("hashing %s: %w", path, err))
+				layerSources[layer.Name] = append(layerSources[layer.Name], storedSources(existingHashes, path)...)
+				result.Errors = append(result.Errors, fmt.Errorf("reading %s: %w", path, err))
 				continue
 			}
-			if !options.Full {
-				if existing, ok := existingHashes[path]; ok && existing == hash {
-					result.FilesSkipped++
-					continue
-				}
+			// A file whose last entity recorded the hash of the whole file is
+			// unchanged and needs no parsing. Entity types that left the
+			// schema are only dropped when their sections are seen, so this
+			// waits for a run with an unchanged schema.
+			fileHash := contentHash(data, defaults)
+			if !options.Full && schemaUnchanged && hasStoredHash(existingHashes, path, fileHash) {
+				layerSources[layer.Name] = append(layerSources[layer.Name], storedSources(existingHashes, path)...)
+				result.FilesSkipped++
+				continue
 			}
 
-			doc, err := parser.ParseFile(path)
+			docs, err := parser.ParseAllWithDefaults(data, defaults)
 			if err != nil {
+				// Entities already stored from a file that no longer parses
+				// are kept until it is fixed or deleted.
+				layerSources[layer.Name] = append(layerSources[layer.Name], storedSources(existingHashes, path)...)
 				if err == parser.ErrNoFrontmatter || err == parser.ErrMissingType {
 					result.FilesSkipped++
 					continue
@@ -81,53 +177,87 @@ func Run(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema,
 				continue
 			}
 
-			if !schema.IsValidEntityType(doc.EntityType) {
-				result.FilesSkipped++
-				continue
-			}
+			fileErrors := len(result.Errors)
+			for i, doc := range docs {
+				doc.SourceFile = path
+				last := i == len(docs)-1
+				source := store.SourceKey(path, doc.Anchor)
+				if ruled && rule.Type != "" && !slices.Contains(doc.Inferred, "type") && !strings.EqualFold(doc.EntityType, rule.Type) {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("%s: type %s conflicts with type %s from path rule %s", source, doc.EntityType, rule.Type, rule.Glob))
+				}
 
-			entityType, _ := schema.EntityTypeByName(doc.EntityType)
-			props := filterProperties(doc.Frontmatter, entityType)
+				// Entities whose type left the schema are removed as stale.
+				if !schema.IsValidEntityType(doc.EntityType) {
+					result.EntitiesSkipped++
+					continue
+				}
+				layerSources[layer.Name] = append(layerSources[layer.Name], source)
 
-			if strings.EqualFold(doc.EntityType, "event") {
-				if value, ok := doc.Frontmatter["consequences"]; ok {
-					consequences, err := parseConsequences(value)
-					if err != nil {
-						result.Errors = append(result.Errors, fmt.Errorf("parsing consequences in %s: %w", path, err))
-						continue
-					}
-					payload, err := json.Marshal(consequences)
-					if err != nil {
-						result.Errors = append(result.Errors, fmt.Errorf("encoding consequences in %s: %w", path, err))
+				hash := entityHash(doc)
+				entityType, _ := schema.EntityTypeByName(doc.EntityType)
+				if !options.Full && !reingest[strings.ToLower(entityType.Name)] {
+					if existing, ok := existingHashes[source]; ok && (existing == hash || last && existing == fileHash) {
+						result.EntitiesSkipped++
 						continue
 					}
-					if props == nil {
-						props = make(map[string]any)
+				}
+				// The last entity is written last, so it can vouch for the
+				// whole file once every other section was stored.
+				if last && len(result.Errors) == fileErrors {
+					hash = fileHash
+				}
+
+				if entityType.Abstract {
+					result.Errors = append(result.Errors, fmt.Errorf("%s: entity type %s is abstract", source, entityType.Name))
+					continue
+				}
+				props, defaulted := filterProperties(doc.Frontmatter, entityType)
+
+				if schema.IsA(doc.EntityType, "event") {
+					if value, ok := doc.Frontmatter["consequences"]; ok {
+						consequences, err := parseConsequences(value)
+						if err != nil {
+							result.Errors = append(result.Errors, fmt.Errorf("parsing consequences in %s: %w", source, err))
+							continue
+						}
+						payload, err := json.Marshal(consequences)
+						if err != nil {
+							result.Errors = append(result.Errors, fmt.Errorf("encoding consequences in %s: %w", source, err))
+							continue
+						}
+						if props == nil {
+							props = make(map[string]any)
+						}
+						props["consequences_json"] = string(payload)
 					}
-					props["consequences_json"] = string(payload)
 				}
-			}
 
-			input := store.EntityInput{
-				Name:       doc.Title,
-				EntityType: doc.EntityType,
-				Layer:      layer.Name,
-				SourceFile: path,
-				SourceHash: hash,
-				Properties: props,
-				Tags:       doc.Tags,
-				Body:       doc.Body,
-			}
+				input := store.EntityInput{
+					Name:         doc.Title,
+					EntityType:   doc.EntityType,
+					Layer:        layer.Name,
+					SourceFile:   path,
+					SourceAnchor: doc.Anchor,
+					SourceHash:   hash,
+					Properties:   props,
+					Tags:         doc.Tags,
+					Aliases:      doc.Aliases,
+					Body:         doc.Body,
+					Secret:       doc.Secret,
+					Defaulted:    defaulted,
+				}
 
-			if err := db.UpsertEntity(ctx, input); err != nil {
-				result.Errors = append(result.Errors, fmt.Errorf("upserting %s: %w", path, err))
-				continue
+				if err := db.UpsertEntity(ctx, input); err != nil {
+					result.Errors = append(result.Errors, fmt.Errorf("upserting %s: %w", source, err))
+					continue
+				}
+				result.NodesUpserted++
+				processed = append(processed, processedDoc{doc: doc, layer: layer})
 			}
-			result.NodesUpserted++
-			processed = append(processed, processedDoc{doc: doc, layer: layer})
 		}
 	}
 
+	linkRel := cfg.Links.RelationshipType()
 	for _, item := range processed {
 		entityType, _ := schema.EntityTypeByName(item.doc.EntityType)
 		for _, mapping := range entityType.FieldMappings {
@@ -136,17 +266,10 @@ func Run(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema,
 					if target == "" {
 						continue
 					}
-					targetLayer := item.layer.Name
-					if len(item.layer.DependsOn) > 0 {
-						layers := append([]string{item.layer.Name}, item.layer.DependsOn...)
-						layerName, err := db.FindEntityLayer(ctx, target, layers)
-						if err != nil {
-							result.Errors = append(result.Errors, fmt.Errorf("finding layer for %s: %w", target, err))
-							continue
-						}
-						if layerName != "" {
-							targetLayer = layerName
-						}
+					targetLayer, err := resolveTargetLayer(ctx, db, item.layer, target)
+					if err != nil {
+						result.Errors = append(result.Errors, err)
+						continue
 					}
 					if err := db.UpsertRelationship(ctx, item.doc.Title, item.layer.Name, target, targetLayer, mapping.Relationship); err != nil {
 						result.Errors = append(result.Errors, fmt.Errorf("upserting relationship for %s: %w", item.doc.Title, err))
@@ -162,17 +285,10 @@ func Run(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema,
 				if target == "" {
 					continue
 				}
-				targetLayer := item.layer.Name
-				if len(item.layer.DependsOn) > 0 {
-					layers := append([]string{item.layer.Name}, item.layer.DependsOn...)
-					layerName, err := db.FindEntityLayer(ctx, target, layers)
-					if err != nil {
-						result.Errors = append(result.Errors, fmt.Errorf("finding layer for %s: %w", target, err))
-						continue
-					}
-					if layerName != "" {
-						targetLayer = layerName
-					}
+				targetLayer, err := resolveTargetLayer(ctx, db, item.layer, target)
+				if err != nil {
+					result.Errors = append(result.Errors, err)
+					continue
 				}
 				if err := db.UpsertRelationship(ctx, item.doc.Title, item.layer.Name, target, targetLayer, "RELATED_TO"); err != nil {
 					result.Errors = append(result.Errors, fmt.Errorf("upserting related for %s: %w", item.doc.Title, err))
@@ -181,10 +297,38 @@ func Run(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema,
 				result.EdgesUpserted++
 			}
 		}
+
+		if cfg.Links.Disabled {
+			continue
+		}
+		targets := linkTargets(cfg, item.doc)
+		// Link edges are only understood by queries and validate when their
+		// type is declared like any other relationship. Projects from before
+		// links existed may not declare MENTIONS, so this only warns.
+		if len(targets) > 0 && !schema.IsValidRelationshipType(linkRel) {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: links skipped: relationship %s is not in the schema; declare it or set links.disabled",
+				store.SourceKey(item.doc.SourceFile, item.doc.Anchor), linkRel))
+			continue
+		}
+		for _, target := range targets {
+			if strings.EqualFold(target, item.doc.Title) {
+				continue
+			}
+			targetLayer, err := resolveTargetLayer(ctx, db, item.layer, target)
+			if err != nil {
+				result.Errors = append(result.Errors, err)
+				continue
+			}
+			if err := db.UpsertRelationship(ctx, item.doc.Title, item.layer.Name, target, targetLayer, linkRel); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("upserting link for %s: %w", item.doc.Title, err))
+				continue
+			}
+			result.EdgesUpserted++
+		}
 	}
 
 	for _, layer := range cfg.Layers {
-		deleted, err := db.RemoveStaleNodes(ctx, layer.Name, layerFiles[layer.Name])
+		deleted, err := db.RemoveStaleNodes(ctx, layer.Name, layerSources[layer.Name])
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Errorf("removing stale nodes for %s: %w", layer.Name, err))
 			continue
@@ -192,10 +336,141 @@ func Run(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema,
 		result.NodesRemoved += int(deleted)
 	}
 
+	orphaned, err := db.RemoveOrphanedPlaceholders(ctx)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("removing orphaned placeholders: %w", err))
+	} else {
+		result.NodesRemoved += int(orphaned)
+	}
+
+	// Only a run that ingested every file brings the database in line with
+	// the schema; otherwise the next run compares against the old one again.
+	if options.Files == nil && len(result.Errors) == 0 {
+		if err := db.SaveSchemaState(ctx, store.SchemaState{Fingerprint: schema.Fingerprint(), Source: schema.Source()}); err != nil {
+			return nil, fmt.Errorf("save schema state: %w", err)
+		}
+	}
+
 	return result, nil
 }
 
-func walkMarkdownFiles(roots []string, excludes []string) ([]string, error) {
+// applySchemaChanges compares the schema with the one recorded by the last
+// complete ingestion, moves stored data to the new names it declares, and
+// returns the lower-cased names of the entity types to ingest again.
+func applySchemaChanges(ctx context.Context, schema *config.Schema, db Store, result *Result) (map[string]bool, bool, error) {
+	state, err := db.GetSchemaState(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("get schema state: %w", err)
+	}
+	if state == nil {
+		return nil, false, nil
+	}
+	if state.Fingerprint == schema.Fingerprint() {
+		return nil, true, nil
+	}
+
+	var diff config.SchemaDiff
+	previous, err := config.ParseSchema(state.Source)
+	if err != nil {
+		// A recorded schema that no longer loads cannot be compared.
+		for _, entityType := range schema.EntityTypes {
+			diff.Reingest = append(diff.Reingest, entityType.Name)
+		}
+	} else {
+		diff = config.DiffSchemas(previous, schema)
+	}
+
+	for _, rename := range diff.PropertyRenames {
+		renamed, err := db.RenameProperty(ctx, rename.EntityType, rename.From, rename.To)
+		if err != nil {
+			return nil, false, fmt.Errorf("rename property: %w", err)
+		}
+		result.Renamed += int(renamed)
+	}
+	for _, rename := range diff.RelationshipRenames {
+		renamed, err := db.RenameRelationshipType(ctx, rename.From, rename.To)
+		if err != nil {
+			return nil, false, fmt.Errorf("rename relationship: %w", err)
+		}
+		result.Renamed += int(renamed)
+	}
+
+	reingest := make(map[string]bool, len(diff.Reingest))
+	for _, name := range diff.Reingest {
+		reingest[strings.ToLower(name)] = true
+	}
+	result.Reingested = diff.Reingest
+	return reingest, false, nil
+}
+
+// resolveTargetLayer picks the layer an edge target lives in. Layers with
+// dependencies may point at entities defined in a parent layer; anything not
+// found is created as a placeholder in the source layer.
+func resolveTargetLayer(ctx context.Context, db Store, layer config.Layer, target string) (string, error) {
+	if len(layer.DependsOn) == 0 {
+		return layer.Name, nil
+	}
+	layers := append([]string{layer.Name}, layer.DependsOn...)
+	layerName, err := db.FindEntityLayer(ctx, target, layers)
+	if err != nil {
+		return "", fmt.Errorf("finding layer for %s: %w", target, err)
+	}
+	if layerName == "" {
+		return layer.Name, nil
+	}
+	return layerName, nil
+}
+
+// linkTargets resolves the body links of doc to entity names. Markdown links
+// are followed only when enabled, and name the title of the linked file, or
+// of the entity under the linked anchor when the file defines several; links
+// to files that are missing or have no frontmatter are ignored.
+func linkTargets(cfg *config.ProjectConfig, doc *parser.Document) []string {
+	var targets []string
+	seen := make(map[string]struct{})
+	for _, link := range doc.Links {
+		target := link.Target
+		if link.Path != "" {
+			if !cfg.Links.Markdown {
+				continue
+			}
+			path := filepath.Join(filepath.Dir(doc.SourceFile), filepath.FromSlash(link.Path))
+			linked, err := parser.ParseFileAllWithDefaults(path, ruleDefaults(cfg, path))
+			if err != nil {
+				continue
+			}
+			target = linked[0].Title
+			for _, entity := range linked {
+				if link.Anchor != "" && entity.Anchor == link.Anchor {
+					target = entity.Title
+					break
+				}
+			}
+		}
+		key := strings.ToLower(target)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// ruleDefaults returns the frontmatter supplied by the first path rule of any
+// layer that matches path.
+func ruleDefaults(cfg *config.ProjectConfig, path string) map[string]any {
+	for _, layer := range cfg.Layers {
+		if rule, ok := layer.RuleFor(path); ok {
+			return rule.Frontmatter()
+		}
+	}
+	return nil
+}
+
+// walkMarkdownFiles lists the markdown files under the layer's paths that
+// the layer includes, leaving out the project-wide excludes.
+func walkMarkdownFiles(layer config.Layer, excludes []string) ([]string, error) {
 	excluded := make([]string, 0, len(excludes))
 	for _, path := range excludes {
 		if path == "" {
@@ -205,7 +480,7 @@ func walkMarkdownFiles(roots []string, excludes []string) ([]string, error) {
 	}
 
 	var files []string
-	for _, root := range roots {
+	for _, root := range layer.Paths {
 		if root == "" {
 			continue
 		}
@@ -223,7 +498,7 @@ func walkMarkdownFiles(roots []string, excludes []string) ([]string, error) {
 			if !strings.HasSuffix(strings.ToLower(d.Name()), ".md") {
 				return nil
 			}
-			if isExcluded(path, excluded) {
+			if isExcluded(path, excluded) || !layer.Includes(path) {
 				return nil
 			}
 			files = append(files, path)
@@ -246,13 +521,55 @@ func isExcluded(path string, excludes []string) bool {
 	return false
 }
 
-func computeHash(path string) (string, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
+// entityHash hashes the source of doc together with the values it took from
+// path rules, so changing a rule re-ingests the entities it applies to.
+func entityHash(doc *parser.Document) string {
+	if len(doc.Inferred) == 0 {
+		return computeHash(doc.Raw)
+	}
+	inferred := make(map[string]any, len(doc.Inferred))
+	for _, key := range doc.Inferred {
+		inferred[key] = doc.Frontmatter[key]
 	}
+	// fmt prints maps sorted by key, so the encoding is stable.
+	return computeHash(fmt.Appendf(slices.Clone(doc.Raw), "%v", inferred))
+}
+
+// contentHash hashes a whole file together with the path rule defaults
+// applied to it. Without defaults it matches entityHash of a file holding a
+// single entity.
+func contentHash(data []byte, defaults map[string]any) string {
+	if len(defaults) == 0 {
+		return computeHash(data)
+	}
+	return computeHash(fmt.Appendf(slices.Clone(data), "%v", defaults))
+}
+
+func computeHash(data []byte) string {
 	sum := sha256.Sum256(data)
-	return hex.EncodeToString(sum[:]), nil
+	return hex.EncodeToString(sum[:])
+}
+
+// hasStoredHash reports whether an entity stored from path has hash.
+func hasStoredHash(hashes map[string]string, path, hash string) bool {
+	for _, source := range storedSources(hashes, path) {
+		if hashes[source] == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// storedSources returns path and the source keys stored for the entities of
+// its sections, so a file that is not parsed in this run keeps them.
+func storedSources(hashes map[string]string, path string) []string {
+	sources := []string{path}
+	for source := range hashes {
+		if strings.HasPrefix(source, path+"#") {
+			sources = append(sources, source)
+		}
+	}
+	return sources
 }
 
 func resolveFieldValue(value any) []string {
@@ -276,35 +593,59 @@ func resolveFieldValue(value any) []string {
 	}
 }
 
-func filterProperties(frontmatter map[string]any, entityType *config.EntityType) map[string]any {
+// filterProperties keeps the frontmatter keys declared as properties of the
+// entity type, coerced to their declared types, and fills in schema defaults
+// for properties the file does not set. The names of defaulted properties are
+// returned so the stored entity can distinguish them from authored values.
+func filterProperties(frontmatter map[string]any, entityType *config.EntityType) (map[string]any, []string) {
 	if frontmatter == nil || entityType == nil {
-		return nil
+		return nil, nil
 	}
 
 	props := make(map[string]any)
 	for key, value := range frontmatter {
-		if key == "title" || key == "type" || key == "tags" || key == "related" || key == "consequences" {
+		if key == "title" || key == "type" || key == "tags" || key == "aliases" || key == "related" || key == "consequences" {
 			continue
 		}
 		if isFieldMapping(entityType, key) {
 			continue
 		}
-		if !isProperty(entityType, key) {
-			continue
+		prop, ok := entityType.PropertyByName(key)
+		if !ok {
+			// A key under a property's previous name still sets it, unless
+			// the file also uses the new name.
+			prop, ok = entityType.PropertyRenamedFrom(key)
+			if !ok {
+				continue
+			}
+			if _, set := frontmatter[prop.Name]; set {
+				continue
+			}
+		}
+		// Values that do not coerce are kept as authored; validate reports them.
+		if coerced, err := prop.Coerce(value); err == nil {
+			value = coerced
 		}
-		props[key] = value
+		props[prop.Name] = value
 	}
 
-	return props
-}
-
-func isProperty(entityType *config.EntityType, key string) bool {
+	var defaulted []string
 	for _, prop := range entityType.Properties {
-		if prop.Name == key {
-			return true
+		if prop.Default == "" {
+			continue
+		}
+		if _, ok := props[prop.Name]; ok {
+			continue
 		}
+		value, err := prop.Coerce(prop.Default)
+		if err != nil {
+			continue
+		}
+		props[prop.Name] = value
+		defaulted = append(defaulted, prop.Name)
 	}
-	return false
+
+	return props, defaulted
 }
 
 func isFieldMapping(entityType *config.EntityType, key string) bool {
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"lorecraft/internal/config"
 	"lorecraft/internal/store"
@@ -28,8 +31,12 @@ type mockStore struct {
 	}
 	ensureCalled bool
 	failUpsert   bool
+	committed    bool
+	rolledBack   bool
+	orphanCalls  int
 	layerHashes  map[string]map[string]string
 	entityLayers map[string]map[string]struct{}
+	schemaState  *store.SchemaState
 }
 
 func (m *mockStore) Close(ctx context.Context) error { return nil }
@@ -39,6 +46,17 @@ func (m *mockStore) EnsureSchema(ctx context.Context, schema *config.Schema) err
 	return nil
 }
 
+func (m *mockStore) InTransaction(ctx context.Context, fn func(store.Store) error) error {
+	if err := fn(m); err != nil {
+		m.rolledBack = true
+		return err
+	}
+	m.committed = true
+	return nil
+}
+
+func (m *mockStore) WithVisibility(v *store.Visibility) store.Store { return m }
+
 func (m *mockStore) UpsertEntity(ctx context.Context, e store.EntityInput) error {
 	if m.failUpsert && e.Name == "Test NPC" {
 		return errors.New("forced error")
@@ -66,6 +84,11 @@ func (m *mockStore) RemoveStaleNodes(ctx context.Context, layer string, currentS
 	return int64(0), nil
 }
 
+func (m *mockStore) RemoveOrphanedPlaceholders(ctx context.Context) (int64, error) {
+	m.orphanCalls++
+	return 0, nil
+}
+
 func (m *mockStore) GetLayerHashes(ctx context.Context, layer string) (map[string]string, error) {
 	if m.layerHashes == nil {
 		return map[string]string{}, nil
@@ -93,6 +116,27 @@ func (m *mockStore) FindEntityLayer(ctx context.Context, name string, layers []s
 	return "", nil
 }
 
+func (m *mockStore) GetSchemaState(ctx context.Context) (*store.SchemaState, error) {
+	return m.schemaState, nil
+}
+
+func (m *mockStore) SaveSchemaState(ctx context.Context, state store.SchemaState) error {
+	m.schemaState = &state
+	return nil
+}
+
+func (m *mockStore) RenameProperty(ctx context.Context, entityType, from, to string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStore) RenameRelationshipType(ctx context.Context, from, to string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStore) GetEntityInLayer(ctx context.Context, name, layer string) (*store.Entity, error) {
+	return nil, nil
+}
+
 func (m *mockStore) GetEntity(ctx context.Context, name, entityType string) (*store.Entity, error) {
 	return nil, nil
 }
@@ -101,6 +145,14 @@ func (m *mockStore) GetRelationships(ctx context.Context, name, relType, directi
 	return nil, nil
 }
 
+func (m *mockStore) FindPaths(ctx context.Context, from, to string, maxDepth int, relTypes []string) ([]store.Path, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SuggestEntities(ctx context.Context, name string, limit int) ([]store.EntitySuggestion, error) {
+	return nil, nil
+}
+
 func (m *mockStore) ListEntities(ctx context.Context, entityType, layer, tag string) ([]store.EntitySummary, error) {
 	return nil, nil
 }
@@ -133,6 +185,10 @@ func (m *mockStore) ListCrossLayerViolations(ctx context.Context) ([]store.Entit
 	return nil, nil
 }
 
+func (m *mockStore) ListEdges(ctx context.Context) ([]store.Edge, error) {
+	return nil, nil
+}
+
 func (m *mockStore) RunSQL(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
 	return nil, nil
 }
@@ -171,8 +227,8 @@ func TestRun_SkipsUnknownTypes(t *testing.T) {
 		t.Fatalf("run: %v", err)
 	}
 
-	if result.FilesSkipped == 0 {
-		t.Fatalf("expected files skipped")
+	if result.EntitiesSkipped == 0 {
+		t.Fatalf("expected entities skipped")
 	}
 }
 
@@ -248,6 +304,32 @@ func TestRun_ContinuesOnError(t *testing.T) {
 	}
 }
 
+func TestRun_AtomicRollsBackOnError(t *testing.T) {
+	cfg := testProjectConfig(t)
+	schema := testSchema(t)
+	client := &mockStore{failUpsert: true}
+
+	result, err := Run(context.Background(), cfg, schema, client, Options{Atomic: true})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !result.RolledBack || !client.rolledBack || client.committed {
+		t.Fatalf("expected rollback, got result %v, rolled back %v, committed %v", result.RolledBack, client.rolledBack, client.committed)
+	}
+	if len(result.Errors) == 0 {
+		t.Fatalf("expected errors to be reported")
+	}
+
+	client = &mockStore{}
+	result, err = Run(context.Background(), cfg, schema, client, Options{Atomic: true})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.RolledBack || !client.committed {
+		t.Fatalf("expected clean atomic run to commit")
+	}
+}
+
 func TestRun_RemoveStaleNodes(t *testing.T) {
 	cfg := testProjectConfig(t)
 	schema := testSchema(t)
@@ -263,16 +345,20 @@ func TestRun_RemoveStaleNodes(t *testing.T) {
 	if len(client.removeCalls[0].files) == 0 {
 		t.Fatalf("expected file list")
 	}
+	if client.orphanCalls != 1 {
+		t.Fatalf("expected orphaned placeholder cleanup, got %d calls", client.orphanCalls)
+	}
 }
 
 func TestRun_IncrementalSkip(t *testing.T) {
 	cfg := testProjectConfig(t)
 	schema := testSchema(t)
 	path := filepath.Join("testdata", "lore", "valid_npc.md")
-	hash, err := computeHash(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
-		t.Fatalf("compute hash: %v", err)
+		t.Fatalf("read file: %v", err)
 	}
+	hash := computeHash(data)
 	client := &mockStore{
 		layerHashes: map[string]map[string]string{
 			"setting": {path: hash},
@@ -341,10 +427,11 @@ func TestRun_FullIngestionOverridesHashes(t *testing.T) {
 	cfg := testProjectConfig(t)
 	schema := testSchema(t)
 	path := filepath.Join("testdata", "lore", "valid_npc.md")
-	hash, err := computeHash(path)
+	data, err := os.ReadFile(path)
 	if err != nil {
-		t.Fatalf("compute hash: %v", err)
+		t.Fatalf("read file: %v", err)
 	}
+	hash := computeHash(data)
 	client := &mockStore{
 		layerHashes: map[string]map[string]string{
 			"setting": {path: hash},
@@ -368,6 +455,43 @@ func TestRun_FullIngestionOverridesHashes(t *testing.T) {
 	}
 }
 
+func TestRun_SchemaChangeReingestsChangedTypes(t *testing.T) {
+	cfg := testProjectConfig(t)
+	schema := testSchema(t)
+	hashes := make(map[string]string)
+	for _, name := range []string{"valid_npc.md", "valid_faction.md"} {
+		path := filepath.Join("testdata", "lore", name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read file: %v", err)
+		}
+		hashes[path] = computeHash(data)
+	}
+	previous := strings.Replace(string(schema.Source()), "      - { name: status, type: string }\n", "", 1)
+	client := &mockStore{
+		layerHashes: map[string]map[string]string{"setting": hashes},
+		schemaState: &store.SchemaState{Fingerprint: "previous", Source: []byte(previous)},
+	}
+
+	result, err := Run(context.Background(), cfg, schema, client, Options{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !reflect.DeepEqual(result.Reingested, []string{"npc"}) {
+		t.Fatalf("expected npc to be re-ingested, got %v", result.Reingested)
+	}
+	var names []string
+	for _, entity := range client.entities {
+		names = append(names, entity.Name)
+	}
+	if !slices.Contains(names, "Test NPC") || slices.Contains(names, "The Watch") {
+		t.Fatalf("expected only the unchanged npc to be upserted again, got %v", names)
+	}
+	if client.schemaState.Fingerprint != schema.Fingerprint() {
+		t.Fatalf("expected the current schema to be recorded, got %#v", client.schemaState)
+	}
+}
+
 func TestRun_DependsOnResolution(t *testing.T) {
 	settingDir := t.TempDir()
 	cfg := &config.ProjectConfig{
@@ -406,6 +530,234 @@ func TestRun_DependsOnResolution(t *testing.T) {
 	}
 }
 
+func TestRun_BodyLinks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"captain.md": "---\ntitle: Captain Vey\ntype: npc\n---\n\nVey answers to [[Mayor Teston|the mayor]] and drills [the watch](watch.md).\nShe also mentions [[Mayor Teston]] and herself, [[Captain Vey]].\n\n```\n[[Not A Link]]\n```\n",
+		"watch.md":   "---\ntitle: The Watch\ntype: faction\n---\n\nThe city guard.\n",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "postgres://localhost:5432/lorecraft"},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{dir}}},
+	}
+
+	mentions := func(client *mockStore, relType string) []string {
+		var targets []string
+		for _, rel := range client.relationships {
+			if rel.relType == relType && rel.fromName == "Captain Vey" {
+				targets = append(targets, rel.toName)
+			}
+		}
+		return targets
+	}
+
+	client := &mockStore{}
+	if _, err := Run(context.Background(), cfg, testSchema(t), client, Options{Full: true}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := mentions(client, "MENTIONS"); !reflect.DeepEqual(got, []string{"Mayor Teston"}) {
+		t.Fatalf("expected wiki link only, got %#v", got)
+	}
+
+	cfg.Links = config.LinksConfig{Relationship: "references", Markdown: true}
+	client = &mockStore{}
+	result, err := Run(context.Background(), cfg, testSchema(t), client, Options{Full: true})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := mentions(client, "REFERENCES"); len(got) != 0 {
+		t.Fatalf("expected no edges of an undeclared link relationship, got %#v", got)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "REFERENCES") {
+		t.Fatalf("expected a warning for the document with links, got %v", result.Warnings)
+	}
+
+	cfg.Links = config.LinksConfig{Relationship: "related_to", Markdown: true}
+	client = &mockStore{}
+	if _, err := Run(context.Background(), cfg, testSchema(t), client, Options{Full: true}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if got := mentions(client, "RELATED_TO"); !reflect.DeepEqual(got, []string{"Mayor Teston", "The Watch"}) {
+		t.Fatalf("expected wiki and markdown links, got %#v", got)
+	}
+}
+
+func TestRun_FilesRestrictsParsing(t *testing.T) {
+	cfg := testProjectConfig(t)
+	client := &mockStore{}
+
+	target := filepath.Join("testdata", "lore", "valid_faction.md")
+	result, err := Run(context.Background(), cfg, testSchema(t), client, Options{Files: []string{target}})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(client.entities) != 1 || client.entities[0].SourceFile != target {
+		t.Fatalf("expected only %s to be ingested, got %#v", target, client.entities)
+	}
+	if result.NodesUpserted != 1 {
+		t.Fatalf("expected 1 node upserted, got %d", result.NodesUpserted)
+	}
+	if len(client.removeCalls) != 1 || len(client.removeCalls[0].files) != 5 {
+		t.Fatalf("expected stale removal to see every file, got %#v", client.removeCalls)
+	}
+}
+
+func TestRun_PathRules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"npcs/mira.md":        "---\ntitle: Mira Vell\n---\n",
+		"npcs/brine.md":       "---\ntitle: Tomas Brine\ntype: faction\n---\n",
+		"npcs/archive/old.md": "---\ntitle: Old Nessa\n---\n",
+		"factions/watch.md":   "---\ntitle: The Watch\ntype: faction\n---\n",
+		"notes/ideas.md":      "---\ntitle: Ideas\ntype: npc\n---\n",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	cfg := testProjectConfig(t)
+	cfg.Layers[0].Paths = []string{dir}
+	cfg.Layers[0].Include = []string{"npcs", "factions/*.md"}
+	cfg.Layers[0].Exclude = []string{"**/archive"}
+	cfg.Layers[0].Rules = []config.PathRule{{Glob: "npcs/**/*.md", Type: "npc", Defaults: map[string]any{"status": "alive"}}}
+	client := &mockStore{}
+
+	result, err := Run(context.Background(), cfg, testSchema(t), client, Options{})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+
+	byName := map[string]store.EntityInput{}
+	for _, entity := range client.entities {
+		byName[entity.Name] = entity
+	}
+	if len(byName) != 3 {
+		t.Fatalf("expected the included files to be ingested, got %#v", byName)
+	}
+	mira := byName["Mira Vell"]
+	if mira.EntityType != "npc" || mira.Properties["status"] != "alive" {
+		t.Fatalf("expected the path rule to supply type and defaults, got %#v", mira)
+	}
+	if byName["Tomas Brine"].EntityType != "faction" {
+		t.Fatalf("expected an explicit type to win, got %#v", byName["Tomas Brine"])
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "conflicts with type npc") {
+		t.Fatalf("expected a conflict warning, got %v", result.Warnings)
+	}
+
+	cfg.Layers[0].Rules[0].Type = "dragon"
+	if _, err := Run(context.Background(), cfg, testSchema(t), &mockStore{}, Options{}); err == nil {
+		t.Fatalf("expected a rule with an unknown type to be rejected")
+	}
+}
+
+func TestWatch_IngestsChanges(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "postgres://localhost:5432/lorecraft"},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{dir}}},
+	}
+	existing := filepath.Join(dir, "watch.md")
+	if err := os.WriteFile(existing, []byte("---\ntitle: The Watch\ntype: faction\n---\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	schema := testSchema(t)
+	changes := make(chan Change, 4)
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		options := WatchOptions{Interval: 10 * time.Millisecond, Debounce: 30 * time.Millisecond, Ready: func() { close(ready) }}
+		done <- Watch(ctx, cfg, schema, &mockStore{}, options, func(change Change) {
+			changes <- change
+		})
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for watch to start")
+	}
+	added := filepath.Join(dir, "captain.md")
+	if err := os.WriteFile(added, []byte("---\ntitle: Captain Vey\ntype: npc\n---\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Remove(existing); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	select {
+	case change := <-changes:
+		if change.Err != nil {
+			t.Fatalf("watch error: %v", change.Err)
+		}
+		if !reflect.DeepEqual(change.Added, []string{added}) || !reflect.DeepEqual(change.Removed, []string{existing}) {
+			t.Fatalf("unexpected change: %#v", change)
+		}
+		if change.Result.NodesUpserted != 1 {
+			t.Fatalf("expected only the added file to be ingested, got %d", change.Result.NodesUpserted)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for change")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	now := time.Now()
+	previous := map[string]fileState{
+		"a.md": {modTime: now, size: 1},
+		"b.md": {modTime: now, size: 1},
+	}
+	current := map[string]fileState{
+		"a.md": {modTime: now.Add(time.Second), size: 1},
+		"c.md": {modTime: now, size: 1},
+	}
+
+	pending := map[string]string{}
+	if !diffSnapshots(previous, current, pending) {
+		t.Fatalf("expected changes")
+	}
+	expected := map[string]string{"a.md": "modified", "b.md": "removed", "c.md": "added"}
+	if !reflect.DeepEqual(pending, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, pending)
+	}
+
+	if !diffSnapshots(current, previous, pending) {
+		t.Fatalf("expected changes")
+	}
+	expected = map[string]string{"a.md": "modified", "b.md": "modified"}
+	if !reflect.DeepEqual(pending, expected) {
+		t.Fatalf("expected added-then-removed file to be dropped, got %#v", pending)
+	}
+}
+
 func TestResolveFieldValue(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -428,6 +780,67 @@ func TestResolveFieldValue(t *testing.T) {
 	}
 }
 
+func TestFilterProperties_CoercesTypes(t *testing.T) {
+	entityType := &config.EntityType{
+		Name: "event",
+		Properties: []config.Property{
+			{Name: "session", Type: "integer"},
+			{Name: "canon", Type: "boolean"},
+			{Name: "districts", Type: "list"},
+			{Name: "title_note", Type: "string"},
+		},
+	}
+	frontmatter := map[string]any{
+		"title":      "Storm Surge",
+		"session":    "2",
+		"canon":      "yes",
+		"districts":  "Harbor Ward",
+		"title_note": 7,
+		"undeclared": "ignored",
+	}
+
+	got, _ := filterProperties(frontmatter, entityType)
+	expected := map[string]any{
+		"session":    2,
+		"canon":      true,
+		"districts":  []any{"Harbor Ward"},
+		"title_note": "7",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, got)
+	}
+
+	got, _ = filterProperties(map[string]any{"session": "two"}, entityType)
+	if got["session"] != "two" {
+		t.Fatalf("expected uncoercible value to be kept, got %#v", got["session"])
+	}
+}
+
+func TestFilterProperties_AppliesDefaults(t *testing.T) {
+	entityType := &config.EntityType{
+		Name: "npc",
+		Properties: []config.Property{
+			{Name: "status", Type: "enum", Values: []string{"alive", "dead"}, Default: "alive"},
+			{Name: "visibility", Type: "enum", Values: []string{"player", "gm"}, Default: "gm"},
+			{Name: "level", Type: "integer", Default: "1"},
+			{Name: "role", Type: "string"},
+		},
+	}
+
+	got, defaulted := filterProperties(map[string]any{"title": "Mira", "visibility": "player"}, entityType)
+	expected := map[string]any{
+		"status":     "alive",
+		"visibility": "player",
+		"level":      1,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, got)
+	}
+	if !reflect.DeepEqual(defaulted, []string{"status", "level"}) {
+		t.Fatalf("expected status and level to be defaulted, got %#v", defaulted)
+	}
+}
+
 func testProjectConfig(t *testing.T) *config.ProjectConfig {
 	t.Helper()
 	return &config.ProjectConfig{
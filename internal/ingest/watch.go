@@ -0,0 +1,172 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"lorecraft/internal/config"
+)
+
+const (
+	DefaultWatchInterval = time.Second
+	DefaultWatchDebounce = 500 * time.Millisecond
+)
+
+type WatchOptions struct {
+	// Interval is how often layer paths are polled for changes.
+	Interval time.Duration
+	// Debounce is how long the tree must stay unchanged before the pending
+	// changes are ingested, so editors that write in several steps trigger a
+	// single run.
+	Debounce time.Duration
+	// Ready, if set, is called once the starting state of the layer paths has
+	// been recorded; changes made after it returns are picked up.
+	Ready func()
+}
+
+// Change describes one batch of file changes and the incremental ingestion
+// run that picked them up.
+type Change struct {
+	Added    []string
+	Modified []string
+	Removed  []string
+	Result   *Result
+	Err      error
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch polls every layer path for added, modified and removed markdown files
+// and re-runs incremental ingestion for the affected files once changes settle.
+// Polling keeps it portable to filesystems without change notifications. Each
+// batch is passed to report; Watch returns when ctx is cancelled.
+func Watch(ctx context.Context, cfg *config.ProjectConfig, schema *config.Schema, db Store, options WatchOptions, report func(Change)) error {
+	interval := options.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	debounce := options.Debounce
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+
+	previous, err := snapshotLayers(cfg)
+	if err != nil {
+		return err
+	}
+	if options.Ready != nil {
+		options.Ready()
+	}
+
+	pending := make(map[string]string)
+	var lastChange time.Time
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := snapshotLayers(cfg)
+		if err != nil {
+			report(Change{Err: err})
+			continue
+		}
+		if diffSnapshots(previous, current, pending) {
+			lastChange = time.Now()
+		}
+		previous = current
+
+		if len(pending) == 0 || time.Since(lastChange) < debounce {
+			continue
+		}
+
+		change := Change{}
+		files := make([]string, 0, len(pending))
+		for path, kind := range pending {
+			switch kind {
+			case "added":
+				change.Added = append(change.Added, path)
+			case "modified":
+				change.Modified = append(change.Modified, path)
+			case "removed":
+				change.Removed = append(change.Removed, path)
+			}
+			if kind != "removed" {
+				files = append(files, path)
+			}
+		}
+		sort.Strings(change.Added)
+		sort.Strings(change.Modified)
+		sort.Strings(change.Removed)
+		pending = make(map[string]string)
+
+		change.Result, change.Err = Run(ctx, cfg, schema, db, Options{Files: files})
+		report(change)
+	}
+}
+
+func snapshotLayers(cfg *config.ProjectConfig) (map[string]fileState, error) {
+	snapshot := make(map[string]fileState)
+	for _, layer := range cfg.Layers {
+		files, err := walkMarkdownFiles(layer, cfg.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("walking files for layer %s: %w", layer.Name, err)
+		}
+		for _, path := range files {
+			info, err := os.Stat(path)
+			if err != nil {
+				// Removed between the walk and the stat; the next poll sees it.
+				continue
+			}
+			snapshot[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return snapshot, nil
+}
+
+// diffSnapshots records differences between two snapshots in pending, keyed by
+// path. A file added and then modified before ingestion stays "added", and one
+// added and removed again is dropped. It reports whether anything changed.
+func diffSnapshots(previous, current map[string]fileState, pending map[string]string) bool {
+	changed := false
+	for path, state := range current {
+		old, ok := previous[path]
+		switch {
+		case !ok:
+			if pending[path] == "removed" {
+				pending[path] = "modified"
+			} else {
+				pending[path] = "added"
+			}
+			changed = true
+		case !old.modTime.Equal(state.modTime) || old.size != state.size:
+			if pending[path] != "added" {
+				pending[path] = "modified"
+			}
+			changed = true
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; ok {
+			continue
+		}
+		if pending[path] == "added" {
+			delete(pending, path)
+		} else {
+			pending[path] = "removed"
+		}
+		changed = true
+	}
+	return changed
+}
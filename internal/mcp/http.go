@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// shutdownTimeout bounds how long RunHTTP waits for handlers to return before
+// closing their connections.
+const shutdownTimeout = 5 * time.Second
+
+// HTTPHandler serves the streamable HTTP transport. Every session shares the
+// server and its store. When tokens is non-empty, each request must carry one
+// of them as a bearer token.
+func (s *Server) HTTPHandler(tokens []string) http.Handler {
+	handler := http.Handler(sdk.NewStreamableHTTPHandler(func(*http.Request) *sdk.Server {
+		return s.mcp
+	}, nil))
+	if len(tokens) == 0 {
+		return handler
+	}
+	return auth.RequireBearerToken(staticTokens(tokens), nil)(handler)
+}
+
+// RunHTTP serves HTTPHandler on listener until ctx is cancelled, then shuts
+// down gracefully.
+func (s *Server) RunHTTP(ctx context.Context, listener net.Listener, tokens []string) error {
+	// Session event streams never go idle, so shutting down cancels every
+	// request context to end them instead of waiting out the timeout.
+	base, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+	srv := &http.Server{
+		Handler:     s.HTTPHandler(tokens),
+		BaseContext: func(net.Listener) context.Context { return base },
+	}
+	srv.RegisterOnShutdown(cancelBase)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		srv.Close()
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// staticTokens verifies bearer tokens against a fixed list. Tokens carry no
+// expiry of their own, so each verification is valid for the request only.
+func staticTokens(tokens []string) auth.TokenVerifier {
+	return func(ctx context.Context, token string, req *http.Request) (*auth.TokenInfo, error) {
+		for _, candidate := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(candidate)) == 1 {
+				return &auth.TokenInfo{Expiration: time.Now().Add(time.Minute)}, nil
+			}
+		}
+		return nil, auth.ErrInvalidToken
+	}
+}
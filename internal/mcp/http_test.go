@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/store"
+)
+
+// bearerTransport adds an Authorization header to every request.
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func connectHTTP(ctx context.Context, endpoint, token string) (*sdk.ClientSession, error) {
+	client := sdk.NewClient(&sdk.Implementation{Name: "test", Version: "test"}, nil)
+	transport := &sdk.StreamableClientTransport{
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Transport: bearerTransport{token: token}},
+		MaxRetries: -1,
+	}
+	return client.Connect(ctx, transport, nil)
+}
+
+func TestHTTPHandler_RequiresBearerToken(t *testing.T) {
+	storeMock := &mockStore{
+		listResult: []store.EntitySummary{{Name: "Westport", EntityType: "settlement", Layer: "setting", Tags: []string{}}},
+	}
+	server := NewServer(&config.Schema{Version: 1}, storeMock, "test")
+	httpServer := httptest.NewServer(server.HTTPHandler([]string{"s3cret"}))
+	defer httpServer.Close()
+
+	resp, err := http.Post(httpServer.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without a token, got %d", resp.StatusCode)
+	}
+
+	ctx := context.Background()
+	if session, err := connectHTTP(ctx, httpServer.URL, "wrong"); err == nil {
+		session.Close()
+		t.Fatalf("expected connecting with a wrong token to fail")
+	}
+
+	var sessions []*sdk.ClientSession
+	for i := 0; i < 2; i++ {
+		session, err := connectHTTP(ctx, httpServer.URL, "s3cret")
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		defer session.Close()
+		sessions = append(sessions, session)
+	}
+	for _, session := range sessions {
+		result, err := session.CallTool(ctx, &sdk.CallToolParams{Name: "list_entities", Arguments: map[string]any{}})
+		if err != nil {
+			t.Fatalf("call tool: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("tool error: %#v", result.Content)
+		}
+		text, ok := result.Content[0].(*sdk.TextContent)
+		if !ok || !strings.Contains(text.Text, "Westport") {
+			t.Fatalf("unexpected tool result: %#v", result.Content)
+		}
+	}
+}
+
+func TestRunHTTP_StopsOnCancel(t *testing.T) {
+	server := NewServer(&config.Schema{Version: 1}, &mockStore{}, "test")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.RunHTTP(ctx, listener, nil)
+	}()
+
+	session, err := connectHTTP(context.Background(), "http://"+listener.Addr().String(), "")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(shutdownTimeout + 5*time.Second):
+		t.Fatalf("server did not shut down")
+	}
+}
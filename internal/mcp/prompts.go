@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"lorecraft/internal/store"
+)
+
+//go:embed prompts/*.md
+var defaultPrompts embed.FS
+
+// PromptData is what prompt templates are executed with. Which fields are set
+// depends on the prompt: timelines fill Events, entity prompts fill Entity,
+// Relationships and, when a campaign layer is given, State.
+type PromptData struct {
+	Layer         string
+	Session       int
+	Entity        *store.Entity
+	Relationships []store.Relationship
+	State         *store.CurrentState
+	Events        []store.Event
+}
+
+type promptSpec struct {
+	prompt *sdk.Prompt
+	gather func(ctx context.Context, s *Server, args map[string]string) (PromptData, error)
+}
+
+var promptSpecs = []promptSpec{
+	{
+		prompt: &sdk.Prompt{
+			Name:        "prep_session",
+			Title:       "Prep next session",
+			Description: "Plan the next session of a campaign from its timeline",
+			Arguments: []*sdk.PromptArgument{
+				{Name: "layer", Description: "campaign layer", Required: true},
+				{Name: "session", Description: "session number to prepare; defaults to the one after the latest event"},
+			},
+		},
+		gather: gatherPrepSession,
+	},
+	{
+		prompt: &sdk.Prompt{
+			Name:        "recap_session",
+			Title:       "Recap a session",
+			Description: "Write a player-facing recap of one session",
+			Arguments: []*sdk.PromptArgument{
+				{Name: "layer", Description: "campaign layer", Required: true},
+				{Name: "session", Description: "session number", Required: true},
+			},
+		},
+		gather: gatherRecapSession,
+	},
+	{
+		prompt: &sdk.Prompt{
+			Name:        "stat_npc",
+			Title:       "Stat up an NPC",
+			Description: "Give an entity game statistics consistent with its properties and relationships",
+			Arguments: []*sdk.PromptArgument{
+				{Name: "name", Description: "entity name", Required: true},
+				{Name: "layer", Description: "optional campaign layer whose events apply"},
+			},
+		},
+		gather: gatherStatNPC,
+	},
+}
+
+var promptFuncs = template.FuncMap{
+	"timeline":      formatTimeline,
+	"properties":    formatProperties,
+	"relationships": formatRelationships,
+	"join":          strings.Join,
+}
+
+func (s *Server) registerPrompts() {
+	s.prompts = make(map[string]*template.Template, len(promptSpecs))
+	for _, spec := range promptSpecs {
+		text, err := defaultPrompts.ReadFile("prompts/" + spec.prompt.Name + ".md")
+		if err != nil {
+			panic(fmt.Sprintf("missing default prompt %s: %v", spec.prompt.Name, err))
+		}
+		s.prompts[spec.prompt.Name] = template.Must(template.New(spec.prompt.Name).Funcs(promptFuncs).Parse(string(text)))
+		s.mcp.AddPrompt(spec.prompt, s.promptHandler(spec))
+	}
+}
+
+// LoadPromptTemplates replaces built-in prompt templates with <name>.md files
+// from dir. A missing directory leaves the defaults in place; a file that
+// names no prompt or fails to parse is an error.
+func (s *Server) LoadPromptTemplates(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading prompts: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".md")
+		if _, ok := s.prompts[name]; !ok {
+			return fmt.Errorf("prompt template %s: no prompt named %s", entry.Name(), name)
+		}
+		path := filepath.Join(dir, entry.Name())
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		tmpl, err := template.New(name).Funcs(promptFuncs).Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		s.prompts[name] = tmpl
+	}
+	return nil
+}
+
+func (s *Server) promptHandler(spec promptSpec) sdk.PromptHandler {
+	return func(ctx context.Context, req *sdk.GetPromptRequest) (*sdk.GetPromptResult, error) {
+		args := req.Params.Arguments
+		for _, arg := range spec.prompt.Arguments {
+			if arg.Required && strings.TrimSpace(args[arg.Name]) == "" {
+				return nil, fmt.Errorf("%s is required", arg.Name)
+			}
+		}
+		data, err := spec.gather(ctx, s, args)
+		if err != nil {
+			return nil, err
+		}
+		var b strings.Builder
+		if err := s.prompts[spec.prompt.Name].Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("rendering prompt %s: %w", spec.prompt.Name, err)
+		}
+		return &sdk.GetPromptResult{
+			Description: spec.prompt.Description,
+			Messages: []*sdk.PromptMessage{{
+				Role:    "user",
+				Content: &sdk.TextContent{Text: b.String()},
+			}},
+		}, nil
+	}
+}
+
+func gatherPrepSession(ctx context.Context, s *Server, args map[string]string) (PromptData, error) {
+	data := PromptData{Layer: args["layer"]}
+	events, err := s.db.GetTimeline(ctx, data.Layer, "", 0, 0)
+	if err != nil {
+		return PromptData{}, err
+	}
+	data.Events = events
+	if args["session"] != "" {
+		data.Session, err = parseSession(args["session"])
+		return data, err
+	}
+	for _, event := range events {
+		data.Session = max(data.Session, event.Session)
+	}
+	data.Session++
+	return data, nil
+}
+
+func gatherRecapSession(ctx context.Context, s *Server, args map[string]string) (PromptData, error) {
+	session, err := parseSession(args["session"])
+	if err != nil {
+		return PromptData{}, err
+	}
+	events, err := s.db.GetTimeline(ctx, args["layer"], "", session, session)
+	if err != nil {
+		return PromptData{}, err
+	}
+	return PromptData{Layer: args["layer"], Session: session, Events: events}, nil
+}
+
+func gatherStatNPC(ctx context.Context, s *Server, args map[string]string) (PromptData, error) {
+	data := PromptData{Layer: args["layer"]}
+	entity, err := s.db.GetEntity(ctx, args["name"], "")
+	if err != nil {
+		return PromptData{}, err
+	}
+	if entity == nil {
+		return PromptData{}, fmt.Errorf("entity not found")
+	}
+	data.Entity = entity
+	rels, err := s.db.GetRelationships(ctx, entity.Name, "", "both", 1)
+	if err != nil {
+		return PromptData{}, err
+	}
+	data.Relationships = dedupeRelationships(rels)
+	if data.Layer != "" {
+		data.State, err = s.db.GetCurrentState(ctx, entity.Name, data.Layer)
+		if err != nil {
+			return PromptData{}, err
+		}
+	}
+	return data, nil
+}
+
+func parseSession(value string) (int, error) {
+	session, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || session < 1 {
+		return 0, fmt.Errorf("session must be a positive number, got %q", value)
+	}
+	return session, nil
+}
+
+// formatTimeline renders events as a markdown list, one line per event with
+// its consequences nested below.
+func formatTimeline(events []store.Event) string {
+	if len(events) == 0 {
+		return "(no events recorded)\n"
+	}
+	var b strings.Builder
+	for _, event := range events {
+		fmt.Fprintf(&b, "- Session %d", event.Session)
+		if event.DateInWorld != "" {
+			fmt.Fprintf(&b, " (%s)", event.DateInWorld)
+		}
+		fmt.Fprintf(&b, ": %s", event.Name)
+		if len(event.Location) > 0 {
+			fmt.Fprintf(&b, " at %s", strings.Join(event.Location, ", "))
+		}
+		if len(event.Participants) > 0 {
+			fmt.Fprintf(&b, "; participants: %s", strings.Join(event.Participants, ", "))
+		}
+		b.WriteString("\n")
+		for _, c := range event.Consequences {
+			if c.Add != nil {
+				fmt.Fprintf(&b, "  - %s: %s gains %v\n", c.Entity, c.Property, c.Add)
+			} else {
+				fmt.Fprintf(&b, "  - %s: %s becomes %v\n", c.Entity, c.Property, c.Value)
+			}
+		}
+	}
+	return b.String()
+}
+
+func formatProperties(properties map[string]any) string {
+	if len(properties) == 0 {
+		return "(none)\n"
+	}
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "- %s: %v\n", key, properties[key])
+	}
+	return b.String()
+}
+
+func formatRelationships(rels []store.Relationship) string {
+	if len(rels) == 0 {
+		return "(none)\n"
+	}
+	var b strings.Builder
+	for _, rel := range rels {
+		fmt.Fprintf(&b, "- %s -[%s]-> %s (%s)\n", rel.From.Name, rel.Type, rel.To.Name, rel.To.EntityType)
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/store"
+)
+
+func promptText(t *testing.T, session *sdk.ClientSession, name string, args map[string]string) string {
+	t.Helper()
+	result, err := session.GetPrompt(context.Background(), &sdk.GetPromptParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("get prompt %s: %v", name, err)
+	}
+	text, ok := result.Messages[0].Content.(*sdk.TextContent)
+	if !ok {
+		t.Fatalf("unexpected prompt content: %#v", result.Messages[0].Content)
+	}
+	return text.Text
+}
+
+func TestPrompts_FillFromStore(t *testing.T) {
+	storeMock := &mockStore{
+		timelineResult: []store.Event{
+			{Name: "Storm Surge", Layer: "campaign", Session: 2, DateInWorld: "Spring 1201", Location: []string{"Westport"}, Participants: []string{"Mira Voss"},
+				Consequences: []store.Consequence{{Entity: "Westport", Property: "status", Value: "damaged"}}},
+		},
+		entityResult: &store.Entity{
+			Name:       "Mira Voss",
+			EntityType: "npc",
+			Layer:      "setting",
+			Properties: map[string]any{"status": "alive"},
+			Body:       "Knows every reef.",
+		},
+		relationshipsResult: []store.Relationship{{
+			From: store.EntityRef{Name: "Mira Voss"},
+			To:   store.EntityRef{Name: "Westport", EntityType: "settlement"},
+			Type: "LOCATED_IN",
+		}},
+		currentStateResult: &store.CurrentState{CurrentProperties: map[string]any{"status": "wounded"}},
+	}
+	session := connectInMemory(t, NewServer(&config.Schema{Version: 1}, storeMock, "test"))
+
+	text := promptText(t, session, "prep_session", map[string]string{"layer": "campaign"})
+	if !strings.HasPrefix(text, "Help me prepare session 3 of the campaign campaign.") {
+		t.Fatalf("expected next session to be derived from the timeline:\n%s", text)
+	}
+	if !strings.Contains(text, "- Session 2 (Spring 1201): Storm Surge at Westport; participants: Mira Voss\n  - Westport: status becomes damaged\n") {
+		t.Fatalf("expected timeline in prompt:\n%s", text)
+	}
+
+	promptText(t, session, "recap_session", map[string]string{"layer": "campaign", "session": "2"})
+	if storeMock.lastTimelineFrom != 2 || storeMock.lastTimelineTo != 2 {
+		t.Fatalf("expected recap to read one session, got %d-%d", storeMock.lastTimelineFrom, storeMock.lastTimelineTo)
+	}
+
+	text = promptText(t, session, "stat_npc", map[string]string{"name": "Mira Voss", "layer": "campaign"})
+	for _, want := range []string{"- status: alive\n", "Current state in the campaign campaign:\n\n- status: wounded\n", "- Mira Voss -[LOCATED_IN]-> Westport (settlement)\n", "Description:\n\nKnows every reef.\n"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "GM notes") {
+		t.Fatalf("expected no GM notes section without secrets:\n%s", text)
+	}
+
+	if _, err := session.GetPrompt(context.Background(), &sdk.GetPromptParams{Name: "recap_session", Arguments: map[string]string{"layer": "campaign", "session": "last"}}); err == nil {
+		t.Fatalf("expected invalid session to be rejected")
+	}
+	if _, err := session.GetPrompt(context.Background(), &sdk.GetPromptParams{Name: "stat_npc", Arguments: map[string]string{}}); err == nil {
+		t.Fatalf("expected missing name to be rejected")
+	}
+}
+
+func TestLoadPromptTemplates(t *testing.T) {
+	storeMock := &mockStore{}
+	server := NewServer(&config.Schema{Version: 1}, storeMock, "test")
+
+	if err := server.LoadPromptTemplates(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Fatalf("expected missing directory to keep defaults: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "recap_session.md"), []byte("Recap {{.Layer}} session {{.Session}}: {{timeline .Events}}"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := server.LoadPromptTemplates(dir); err != nil {
+		t.Fatalf("load prompts: %v", err)
+	}
+	session := connectInMemory(t, server)
+	text := promptText(t, session, "recap_session", map[string]string{"layer": "campaign", "session": "4"})
+	if text != "Recap campaign session 4: (no events recorded)\n" {
+		t.Fatalf("expected overridden template, got %q", text)
+	}
+
+	bad := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bad, "unknown.md"), []byte("hi"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := server.LoadPromptTemplates(bad); err == nil {
+		t.Fatalf("expected unknown prompt template to be rejected")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "stat_npc.md"), []byte("{{.Entity"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := server.LoadPromptTemplates(dir); err == nil {
+		t.Fatalf("expected invalid template to be rejected")
+	}
+}
@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
+
+	"lorecraft/internal/store"
+)
+
+const (
+	entityURIPrefix = "lore://entity/"
+	typeURIPrefix   = "lore://type/"
+	markdownMIME    = "text/markdown"
+
+	// resourcePageSize is how many entities one resources/list page holds.
+	resourcePageSize = 100
+)
+
+// registerResources exposes entities as resources so clients can attach them
+// to a conversation. Templates cover reads; resources/list is answered from
+// the store because entities change with every ingest, so the server has no
+// resources registered with AddResource.
+func (s *Server) registerResources() {
+	s.mcp.AddResourceTemplate(&sdk.ResourceTemplate{
+		Name:        "entity",
+		Title:       "Entity",
+		URITemplate: entityURIPrefix + "{layer}/{name}",
+		Description: "An entity as markdown with its frontmatter",
+		MIMEType:    markdownMIME,
+	}, s.readEntityResource)
+
+	s.mcp.AddResourceTemplate(&sdk.ResourceTemplate{
+		Name:        "type",
+		Title:       "Entity type",
+		URITemplate: typeURIPrefix + "{type}",
+		Description: "Every entity of one type as a markdown list",
+		MIMEType:    markdownMIME,
+	}, s.readTypeResource)
+
+	s.mcp.AddReceivingMiddleware(func(next sdk.MethodHandler) sdk.MethodHandler {
+		return func(ctx context.Context, method string, req sdk.Request) (sdk.Result, error) {
+			if method != "resources/list" {
+				return next(ctx, method, req)
+			}
+			return s.listResources(ctx, req.(*sdk.ListResourcesRequest).Params)
+		}
+	})
+}
+
+// listResources returns one page of entity resources ordered by URI. The
+// cursor encodes the last URI of the previous page, so a page stays correct
+// when entities are added or removed between requests.
+func (s *Server) listResources(ctx context.Context, params *sdk.ListResourcesParams) (*sdk.ListResourcesResult, error) {
+	after := ""
+	if params != nil && params.Cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(params.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor %q", params.Cursor)
+		}
+		after = string(decoded)
+	}
+
+	entities, err := s.db.ListEntities(ctx, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]*sdk.Resource, 0, len(entities))
+	for _, entity := range entities {
+		uri := entityURI(entity.Layer, entity.Name)
+		if uri <= after {
+			continue
+		}
+		resources = append(resources, &sdk.Resource{
+			URI:         uri,
+			Name:        entity.Name,
+			Description: fmt.Sprintf("%s in %s", entity.EntityType, entity.Layer),
+			MIMEType:    markdownMIME,
+		})
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].URI < resources[j].URI })
+
+	result := &sdk.ListResourcesResult{Resources: resources}
+	if len(resources) > resourcePageSize {
+		result.Resources = resources[:resourcePageSize]
+		result.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(resources[resourcePageSize-1].URI))
+	}
+	return result, nil
+}
+
+func (s *Server) readEntityResource(ctx context.Context, req *sdk.ReadResourceRequest) (*sdk.ReadResourceResult, error) {
+	uri := req.Params.URI
+	layer, name, ok := strings.Cut(strings.TrimPrefix(uri, entityURIPrefix), "/")
+	if !ok {
+		return nil, sdk.ResourceNotFoundError(uri)
+	}
+	layer, err := url.PathUnescape(layer)
+	if err != nil {
+		return nil, sdk.ResourceNotFoundError(uri)
+	}
+	name, err = url.PathUnescape(name)
+	if err != nil {
+		return nil, sdk.ResourceNotFoundError(uri)
+	}
+
+	entity, err := s.db.GetEntityInLayer(ctx, name, layer)
+	if err != nil {
+		return nil, err
+	}
+	if entity == nil {
+		return nil, sdk.ResourceNotFoundError(uri)
+	}
+	text, err := entityMarkdown(entity)
+	if err != nil {
+		return nil, err
+	}
+	return &sdk.ReadResourceResult{Contents: []*sdk.ResourceContents{{
+		URI:      uri,
+		MIMEType: markdownMIME,
+		Text:     text,
+	}}}, nil
+}
+
+func (s *Server) readTypeResource(ctx context.Context, req *sdk.ReadResourceRequest) (*sdk.ReadResourceResult, error) {
+	uri := req.Params.URI
+	entityType, err := url.PathUnescape(strings.TrimPrefix(uri, typeURIPrefix))
+	if err != nil {
+		return nil, sdk.ResourceNotFoundError(uri)
+	}
+	typeDef, ok := s.schema.EntityTypeByName(entityType)
+	if !ok {
+		return nil, sdk.ResourceNotFoundError(uri)
+	}
+
+	entities, err := s.db.ListEntities(ctx, typeDef.Name, "", "")
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", typeDef.Name)
+	for _, entity := range entities {
+		fmt.Fprintf(&b, "- [%s](%s) (%s)\n", entity.Name, entityURI(entity.Layer, entity.Name), entity.Layer)
+	}
+	return &sdk.ReadResourceResult{Contents: []*sdk.ResourceContents{{
+		URI:      uri,
+		MIMEType: markdownMIME,
+		Text:     b.String(),
+	}}}, nil
+}
+
+func entityURI(layer, name string) string {
+	return entityURIPrefix + url.PathEscape(layer) + "/" + url.PathEscape(name)
+}
+
+// entityMarkdown renders an entity the way its source file reads: frontmatter
+// with title, type, properties and tags, then the body. Secrets come back as a
+// gm block only when the store returned them, which it does not for
+// restricted audiences.
+func entityMarkdown(entity *store.Entity) (string, error) {
+	front := &yaml.Node{Kind: yaml.MappingNode}
+	add := func(key string, value any) error {
+		node := &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return fmt.Errorf("encoding %s: %w", key, err)
+		}
+		if node.Kind == yaml.SequenceNode {
+			node.Style = yaml.FlowStyle
+		}
+		front.Content = append(front.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, node)
+		return nil
+	}
+	if err := add("title", entity.Name); err != nil {
+		return "", err
+	}
+	if err := add("type", entity.EntityType); err != nil {
+		return "", err
+	}
+	keys := make([]string, 0, len(entity.Properties))
+	for key := range entity.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := add(key, entity.Properties[key]); err != nil {
+			return "", err
+		}
+	}
+	if len(entity.Tags) > 0 {
+		if err := add("tags", entity.Tags); err != nil {
+			return "", err
+		}
+	}
+	if len(entity.Aliases) > 0 {
+		if err := add("aliases", entity.Aliases); err != nil {
+			return "", err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(front); err != nil {
+		return "", fmt.Errorf("encoding frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("encoding frontmatter: %w", err)
+	}
+	buf.WriteString("---\n")
+	if body := strings.TrimSpace(entity.Body); body != "" {
+		buf.WriteString("\n" + body + "\n")
+	}
+	if secret := strings.TrimSpace(entity.Secret); secret != "" {
+		buf.WriteString("\n```gm\n" + secret + "\n```\n")
+	}
+	return buf.String(), nil
+}
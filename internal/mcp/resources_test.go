@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+	"lorecraft/internal/store"
+	"lorecraft/internal/store/sqlite"
+)
+
+func connectInMemory(t *testing.T, server *Server) *sdk.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := sdk.NewInMemoryTransports()
+	serverSession, err := server.mcp.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+	client := sdk.NewClient(&sdk.Implementation{Name: "test", Version: "test"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestResources_ListAndRead(t *testing.T) {
+	storeMock := &mockStore{
+		listResult: []store.EntitySummary{{Name: "Harbor Master", EntityType: "npc", Layer: "setting", Tags: []string{}}},
+		entityResult: &store.Entity{
+			Name:       "Harbor Master",
+			EntityType: "npc",
+			Layer:      "setting",
+			Tags:       []string{"westport"},
+			Properties: map[string]any{"role": "Harbor master", "status": "alive"},
+			Body:       "Keeps the tide tables.",
+			Secret:     "Takes bribes.",
+		},
+	}
+	schema, err := config.LoadSchema(filepath.Join("..", "..", "example", "schema.yaml"))
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+	session := connectInMemory(t, NewServer(schema, storeMock, "test"))
+	ctx := context.Background()
+
+	listed, err := session.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("list resources: %v", err)
+	}
+	if len(listed.Resources) != 1 || listed.Resources[0].URI != "lore://entity/setting/Harbor%20Master" {
+		t.Fatalf("unexpected resources: %#v", listed.Resources)
+	}
+
+	read, err := session.ReadResource(ctx, &sdk.ReadResourceParams{URI: listed.Resources[0].URI})
+	if err != nil {
+		t.Fatalf("read entity: %v", err)
+	}
+	expected := "---\ntitle: Harbor Master\ntype: npc\nrole: Harbor master\nstatus: alive\ntags: [westport]\n---\n\nKeeps the tide tables.\n\n```gm\nTakes bribes.\n```\n"
+	if len(read.Contents) != 1 || read.Contents[0].Text != expected {
+		t.Fatalf("unexpected entity resource: %#v", read.Contents)
+	}
+	if storeMock.lastGetEntityName != "Harbor Master" {
+		t.Fatalf("expected unescaped name, got %q", storeMock.lastGetEntityName)
+	}
+
+	if _, err := session.ReadResource(ctx, &sdk.ReadResourceParams{URI: "lore://entity/campaign/Harbor%20Master"}); err == nil {
+		t.Fatalf("expected entity in another layer to be missing")
+	}
+
+	read, err = session.ReadResource(ctx, &sdk.ReadResourceParams{URI: "lore://type/NPC"})
+	if err != nil {
+		t.Fatalf("read type: %v", err)
+	}
+	if !strings.Contains(read.Contents[0].Text, "- [Harbor Master](lore://entity/setting/Harbor%20Master) (setting)") {
+		t.Fatalf("unexpected type resource: %s", read.Contents[0].Text)
+	}
+	if storeMock.lastListType != "npc" {
+		t.Fatalf("expected canonical type name, got %q", storeMock.lastListType)
+	}
+
+	if _, err := session.ReadResource(ctx, &sdk.ReadResourceParams{URI: "lore://type/dragon"}); err == nil {
+		t.Fatalf("expected unknown type to be missing")
+	}
+}
+
+func TestResources_ListPages(t *testing.T) {
+	storeMock := &mockStore{}
+	for i := range resourcePageSize + 20 {
+		storeMock.listResult = append(storeMock.listResult, store.EntitySummary{Name: fmt.Sprintf("Sailor %03d", i), EntityType: "npc", Layer: "setting"})
+	}
+	schema, err := config.LoadSchema(filepath.Join("..", "..", "example", "schema.yaml"))
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+	session := connectInMemory(t, NewServer(schema, storeMock, "test"))
+	ctx := context.Background()
+
+	first, err := session.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("list resources: %v", err)
+	}
+	if len(first.Resources) != resourcePageSize || first.NextCursor == "" {
+		t.Fatalf("expected a full first page with a cursor, got %d resources and cursor %q", len(first.Resources), first.NextCursor)
+	}
+	second, err := session.ListResources(ctx, &sdk.ListResourcesParams{Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("list resources: %v", err)
+	}
+	if len(second.Resources) != 20 || second.NextCursor != "" {
+		t.Fatalf("expected the remaining resources without a cursor, got %d resources and cursor %q", len(second.Resources), second.NextCursor)
+	}
+	if second.Resources[0].Name != fmt.Sprintf("Sailor %03d", resourcePageSize) {
+		t.Fatalf("expected the second page to continue after the first, got %s", second.Resources[0].Name)
+	}
+
+	if _, err := session.ListResources(ctx, &sdk.ListResourcesParams{Cursor: "not a cursor"}); err == nil {
+		t.Fatalf("expected an invalid cursor to be rejected")
+	}
+}
+
+func TestResources_ReadOverlaidEntity(t *testing.T) {
+	ctx := context.Background()
+	settingDir := t.TempDir()
+	campaignDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers: []config.Layer{
+			{Name: "setting", Paths: []string{settingDir}, Canonical: true},
+			{Name: "campaign", Paths: []string{campaignDir}, DependsOn: []string{"setting"}},
+		},
+	}
+	schema, err := config.LoadSchema(filepath.Join("..", "..", "example", "schema.yaml"))
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+	client, err := sqlite.New(ctx, cfg.Database.DSN, cfg)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { client.Close(ctx) })
+
+	if err := os.WriteFile(filepath.Join(settingDir, "westport.md"), []byte("---\ntitle: Westport\ntype: settlement\n---\n\nA harbour town.\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(campaignDir, "westport.md"), []byte("---\ntitle: Westport\ntype: settlement\n---\n\nA flooded ruin.\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	session := connectInMemory(t, NewServer(schema, client, "test"))
+	for layer, body := range map[string]string{"setting": "A harbour town.", "campaign": "A flooded ruin."} {
+		read, err := session.ReadResource(ctx, &sdk.ReadResourceParams{URI: "lore://entity/" + layer + "/Westport"})
+		if err != nil {
+			t.Fatalf("read %s entity: %v", layer, err)
+		}
+		if !strings.Contains(read.Contents[0].Text, body) {
+			t.Fatalf("expected the %s overlay, got %s", layer, read.Contents[0].Text)
+		}
+	}
+}
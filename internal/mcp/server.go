@@ -2,6 +2,8 @@ package mcp
 
 import (
 	"context"
+	"sync"
+	"text/template"
 
 	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -13,6 +15,15 @@ type Server struct {
 	schema *config.Schema
 	db     store.Store
 	mcp    *sdk.Server
+
+	// prompts holds the template of each registered prompt, keyed by name.
+	prompts map[string]*template.Template
+
+	// cfg and writer are set by EnableWrites; writeMu serializes writes from
+	// concurrent sessions.
+	cfg     *config.ProjectConfig
+	writer  store.Store
+	writeMu sync.Mutex
 }
 
 func NewServer(schema *config.Schema, db store.Store, version string) *Server {
@@ -25,6 +36,8 @@ func NewServer(schema *config.Schema, db store.Store, version string) *Server {
 		}, nil),
 	}
 	s.registerTools()
+	s.registerResources()
+	s.registerPrompts()
 	return s
 }
 
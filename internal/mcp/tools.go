@@ -2,7 +2,9 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -13,7 +15,7 @@ import (
 type SearchLoreInput struct {
 	Query string `json:"query" jsonschema:"search terms"`
 	Layer string `json:"layer,omitempty" jsonschema:"restrict to a specific layer"`
-	Type  string `json:"type,omitempty" jsonschema:"restrict to a specific entity type"`
+	Type  string `json:"type,omitempty" jsonschema:"restrict to an entity type and its subtypes"`
 }
 
 type GetEntityInput struct {
@@ -23,11 +25,19 @@ type GetEntityInput struct {
 
 type GetRelationshipsInput struct {
 	Name      string `json:"name" jsonschema:"starting entity name"`
-	Type      string `json:"type,omitempty" jsonschema:"relationship type filter"`
+	Type      string `json:"type,omitempty" jsonschema:"relationship type filter; inverse names such as HAS_MEMBER are accepted"`
 	Depth     int    `json:"depth,omitempty" jsonschema:"maximum traversal depth"`
 	Direction string `json:"direction,omitempty" jsonschema:"outgoing, incoming, or both"`
 }
 
+type FindPathInput struct {
+	From     string   `json:"from" jsonschema:"entity the paths start at"`
+	To       string   `json:"to" jsonschema:"entity the paths end at"`
+	MaxDepth int      `json:"max_depth,omitempty" jsonschema:"maximum number of hops (1-5, default 3)"`
+	Types    []string `json:"types,omitempty" jsonschema:"relationship types a path may use; inverse names such as HAS_MEMBER are accepted"`
+	All      bool     `json:"all,omitempty" jsonschema:"return every path within max_depth instead of only the shortest"`
+}
+
 type ListEntitiesInput struct {
 	Type  string `json:"type,omitempty" jsonschema:"entity type filter"`
 	Layer string `json:"layer,omitempty" jsonschema:"layer filter"`
@@ -57,14 +67,18 @@ type CheckConsistencyInput struct {
 }
 
 type EntityOutput struct {
-	Name       string         `json:"name"`
-	EntityType string         `json:"type"`
-	Layer      string         `json:"layer"`
-	SourceFile string         `json:"source_file"`
-	SourceHash string         `json:"source_hash"`
-	Tags       []string       `json:"tags"`
-	Properties map[string]any `json:"properties"`
-	Body       string         `json:"body,omitempty"`
+	Name         string         `json:"name"`
+	EntityType   string         `json:"type"`
+	Layer        string         `json:"layer"`
+	SourceFile   string         `json:"source_file"`
+	SourceAnchor string         `json:"source_anchor,omitempty" jsonschema:"heading anchor of the section defining the entity in a file with several"`
+	SourceHash   string         `json:"source_hash"`
+	Tags         []string       `json:"tags"`
+	Aliases      []string       `json:"aliases,omitempty" jsonschema:"other names the entity is known by"`
+	Properties   map[string]any `json:"properties"`
+	Defaulted    []string       `json:"defaulted_properties,omitempty" jsonschema:"properties filled from schema defaults rather than the source file"`
+	Body         string         `json:"body,omitempty"`
+	Secret       string         `json:"secret,omitempty" jsonschema:"GM-only notes, omitted for restricted audiences"`
 }
 
 type EntitySummaryOutput struct {
@@ -109,22 +123,27 @@ type SchemaOutput struct {
 
 type EntityTypeOutput struct {
 	Name          string               `json:"name"`
+	Extends       []string             `json:"extends,omitempty" jsonschema:"parent types whose properties and field mappings this type inherits"`
+	Abstract      bool                 `json:"abstract,omitempty" jsonschema:"abstract types only group their subtypes; no entity has one"`
+	Subtypes      []string             `json:"subtypes,omitempty" jsonschema:"types that extend this one, directly or not, and match it in type filters"`
 	Properties    []PropertyOutput     `json:"properties"`
 	FieldMappings []FieldMappingOutput `json:"field_mappings"`
 }
 
 type PropertyOutput struct {
-	Name     string   `json:"name"`
-	Type     string   `json:"type"`
-	Values   []string `json:"values,omitempty"`
-	Default  string   `json:"default,omitempty"`
-	Required bool     `json:"required,omitempty"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Values        []string `json:"values,omitempty"`
+	Default       string   `json:"default,omitempty"`
+	Required      bool     `json:"required,omitempty"`
+	InheritedFrom string   `json:"inherited_from,omitempty" jsonschema:"the ancestor type that declares an inherited property"`
 }
 
 type FieldMappingOutput struct {
-	Field        string   `json:"field"`
-	Relationship string   `json:"relationship"`
-	TargetType   []string `json:"target_type"`
+	Field         string   `json:"field"`
+	Relationship  string   `json:"relationship"`
+	TargetType    []string `json:"target_type"`
+	InheritedFrom string   `json:"inherited_from,omitempty" jsonschema:"the ancestor type that declares an inherited mapping"`
 }
 
 type RelationshipTypeOutput struct {
@@ -137,6 +156,22 @@ type GetRelationshipsOutput struct {
 	Relationships []RelationshipOutput `json:"relationships"`
 }
 
+type PathHopOutput struct {
+	From      EntityRefOutput `json:"from"`
+	To        EntityRefOutput `json:"to"`
+	Type      string          `json:"type"`
+	Direction string          `json:"direction" jsonschema:"outgoing when the relationship points from 'from' to 'to', incoming when it points back"`
+}
+
+type PathOutput struct {
+	Length int             `json:"length"`
+	Hops   []PathHopOutput `json:"hops"`
+}
+
+type FindPathOutput struct {
+	Paths []PathOutput `json:"paths"`
+}
+
 type ListEntitiesOutput struct {
 	Entities []EntitySummaryOutput `json:"entities"`
 }
@@ -190,6 +225,11 @@ func (s *Server) registerTools() {
 		Description: "Traverse relationships from an entity",
 	}, s.handleGetRelationships)
 
+	sdk.AddTool(s.mcp, &sdk.Tool{
+		Name:        "find_path",
+		Description: "Find how two entities are connected, as ordered hops with relationship types and directions",
+	}, s.handleFindPath)
+
 	sdk.AddTool(s.mcp, &sdk.Tool{
 		Name:        "list_entities",
 		Description: "List entities with optional filters",
@@ -241,7 +281,7 @@ func (s *Server) handleGetEntity(ctx context.Context, req *sdk.CallToolRequest,
 		return nil, EntityOutput{}, err
 	}
 	if entity == nil {
-		return nil, EntityOutput{}, fmt.Errorf("entity not found")
+		return nil, EntityOutput{}, s.entityNotFound(ctx, input.Name)
 	}
 	return nil, entityOutputFromStore(entity), nil
 }
@@ -255,6 +295,9 @@ func (s *Server) handleGetRelationships(ctx context.Context, req *sdk.CallToolRe
 		depth = 1
 	}
 	rels, err := s.db.GetRelationships(ctx, input.Name, input.Type, input.Direction, depth)
+	if errors.Is(err, store.ErrEntityNotFound) {
+		return nil, GetRelationshipsOutput{}, s.entityNotFound(ctx, input.Name)
+	}
 	if err != nil {
 		return nil, GetRelationshipsOutput{}, err
 	}
@@ -266,6 +309,29 @@ func (s *Server) handleGetRelationships(ctx context.Context, req *sdk.CallToolRe
 	return nil, GetRelationshipsOutput{Relationships: output}, nil
 }
 
+func (s *Server) handleFindPath(ctx context.Context, req *sdk.CallToolRequest, input FindPathInput) (*sdk.CallToolResult, FindPathOutput, error) {
+	if input.From == "" || input.To == "" {
+		return nil, FindPathOutput{}, fmt.Errorf("from and to are required")
+	}
+	depth := input.MaxDepth
+	if depth == 0 {
+		depth = 3
+	}
+	paths, err := s.db.FindPaths(ctx, input.From, input.To, depth, input.Types)
+	if err != nil {
+		return nil, FindPathOutput{}, err
+	}
+	if !input.All {
+		paths = store.Shortest(paths)
+	}
+
+	output := make([]PathOutput, 0, len(paths))
+	for _, path := range paths {
+		output = append(output, pathOutputFromStore(path))
+	}
+	return nil, FindPathOutput{Paths: output}, nil
+}
+
 func (s *Server) handleListEntities(ctx context.Context, req *sdk.CallToolRequest, input ListEntitiesInput) (*sdk.CallToolResult, ListEntitiesOutput, error) {
 	items, err := s.db.ListEntities(ctx, input.Type, input.Layer, input.Tag)
 	if err != nil {
@@ -353,18 +419,28 @@ func schemaOutputFromConfig(schema *config.Schema) SchemaOutput {
 	}
 
 	for _, entityType := range schema.EntityTypes {
+		var subtypes []string
+		for _, subtype := range schema.Subtypes(entityType.Name) {
+			if !strings.EqualFold(subtype, entityType.Name) {
+				subtypes = append(subtypes, subtype)
+			}
+		}
 		entityOut := EntityTypeOutput{
 			Name:          entityType.Name,
+			Extends:       entityType.Extends,
+			Abstract:      entityType.Abstract,
+			Subtypes:      subtypes,
 			Properties:    make([]PropertyOutput, 0, len(entityType.Properties)),
 			FieldMappings: make([]FieldMappingOutput, 0, len(entityType.FieldMappings)),
 		}
 		for _, prop := range entityType.Properties {
 			entityOut.Properties = append(entityOut.Properties, PropertyOutput{
-				Name:     prop.Name,
-				Type:     prop.Type,
-				Values:   prop.Values,
-				Default:  prop.Default,
-				Required: prop.Required,
+				Name:          prop.Name,
+				Type:          prop.Type,
+				Values:        prop.Values,
+				Default:       prop.Default,
+				Required:      prop.Required,
+				InheritedFrom: prop.InheritedFrom,
 			})
 		}
 		for _, mapping := range entityType.FieldMappings {
@@ -373,9 +449,10 @@ func schemaOutputFromConfig(schema *config.Schema) SchemaOutput {
 				targetTypes = []string{}
 			}
 			entityOut.FieldMappings = append(entityOut.FieldMappings, FieldMappingOutput{
-				Field:        mapping.Field,
-				Relationship: mapping.Relationship,
-				TargetType:   targetTypes,
+				Field:         mapping.Field,
+				Relationship:  mapping.Relationship,
+				TargetType:    targetTypes,
+				InheritedFrom: mapping.InheritedFrom,
 			})
 		}
 		out.EntityTypes = append(out.EntityTypes, entityOut)
@@ -392,6 +469,19 @@ func schemaOutputFromConfig(schema *config.Schema) SchemaOutput {
 	return out
 }
 
+// entityNotFound reports a failed lookup together with the closest entity
+// names, so a client can retry with one of them rather than guess.
+func (s *Server) entityNotFound(ctx context.Context, name string) error {
+	suggestions, err := s.db.SuggestEntities(ctx, name, store.MaxSuggestions)
+	if err != nil {
+		return err
+	}
+	if len(suggestions) == 0 {
+		return fmt.Errorf("entity not found: %s", name)
+	}
+	return fmt.Errorf("entity not found: %s; did you mean %s?", name, store.FormatSuggestions(suggestions))
+}
+
 func entityOutputFromStore(entity *store.Entity) EntityOutput {
 	if entity == nil {
 		return EntityOutput{}
@@ -401,14 +491,18 @@ func entityOutputFromStore(entity *store.Entity) EntityOutput {
 		properties[key] = value
 	}
 	return EntityOutput{
-		Name:       entity.Name,
-		EntityType: entity.EntityType,
-		Layer:      entity.Layer,
-		SourceFile: entity.SourceFile,
-		SourceHash: entity.SourceHash,
-		Tags:       append([]string{}, entity.Tags...),
-		Properties: properties,
-		Body:       entity.Body,
+		Name:         entity.Name,
+		EntityType:   entity.EntityType,
+		Layer:        entity.Layer,
+		SourceFile:   entity.SourceFile,
+		SourceAnchor: entity.SourceAnchor,
+		SourceHash:   entity.SourceHash,
+		Tags:         append([]string{}, entity.Tags...),
+		Aliases:      append([]string(nil), entity.Aliases...),
+		Properties:   properties,
+		Defaulted:    append([]string(nil), entity.Defaulted...),
+		Body:         entity.Body,
+		Secret:       entity.Secret,
 	}
 }
 
@@ -432,6 +526,19 @@ func searchResultOutputFromStore(result store.SearchResult) SearchResultOutput {
 	}
 }
 
+func pathOutputFromStore(path store.Path) PathOutput {
+	hops := make([]PathHopOutput, 0, len(path.Hops))
+	for _, hop := range path.Hops {
+		hops = append(hops, PathHopOutput{
+			From:      EntityRefOutput{Name: hop.From.Name, EntityType: hop.From.EntityType, Layer: hop.From.Layer},
+			To:        EntityRefOutput{Name: hop.To.Name, EntityType: hop.To.EntityType, Layer: hop.To.Layer},
+			Type:      hop.Type,
+			Direction: hop.Direction,
+		})
+	}
+	return PathOutput{Length: len(hops), Hops: hops}
+}
+
 func relationshipOutputFromStore(rel store.Relationship) RelationshipOutput {
 	return RelationshipOutput{
 		From: EntityRefOutput{
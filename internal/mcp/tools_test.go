@@ -2,6 +2,10 @@ package mcp
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"lorecraft/internal/config"
@@ -17,6 +21,9 @@ type mockStore struct {
 	listErr             error
 	relationshipsResult []store.Relationship
 	relationshipsErr    error
+	pathsResult         []store.Path
+	pathsErr            error
+	suggestionsResult   []store.EntitySuggestion
 	currentStateResult  *store.CurrentState
 	currentStateErr     error
 	timelineResult      []store.Event
@@ -34,6 +41,11 @@ type mockStore struct {
 	lastRelationshipsType  string
 	lastRelationshipsDir   string
 	lastRelationshipsDepth int
+	lastPathsFrom          string
+	lastPathsTo            string
+	lastPathsDepth         int
+	lastPathsTypes         []string
+	lastSuggestName        string
 	lastTimelineLayer      string
 	lastTimelineEntity     string
 	lastTimelineFrom       int
@@ -52,10 +64,20 @@ func (m *mockStore) UpsertRelationship(ctx context.Context, fromName, fromLayer,
 	return nil
 }
 
+func (m *mockStore) InTransaction(ctx context.Context, fn func(store.Store) error) error {
+	return fn(m)
+}
+
+func (m *mockStore) WithVisibility(v *store.Visibility) store.Store { return m }
+
 func (m *mockStore) RemoveStaleNodes(ctx context.Context, layer string, currentSourceFiles []string) (int64, error) {
 	return 0, nil
 }
 
+func (m *mockStore) RemoveOrphanedPlaceholders(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
 func (m *mockStore) GetLayerHashes(ctx context.Context, layer string) (map[string]string, error) {
 	return nil, nil
 }
@@ -64,12 +86,36 @@ func (m *mockStore) FindEntityLayer(ctx context.Context, name string, layers []s
 	return "", nil
 }
 
+func (m *mockStore) GetSchemaState(ctx context.Context) (*store.SchemaState, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SaveSchemaState(ctx context.Context, state store.SchemaState) error {
+	return nil
+}
+
+func (m *mockStore) RenameProperty(ctx context.Context, entityType, from, to string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStore) RenameRelationshipType(ctx context.Context, from, to string) (int64, error) {
+	return 0, nil
+}
+
 func (m *mockStore) GetEntity(ctx context.Context, name, entityType string) (*store.Entity, error) {
 	m.lastGetEntityName = name
 	m.lastGetEntityType = entityType
 	return m.entityResult, m.entityErr
 }
 
+func (m *mockStore) GetEntityInLayer(ctx context.Context, name, layer string) (*store.Entity, error) {
+	m.lastGetEntityName = name
+	if m.entityResult == nil || !strings.EqualFold(m.entityResult.Layer, layer) {
+		return nil, m.entityErr
+	}
+	return m.entityResult, m.entityErr
+}
+
 func (m *mockStore) GetRelationships(ctx context.Context, name, relType, direction string, depth int) ([]store.Relationship, error) {
 	m.lastRelationshipsName = name
 	m.lastRelationshipsType = relType
@@ -78,6 +124,19 @@ func (m *mockStore) GetRelationships(ctx context.Context, name, relType, directi
 	return m.relationshipsResult, m.relationshipsErr
 }
 
+func (m *mockStore) FindPaths(ctx context.Context, from, to string, maxDepth int, relTypes []string) ([]store.Path, error) {
+	m.lastPathsFrom = from
+	m.lastPathsTo = to
+	m.lastPathsDepth = maxDepth
+	m.lastPathsTypes = relTypes
+	return m.pathsResult, m.pathsErr
+}
+
+func (m *mockStore) SuggestEntities(ctx context.Context, name string, limit int) ([]store.EntitySuggestion, error) {
+	m.lastSuggestName = name
+	return m.suggestionsResult, nil
+}
+
 func (m *mockStore) ListEntities(ctx context.Context, entityType, layer, tag string) ([]store.EntitySummary, error) {
 	m.lastListType = entityType
 	m.lastListLayer = layer
@@ -122,6 +181,10 @@ func (m *mockStore) ListCrossLayerViolations(ctx context.Context) ([]store.Entit
 	return nil, nil
 }
 
+func (m *mockStore) ListEdges(ctx context.Context) ([]store.Edge, error) {
+	return nil, nil
+}
+
 func (m *mockStore) RunSQL(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
 	return nil, nil
 }
@@ -135,6 +198,34 @@ func TestGetEntity_NotFound(t *testing.T) {
 	}
 }
 
+func TestGetEntity_NotFoundSuggestsNames(t *testing.T) {
+	storeMock := &mockStore{
+		suggestionsResult: []store.EntitySuggestion{
+			{Name: "Bureau Director Lysa Quent", EntityType: "npc", Layer: "setting", Score: 0.6},
+			{Name: "Selin Hale", EntityType: "npc", Layer: "setting", Alias: "Lys", Score: 0.3},
+		},
+	}
+	server := NewServer(&config.Schema{Version: 1}, storeMock, "test")
+
+	_, _, err := server.handleGetEntity(context.Background(), nil, GetEntityInput{Name: "Lysa Quint"})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	want := "entity not found: Lysa Quint; did you mean Bureau Director Lysa Quent (npc, setting), Selin Hale (npc, setting, alias Lys)?"
+	if err.Error() != want {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if storeMock.lastSuggestName != "Lysa Quint" {
+		t.Fatalf("unexpected suggestion lookup: %q", storeMock.lastSuggestName)
+	}
+
+	storeMock.relationshipsErr = fmt.Errorf("finding start entity: %w: Lysa Quint", store.ErrEntityNotFound)
+	_, _, err = server.handleGetRelationships(context.Background(), nil, GetRelationshipsInput{Name: "Lysa Quint"})
+	if err == nil || err.Error() != want {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestSearchLore(t *testing.T) {
 	storeMock := &mockStore{
 		searchResult: []store.SearchResult{
@@ -197,6 +288,45 @@ func TestGetRelationships(t *testing.T) {
 	}
 }
 
+func TestFindPath(t *testing.T) {
+	a := store.EntityRef{Name: "A", EntityType: "npc", Layer: "setting"}
+	b := store.EntityRef{Name: "B", EntityType: "faction", Layer: "setting"}
+	c := store.EntityRef{Name: "C", EntityType: "npc", Layer: "setting"}
+	storeMock := &mockStore{
+		pathsResult: []store.Path{
+			{Hops: []store.PathHop{{From: a, To: c, Type: "RELATED_TO", Direction: "outgoing"}}},
+			{Hops: []store.PathHop{
+				{From: a, To: b, Type: "MEMBER_OF", Direction: "outgoing"},
+				{From: b, To: c, Type: "MEMBER_OF", Direction: "incoming"},
+			}},
+		},
+	}
+	server := NewServer(&config.Schema{Version: 1}, storeMock, "test")
+
+	_, output, err := server.handleFindPath(context.Background(), nil, FindPathInput{From: "A", To: "C", Types: []string{"MEMBER_OF"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output.Paths) != 1 || output.Paths[0].Length != 1 || output.Paths[0].Hops[0].To.Name != "C" {
+		t.Fatalf("expected only the shortest path, got %+v", output)
+	}
+	if storeMock.lastPathsFrom != "A" || storeMock.lastPathsTo != "C" || storeMock.lastPathsDepth != 3 || len(storeMock.lastPathsTypes) != 1 {
+		t.Fatalf("unexpected path params")
+	}
+
+	_, output, err = server.handleFindPath(context.Background(), nil, FindPathInput{From: "A", To: "C", All: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(output.Paths) != 2 || output.Paths[1].Hops[1].Direction != "incoming" {
+		t.Fatalf("expected every path, got %+v", output)
+	}
+
+	if _, _, err := server.handleFindPath(context.Background(), nil, FindPathInput{From: "A"}); err == nil {
+		t.Fatalf("expected an error without a target")
+	}
+}
+
 func TestGetSchema(t *testing.T) {
 	schema := &config.Schema{
 		Version: 1,
@@ -230,6 +360,37 @@ func TestGetSchema(t *testing.T) {
 	}
 }
 
+func TestGetSchema_Inheritance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	contents := "version: 1\nentity_types:\n" +
+		"  - { name: character, abstract: true, properties: [{ name: status, type: string }] }\n" +
+		"  - { name: npc, extends: character, properties: [{ name: role, type: string }] }\n" +
+		"relationship_types:\n  - name: MEMBER_OF\n"
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	schema, err := config.LoadSchema(path)
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+	server := NewServer(schema, &mockStore{}, "test")
+
+	_, output, err := server.handleGetSchema(context.Background(), nil, GetSchemaInput{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	character, npc := output.EntityTypes[0], output.EntityTypes[1]
+	if !character.Abstract || len(character.Subtypes) != 1 || character.Subtypes[0] != "npc" {
+		t.Fatalf("unexpected abstract type output: %+v", character)
+	}
+	if len(npc.Extends) != 1 || len(npc.Properties) != 2 {
+		t.Fatalf("expected declared and inherited properties, got %+v", npc)
+	}
+	if npc.Properties[0].Name != "status" || npc.Properties[0].InheritedFrom != "character" || npc.Properties[1].InheritedFrom != "" {
+		t.Fatalf("unexpected property origins: %+v", npc.Properties)
+	}
+}
+
 func TestGetCurrentState(t *testing.T) {
 	storeMock := &mockStore{
 		currentStateResult: &store.CurrentState{
@@ -305,3 +466,13 @@ func TestCheckConsistency(t *testing.T) {
 		t.Fatalf("unexpected events output: %+v", output.Events)
 	}
 }
+
+func TestUpdateEntity_NotFound(t *testing.T) {
+	server := NewServer(&config.Schema{Version: 1}, &mockStore{}, "test")
+	server.EnableWrites(&config.ProjectConfig{Layers: []config.Layer{{Name: "setting", Paths: []string{"lore"}, Drafts: "lore/drafts"}}}, &mockStore{})
+
+	_, _, err := server.handleUpdateEntity(context.Background(), nil, UpdateEntityInput{Name: "Missing"})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/drafts"
+	"lorecraft/internal/store"
+)
+
+type CreateEntityInput struct {
+	Name       string         `json:"name" jsonschema:"entity name, used as the title"`
+	Type       string         `json:"type" jsonschema:"entity type from the schema"`
+	Layer      string         `json:"layer" jsonschema:"layer whose drafts path receives the file"`
+	Properties map[string]any `json:"properties,omitempty" jsonschema:"schema properties and field mapping fields such as location, plus related and tags"`
+	Body       string         `json:"body,omitempty" jsonschema:"markdown body text"`
+}
+
+type UpdateEntityInput struct {
+	Name       string         `json:"name" jsonschema:"entity name"`
+	Type       string         `json:"type,omitempty" jsonschema:"optional entity type"`
+	Properties map[string]any `json:"properties,omitempty" jsonschema:"fields to set; a null value removes the field"`
+	Body       *string        `json:"body,omitempty" jsonschema:"replacement markdown body; omit to keep the current body"`
+}
+
+type WriteEntityOutput struct {
+	Path   string        `json:"path"`
+	Entity *EntityOutput `json:"entity,omitempty"`
+}
+
+// EnableWrites registers the create_entity and update_entity tools when at
+// least one layer has a drafts path. Writes are ingested through db, which
+// should not be restricted to an audience; reads keep using the server's
+// store, so entities hidden from its audience cannot be edited.
+func (s *Server) EnableWrites(cfg *config.ProjectConfig, db store.Store) {
+	hasDrafts := false
+	for _, layer := range cfg.Layers {
+		if layer.Drafts != "" {
+			hasDrafts = true
+		}
+	}
+	if !hasDrafts {
+		return
+	}
+	s.cfg = cfg
+	s.writer = db
+
+	sdk.AddTool(s.mcp, &sdk.Tool{
+		Name:        "create_entity",
+		Description: "Create a markdown file for a new entity in a layer's drafts path and ingest it",
+	}, s.handleCreateEntity)
+
+	sdk.AddTool(s.mcp, &sdk.Tool{
+		Name:        "update_entity",
+		Description: "Edit the frontmatter or body of an entity file in a drafts path and ingest it",
+	}, s.handleUpdateEntity)
+}
+
+func (s *Server) handleCreateEntity(ctx context.Context, req *sdk.CallToolRequest, input CreateEntityInput) (*sdk.CallToolResult, WriteEntityOutput, error) {
+	if input.Name == "" || input.Type == "" || input.Layer == "" {
+		return nil, WriteEntityOutput{}, fmt.Errorf("name, type and layer are required")
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	path, err := drafts.Create(ctx, s.cfg, s.schema, s.writer, input.Layer, drafts.Entity{
+		Name:   input.Name,
+		Type:   input.Type,
+		Fields: input.Properties,
+		Body:   input.Body,
+	})
+	if err != nil {
+		return nil, WriteEntityOutput{}, err
+	}
+	return nil, s.writeOutput(ctx, path, input.Name, input.Type), nil
+}
+
+func (s *Server) handleUpdateEntity(ctx context.Context, req *sdk.CallToolRequest, input UpdateEntityInput) (*sdk.CallToolResult, WriteEntityOutput, error) {
+	if input.Name == "" {
+		return nil, WriteEntityOutput{}, fmt.Errorf("name is required")
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	entity, err := s.db.GetEntity(ctx, input.Name, input.Type)
+	if err != nil {
+		return nil, WriteEntityOutput{}, err
+	}
+	if entity == nil {
+		return nil, WriteEntityOutput{}, fmt.Errorf("entity not found")
+	}
+
+	err = drafts.Update(ctx, s.cfg, s.schema, s.writer, entity.Layer, entity.SourceFile, drafts.Patch{
+		Fields: input.Properties,
+		Body:   input.Body,
+	})
+	if err != nil {
+		return nil, WriteEntityOutput{}, err
+	}
+	return nil, s.writeOutput(ctx, entity.SourceFile, entity.Name, entity.EntityType), nil
+}
+
+// writeOutput reports the written file with the entity as now stored, read
+// through the server's store so audience filtering still applies.
+func (s *Server) writeOutput(ctx context.Context, path, name, entityType string) WriteEntityOutput {
+	output := WriteEntityOutput{Path: path}
+	if entity, err := s.db.GetEntity(ctx, name, entityType); err == nil && entity != nil {
+		out := entityOutputFromStore(entity)
+		output.Entity = &out
+	}
+	return output
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Link is an inline reference from a document body to another entity. Wiki
+// links name the target entity directly; markdown links carry the relative
+// path of another lore file, which ingest resolves to that file's title, or
+// with an Anchor to the title of the section it names.
+type Link struct {
+	Target string
+	Text   string
+	Path   string
+	Anchor string
+}
+
+var (
+	wikiLinkPattern     = regexp.MustCompile(`\[\[([^\[\]|]+?)(?:\|([^\[\]]*))?\]\]`)
+	markdownLinkPattern = regexp.MustCompile(`(!?)\[([^\[\]]*)\]\(\s*<?([^()\s<>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	inlineCodePattern   = regexp.MustCompile("`[^`\n]*`")
+)
+
+// extractLinks returns the wiki links and relative markdown links in body, in
+// order of first appearance. Links inside fenced or inline code are ignored.
+func extractLinks(body string) []Link {
+	var links []Link
+	seen := make(map[string]struct{})
+	add := func(link Link) {
+		key := strings.ToLower(link.Target) + "\x00" + link.Path + "\x00" + link.Anchor
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		links = append(links, link)
+	}
+
+	inFence := false
+	fence := ""
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if inFence {
+			if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = true
+			fence = trimmed[:3]
+			continue
+		}
+
+		line = inlineCodePattern.ReplaceAllString(line, "")
+
+		for _, match := range wikiLinkPattern.FindAllStringSubmatch(line, -1) {
+			target := match[1]
+			if idx := strings.Index(target, "#"); idx >= 0 {
+				target = target[:idx]
+			}
+			target = strings.TrimSpace(target)
+			if target == "" {
+				continue
+			}
+			text := strings.TrimSpace(match[2])
+			if text == "" {
+				text = target
+			}
+			add(Link{Target: target, Text: text})
+		}
+
+		for _, match := range markdownLinkPattern.FindAllStringSubmatch(line, -1) {
+			if match[1] == "!" {
+				continue
+			}
+			linkPath, anchor, ok := relativeMarkdownPath(match[3])
+			if !ok {
+				continue
+			}
+			add(Link{Text: strings.TrimSpace(match[2]), Path: linkPath, Anchor: anchor})
+		}
+	}
+
+	return links
+}
+
+// relativeMarkdownPath reports whether dest refers to another markdown file by
+// relative path, returning the cleaned path without fragment or query, and the
+// fragment.
+func relativeMarkdownPath(dest string) (string, string, bool) {
+	if strings.Contains(dest, "://") || strings.HasPrefix(dest, "mailto:") || strings.HasPrefix(dest, "#") {
+		return "", "", false
+	}
+	anchor := ""
+	if idx := strings.Index(dest, "#"); idx >= 0 {
+		anchor = dest[idx+1:]
+		dest = dest[:idx]
+	}
+	if idx := strings.Index(dest, "?"); idx >= 0 {
+		dest = dest[:idx]
+	}
+	if unescaped, err := url.PathUnescape(dest); err == nil {
+		dest = unescaped
+	}
+	if dest == "" || path.IsAbs(dest) || !strings.EqualFold(path.Ext(dest), ".md") {
+		return "", "", false
+	}
+	return path.Clean(dest), strings.ToLower(anchor), true
+}
@@ -5,9 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 type Document struct {
@@ -15,8 +14,22 @@ type Document struct {
 	Title       string
 	EntityType  string
 	Tags        []string
-	Body        string
-	SourceFile  string
+	// Aliases are alternate names the entity can be referenced by.
+	Aliases []string
+	Body    string
+	// Secret holds the GM-only blocks removed from Body.
+	Secret string
+	Links  []Link
+	// Anchor identifies the section of a multi-entity file that defines the
+	// entity; it is empty for the entity at the top of the file.
+	Anchor string
+	// Raw is the part of the file that defines the entity, hashed to detect
+	// changes. For a file with one entity it is the whole file.
+	Raw []byte
+	// Inferred lists, sorted, the frontmatter keys filled from defaults
+	// rather than written in the file.
+	Inferred   []string
+	SourceFile string
 }
 
 var (
@@ -26,42 +39,70 @@ var (
 	ErrMissingType   = errors.New("frontmatter missing required 'type' field")
 )
 
+// ParseFile parses the first entity defined in the file at path.
 func ParseFile(path string) (*Document, error) {
+	docs, err := ParseFileAll(path)
+	if err != nil {
+		return nil, err
+	}
+	return docs[0], nil
+}
+
+// ParseFileAll parses every entity defined in the file at path.
+func ParseFileAll(path string) ([]*Document, error) {
+	return ParseFileAllWithDefaults(path, nil)
+}
+
+// ParseFileAllWithDefaults parses every entity defined in the file at path,
+// filling frontmatter keys an entity does not set, type included, from
+// defaults.
+func ParseFileAllWithDefaults(path string, defaults map[string]any) ([]*Document, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	doc, err := Parse(data)
+	docs, err := ParseAllWithDefaults(data, defaults)
 	if err != nil {
 		return nil, err
 	}
-	doc.SourceFile = path
-	return doc, nil
+	for _, doc := range docs {
+		doc.SourceFile = path
+	}
+	return docs, nil
 }
 
+// Parse parses the first entity defined in content.
 func Parse(content []byte) (*Document, error) {
-	trimmed := bytes.TrimLeft(content, "\ufeff\n\r\t ")
-	if !bytes.HasPrefix(trimmed, []byte("---\n")) {
-		return nil, ErrNoFrontmatter
-	}
-
-	rest := trimmed[len("---\n"):]
-	end := bytes.Index(rest, []byte("---\n"))
-	if end == -1 {
-		return nil, ErrNoFrontmatter
+	docs, err := ParseAll(content)
+	if err != nil {
+		return nil, err
 	}
+	return docs[0], nil
+}
 
-	yamlBytes := rest[:end]
-	body := string(rest[end+len("---\n"):])
-
-	var frontmatter map[string]any
-	if err := yaml.Unmarshal(yamlBytes, &frontmatter); err != nil {
-		return nil, ErrInvalidYAML
+// newDocument builds a document from decoded frontmatter and the body that
+// follows it. defaultTitle is used when the frontmatter sets no title, and
+// defaults fill the other keys it does not set.
+func newDocument(frontmatter map[string]any, body, defaultTitle string, defaults map[string]any) (*Document, error) {
+	var inferred []string
+	for key, value := range defaults {
+		if _, ok := frontmatter[key]; ok {
+			continue
+		}
+		if frontmatter == nil {
+			frontmatter = make(map[string]any)
+		}
+		frontmatter[key] = value
+		inferred = append(inferred, key)
 	}
+	sort.Strings(inferred)
 
 	title, ok := frontmatter["title"].(string)
 	if !ok || strings.TrimSpace(title) == "" {
+		title = defaultTitle
+	}
+	if strings.TrimSpace(title) == "" {
 		return nil, ErrMissingTitle
 	}
 
@@ -70,21 +111,49 @@ func Parse(content []byte) (*Document, error) {
 		return nil, ErrMissingType
 	}
 
-	tags, err := parseTags(frontmatter["tags"])
+	tags, err := parseStringList("tags", frontmatter["tags"])
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := parseStringList("aliases", frontmatter["aliases"])
 	if err != nil {
 		return nil, err
 	}
 
+	public, secret := splitSecrets(body)
+
 	return &Document{
 		Frontmatter: frontmatter,
 		Title:       title,
 		EntityType:  entityType,
 		Tags:        tags,
-		Body:        body,
+		Aliases:     uniqueAliases(title, aliases),
+		Body:        public,
+		Secret:      secret,
+		Links:       extractLinks(public),
+		Inferred:    inferred,
 	}, nil
 }
 
-func parseTags(value any) ([]string, error) {
+// SplitFrontmatter returns the raw YAML between the leading --- delimiters of
+// content and the body that follows them.
+func SplitFrontmatter(content []byte) ([]byte, string, error) {
+	trimmed := bytes.TrimLeft(content, "\ufeff\n\r\t ")
+	if !bytes.HasPrefix(trimmed, []byte("---\n")) {
+		return nil, "", ErrNoFrontmatter
+	}
+
+	rest := trimmed[len("---\n"):]
+	end := bytes.Index(rest, []byte("---\n"))
+	if end == -1 {
+		return nil, "", ErrNoFrontmatter
+	}
+
+	return rest[:end], string(rest[end+len("---\n"):]), nil
+}
+
+func parseStringList(field string, value any) ([]string, error) {
 	if value == nil {
 		return nil, nil
 	}
@@ -99,7 +168,7 @@ func parseTags(value any) ([]string, error) {
 		for _, item := range v {
 			s, ok := item.(string)
 			if !ok {
-				return nil, fmt.Errorf("tags must be strings")
+				return nil, fmt.Errorf("%s must be strings", field)
 			}
 			if strings.TrimSpace(s) == "" {
 				continue
@@ -111,6 +180,23 @@ func parseTags(value any) ([]string, error) {
 		}
 		return tags, nil
 	default:
-		return nil, fmt.Errorf("tags must be string or list of strings")
+		return nil, fmt.Errorf("%s must be string or list of strings", field)
+	}
+}
+
+// uniqueAliases drops aliases that repeat the title or an earlier alias,
+// ignoring case.
+func uniqueAliases(title string, aliases []string) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(title)): true}
+	var unique []string
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		key := strings.ToLower(alias)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, alias)
 	}
+	return unique
 }
@@ -152,3 +152,61 @@ func TestParseFile_ReadError(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestParse_Links(t *testing.T) {
+	content := []byte("---\ntitle: Westport\ntype: settlement\n---\n\n" +
+		"Home of [[Lysa Quent|the Director]] and [[The Westlands#Geography]].\n" +
+		"See [the bureau](factions/bureau%20of%20civic%20affairs.md#history), [[lysa quent]] again,\n" +
+		"[the wiki](https://example.com/page.md), ![map](map.md) and `[[Not A Link]]`.\n" +
+		"~~~\n[[Also Not A Link]]\n~~~\n")
+
+	doc, err := Parse(content)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []Link{
+		{Target: "Lysa Quent", Text: "the Director"},
+		{Target: "The Westlands", Text: "The Westlands"},
+		{Text: "the bureau", Path: "factions/bureau of civic affairs.md", Anchor: "history"},
+	}
+	if !reflect.DeepEqual(doc.Links, expected) {
+		t.Fatalf("unexpected links: %#v", doc.Links)
+	}
+}
+
+func TestParse_SecretBlocks(t *testing.T) {
+	content := []byte("---\ntitle: Lysa Quent\ntype: npc\n---\n\n" +
+		"Director of the Bureau.\n\n" +
+		"```gm\nSecretly funds the [[Iron Tide]].\n```\n\n" +
+		"```go\nfmt.Println(\"kept\")\n```\n\n" +
+		"Known to dislike the harbor.\n\n" +
+		"~~~~ Secret\nPlans to seize Westport.\n~~~\nstill secret\n~~~~\n")
+
+	doc, err := Parse(content)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedBody := "\nDirector of the Bureau.\n\n\n" +
+		"```go\nfmt.Println(\"kept\")\n```\n\n" +
+		"Known to dislike the harbor.\n\n"
+	if doc.Body != expectedBody {
+		t.Fatalf("unexpected public body: %q", doc.Body)
+	}
+	expectedSecret := "Secretly funds the [[Iron Tide]].\n\nPlans to seize Westport.\n~~~\nstill secret"
+	if doc.Secret != expectedSecret {
+		t.Fatalf("unexpected secret: %q", doc.Secret)
+	}
+	if len(doc.Links) != 0 {
+		t.Fatalf("links in secret blocks should be ignored, got %#v", doc.Links)
+	}
+
+	unterminated, err := Parse([]byte("---\ntitle: X\ntype: npc\n---\nOpen.\n```gm\nNever published.\n"))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if unterminated.Body != "Open.\n" || unterminated.Secret != "Never published." {
+		t.Fatalf("unexpected split of unterminated block: %q / %q", unterminated.Body, unterminated.Secret)
+	}
+}
@@ -0,0 +1,74 @@
+package parser
+
+import "strings"
+
+// secretFenceInfo lists the info strings that mark a fenced block as GM-only.
+var secretFenceInfo = map[string]bool{"gm": true, "secret": true}
+
+// splitSecrets separates the GM-only fenced blocks of body from the public
+// text. It returns body with those blocks removed and the contents of the
+// blocks joined by blank lines. Ordinary code fences are left in place, and an
+// unterminated secret block runs to the end of body so it is never published.
+func splitSecrets(body string) (public, secret string) {
+	var pub, sec strings.Builder
+	var secrets []string
+
+	inFence, inSecret := false, false
+	fence := ""
+	for _, line := range strings.SplitAfter(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inSecret:
+			if isClosingFence(trimmed, fence) {
+				inSecret = false
+				secrets = append(secrets, strings.TrimRight(sec.String(), "\n"))
+				sec.Reset()
+				continue
+			}
+			sec.WriteString(line)
+			continue
+		case inFence:
+			if isClosingFence(trimmed, fence) {
+				inFence = false
+			}
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = openingFence(trimmed)
+			info := strings.ToLower(strings.TrimSpace(trimmed[len(fence):]))
+			if fields := strings.Fields(info); len(fields) > 0 && secretFenceInfo[fields[0]] {
+				inSecret = true
+				continue
+			}
+			inFence = true
+		}
+		pub.WriteString(line)
+	}
+	if inSecret {
+		secrets = append(secrets, strings.TrimRight(sec.String(), "\n"))
+	}
+
+	var kept []string
+	for _, s := range secrets {
+		if strings.TrimSpace(s) != "" {
+			kept = append(kept, s)
+		}
+	}
+	return pub.String(), strings.Join(kept, "\n\n")
+}
+
+// openingFence returns the run of backticks or tildes that opens a fence.
+func openingFence(line string) string {
+	n := 0
+	for n < len(line) && line[n] == line[0] {
+		n++
+	}
+	return line[:n]
+}
+
+// isClosingFence reports whether line closes a block opened with fence: the
+// same character, at least as many times, and nothing after it.
+func isClosingFence(line, fence string) bool {
+	if !strings.HasPrefix(line, fence) {
+		return false
+	}
+	return strings.Trim(line, fence[:1]) == ""
+}
@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseAll parses every entity defined in content. The frontmatter at the top
+// of the file defines the first entity. Further entities each start at a
+// frontmatter block of their own: a "---" line outside code fences, YAML that
+// sets a type, and a closing "---" line. A block directly under a "## "
+// heading takes its title from the heading unless it sets one. When a file
+// has such sections, top frontmatter without a type only describes the file.
+func ParseAll(content []byte) ([]*Document, error) {
+	return ParseAllWithDefaults(content, nil)
+}
+
+// ParseAllWithDefaults parses every entity defined in content like ParseAll,
+// filling frontmatter keys an entity does not set from defaults. A type
+// among the defaults does not turn top frontmatter that only describes the
+// file into an entity.
+func ParseAllWithDefaults(content []byte, defaults map[string]any) ([]*Document, error) {
+	var top map[string]any
+	bodyStart := 0
+	yamlBytes, body, err := SplitFrontmatter(content)
+	hasTop := err == nil
+	if hasTop {
+		if err := yaml.Unmarshal(yamlBytes, &top); err != nil {
+			return nil, ErrInvalidYAML
+		}
+		bodyStart = len(content) - len(body)
+	}
+
+	sections := findSections(content, bodyStart)
+	topEnd := len(content)
+	if len(sections) > 0 {
+		topEnd = sections[0].start
+	}
+
+	var docs []*Document
+	// With sections, top frontmatter without a type describes the file.
+	if hasTop && (len(sections) == 0 || hasType(top)) {
+		doc, err := newDocument(top, string(content[bodyStart:topEnd]), "", defaults)
+		if err != nil {
+			return nil, err
+		}
+		doc.Raw = content[:topEnd]
+		docs = append(docs, doc)
+	}
+
+	anchors := map[string]int{}
+	for i, section := range sections {
+		end := len(content)
+		if i+1 < len(sections) {
+			end = sections[i+1].start
+		}
+		doc, err := newDocument(section.frontmatter, string(content[section.bodyStart:end]), section.heading, defaults)
+		if err != nil {
+			return nil, fmt.Errorf("entity at line %d: %w", section.line, err)
+		}
+		name := section.heading
+		if name == "" {
+			name = doc.Title
+		}
+		doc.Anchor = uniqueAnchor(headingAnchor(name), anchors)
+		doc.Raw = content[section.start:end]
+		docs = append(docs, doc)
+	}
+
+	if len(docs) == 0 {
+		return nil, ErrNoFrontmatter
+	}
+	return docs, nil
+}
+
+type section struct {
+	start       int
+	bodyStart   int
+	line        int
+	heading     string
+	frontmatter map[string]any
+}
+
+type sourceLine struct {
+	start int
+	end   int
+	text  string
+}
+
+// findSections locates the frontmatter blocks that start further entities in
+// content after offset from. A "---" line that does not open YAML with a type
+// is an ordinary horizontal rule.
+func findSections(content []byte, from int) []section {
+	var lines []sourceLine
+	for start := from; start < len(content); {
+		end := bytes.IndexByte(content[start:], '\n')
+		if end == -1 {
+			end = len(content)
+		} else {
+			end += start + 1
+		}
+		lines = append(lines, sourceLine{start: start, end: end, text: strings.TrimRight(string(content[start:end]), " \t\r\n")})
+		start = end
+	}
+
+	var sections []section
+	floor := 0
+	inFence := false
+	fence := ""
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i].text)
+		if inFence {
+			if isClosingFence(trimmed, fence) {
+				inFence = false
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = true
+			fence = openingFence(trimmed)
+			continue
+		}
+		if lines[i].text != "---" {
+			continue
+		}
+		closing := -1
+		for j := i + 1; j < len(lines); j++ {
+			if lines[j].text == "---" {
+				closing = j
+				break
+			}
+		}
+		if closing == -1 {
+			break
+		}
+		frontmatter, ok := sectionFrontmatter(content[lines[i].end:lines[closing].start])
+		if !ok {
+			continue
+		}
+
+		s := section{
+			start:       lines[i].start,
+			bodyStart:   lines[closing].end,
+			frontmatter: frontmatter,
+		}
+		if k, heading := headingAbove(lines, i, floor); k >= 0 {
+			s.start = lines[k].start
+			s.heading = heading
+		}
+		s.line = bytes.Count(content[:s.start], []byte("\n")) + 1
+		sections = append(sections, s)
+		i = closing
+		floor = closing + 1
+	}
+	return sections
+}
+
+// sectionFrontmatter decodes a block that may open a section. Only a YAML
+// mapping that sets a type does.
+func sectionFrontmatter(block []byte) (map[string]any, bool) {
+	var frontmatter map[string]any
+	if err := yaml.Unmarshal(block, &frontmatter); err != nil || frontmatter == nil {
+		return nil, false
+	}
+	if !hasType(frontmatter) {
+		return nil, false
+	}
+	return frontmatter, true
+}
+
+func hasType(frontmatter map[string]any) bool {
+	entityType, ok := frontmatter["type"].(string)
+	return ok && strings.TrimSpace(entityType) != ""
+}
+
+// headingAbove finds a "## " heading separated from line i only by blank
+// lines, looking no further back than floor.
+func headingAbove(lines []sourceLine, i, floor int) (int, string) {
+	for k := i - 1; k >= floor; k-- {
+		text := strings.TrimSpace(lines[k].text)
+		if text == "" {
+			continue
+		}
+		if !strings.HasPrefix(text, "## ") {
+			return -1, ""
+		}
+		heading := strings.TrimSpace(strings.TrimRight(text[len("## "):], "#"))
+		if heading == "" {
+			return -1, ""
+		}
+		return k, heading
+	}
+	return -1, ""
+}
+
+// headingAnchor derives the anchor markdown renderers give a heading:
+// lower-case, punctuation dropped and spaces turned into hyphens.
+func headingAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// uniqueAnchor numbers repeated anchors the way renderers do: the second
+// "tavern" becomes "tavern-1".
+func uniqueAnchor(anchor string, seen map[string]int) string {
+	n := seen[anchor]
+	seen[anchor] = n + 1
+	if n == 0 {
+		return anchor
+	}
+	return anchor + "-" + strconv.Itoa(n)
+}
@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAll(t *testing.T) {
+	t.Run("single entity", func(t *testing.T) {
+		content := []byte("---\ntitle: Westport\ntype: settlement\n---\n\nA harbour town.\n\n---\n\nAfter the rule.\n")
+		docs, err := ParseAll(content)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(docs) != 1 {
+			t.Fatalf("expected one entity, got %d", len(docs))
+		}
+		if docs[0].Anchor != "" || string(docs[0].Raw) != string(content) {
+			t.Fatalf("expected the whole file without an anchor, got %q and %q", docs[0].Anchor, docs[0].Raw)
+		}
+		if !strings.Contains(docs[0].Body, "After the rule.") {
+			t.Fatalf("expected the horizontal rule to stay in the body, got %q", docs[0].Body)
+		}
+	})
+
+	t.Run("heading sections", func(t *testing.T) {
+		content := []byte("---\ntitle: Harbour Folk\n---\n\nPeople of Westport.\n\n" +
+			"## Mira Vell\n\n---\ntype: npc\nrole: Harbourmaster\n---\n\nKeeps the tide tables.\n\n" +
+			"## Old Tom\n\n---\ntype: npc\ntitle: Tomas Brine\n---\n\nSells bait.\n")
+		docs, err := ParseAll(content)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(docs) != 2 {
+			t.Fatalf("expected the untyped top block to describe the file, got %d entities", len(docs))
+		}
+		if docs[0].Title != "Mira Vell" || docs[0].Anchor != "mira-vell" || docs[0].Frontmatter["role"] != "Harbourmaster" {
+			t.Fatalf("unexpected first entity: %#v", docs[0])
+		}
+		if strings.TrimSpace(docs[0].Body) != "Keeps the tide tables." {
+			t.Fatalf("unexpected first body: %q", docs[0].Body)
+		}
+		if !strings.HasPrefix(string(docs[0].Raw), "## Mira Vell\n") {
+			t.Fatalf("expected the raw section to start at its heading, got %q", docs[0].Raw)
+		}
+		if docs[1].Title != "Tomas Brine" || docs[1].Anchor != "old-tom" {
+			t.Fatalf("expected the title to override the heading but keep its anchor, got %#v", docs[1])
+		}
+	})
+
+	t.Run("multi-document frontmatter", func(t *testing.T) {
+		content := []byte("---\ntitle: Westport\ntype: settlement\n---\n\nA harbour town.\n\n" +
+			"---\ntitle: The Drowned Bell\ntype: location\n---\n\nA tavern.\n")
+		docs, err := ParseAll(content)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(docs) != 2 {
+			t.Fatalf("expected two entities, got %d", len(docs))
+		}
+		if docs[0].Anchor != "" || strings.TrimSpace(docs[0].Body) != "A harbour town." {
+			t.Fatalf("unexpected top entity: %#v", docs[0])
+		}
+		if docs[1].Title != "The Drowned Bell" || docs[1].Anchor != "the-drowned-bell" {
+			t.Fatalf("unexpected second entity: %#v", docs[1])
+		}
+	})
+
+	t.Run("repeated anchors", func(t *testing.T) {
+		content := []byte("## Tavern\n\n---\ntype: location\n---\n\n## Tavern\n\n---\ntype: location\ntitle: Other Tavern\n---\n")
+		docs, err := ParseAll(content)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(docs) != 2 || docs[0].Anchor != "tavern" || docs[1].Anchor != "tavern-1" {
+			t.Fatalf("unexpected anchors: %#v", docs)
+		}
+	})
+
+	t.Run("code fences", func(t *testing.T) {
+		content := []byte("---\ntitle: Notes\ntype: lore\n---\n\n```yaml\n---\ntitle: Example\ntype: npc\n---\n```\n")
+		docs, err := ParseAll(content)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(docs) != 1 {
+			t.Fatalf("expected frontmatter inside a code fence to be ignored, got %d entities", len(docs))
+		}
+	})
+
+	t.Run("invalid section", func(t *testing.T) {
+		content := []byte("---\ntitle: Notes\ntype: lore\n---\n\n---\ntype: npc\n---\n")
+		_, err := ParseAll(content)
+		if !errors.Is(err, ErrMissingTitle) || !strings.Contains(err.Error(), "line 6") {
+			t.Fatalf("expected a missing title at line 6, got %v", err)
+		}
+	})
+
+	t.Run("no entities", func(t *testing.T) {
+		_, err := ParseAll([]byte("Just text\n\n---\n\nMore text\n"))
+		if !errors.Is(err, ErrNoFrontmatter) {
+			t.Fatalf("expected ErrNoFrontmatter, got %v", err)
+		}
+	})
+}
+
+func TestParseAllWithDefaults(t *testing.T) {
+	defaults := map[string]any{"type": "npc", "status": "alive"}
+
+	docs, err := ParseAllWithDefaults([]byte("---\ntitle: Mira Vell\nstatus: missing\n---\n"), defaults)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if docs[0].EntityType != "npc" || docs[0].Frontmatter["status"] != "missing" {
+		t.Fatalf("expected defaults only for unset keys, got %#v", docs[0].Frontmatter)
+	}
+	if len(docs[0].Inferred) != 1 || docs[0].Inferred[0] != "type" {
+		t.Fatalf("unexpected inferred keys: %#v", docs[0].Inferred)
+	}
+
+	content := []byte("---\ntitle: Harbour Folk\n---\n\n## Mira Vell\n\n---\ntype: npc\n---\n")
+	docs, err = ParseAllWithDefaults(content, defaults)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(docs) != 1 || docs[0].Title != "Mira Vell" {
+		t.Fatalf("expected the untyped top block to keep describing the file, got %#v", docs)
+	}
+}
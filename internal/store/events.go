@@ -0,0 +1,39 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EventSession reads the session property of an event entity, which YAML
+// may have decoded as a number or a string. Anything else is session 0.
+func EventSession(value any) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		session, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0
+		}
+		return session
+	default:
+		return 0
+	}
+}
+
+// EventDate reads the date_in_world property of an event entity as text.
+func EventDate(value any) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,35 @@
+package store
+
+import "testing"
+
+func TestEventSession(t *testing.T) {
+	cases := []struct {
+		value    any
+		expected int
+	}{
+		{value: 3, expected: 3},
+		{value: int64(4), expected: 4},
+		{value: 5.0, expected: 5},
+		{value: " 6 ", expected: 6},
+		{value: "six", expected: 0},
+		{value: nil, expected: 0},
+	}
+	for _, c := range cases {
+		if got := EventSession(c.value); got != c.expected {
+			t.Errorf("EventSession(%#v) = %d, want %d", c.value, got, c.expected)
+		}
+	}
+}
+
+func TestEventDate(t *testing.T) {
+	cases := map[any]string{
+		nil:           "",
+		"Spring 1203": "Spring 1203",
+		1203:          "1203",
+	}
+	for value, expected := range cases {
+		if got := EventDate(value); got != expected {
+			t.Errorf("EventDate(%#v) = %q, want %q", value, got, expected)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"slices"
+	"strings"
+)
+
+// MaxPaths bounds the number of paths FindPaths returns; the shortest are
+// kept.
+const MaxPaths = 50
+
+// PathHop is one step along a path. The hop leaves From and arrives at To;
+// Direction is "outgoing" when the relationship, labelled Type, points from
+// From to To and "incoming" when it points back.
+type PathHop struct {
+	From      EntityRef
+	To        EntityRef
+	Type      string
+	Direction string
+}
+
+type Path struct {
+	Hops []PathHop
+}
+
+// PathEdge is a stored edge loaded by a backend while searching for paths.
+type PathEdge struct {
+	SrcID int64
+	DstID int64
+	Src   EntityRef
+	Dst   EntityRef
+	Type  string
+}
+
+// Shortest returns the paths that share the minimum length.
+func Shortest(paths []Path) []Path {
+	if len(paths) == 0 {
+		return paths
+	}
+	shortest := len(paths[0].Hops)
+	for _, path := range paths {
+		shortest = min(shortest, len(path.Hops))
+	}
+	var result []Path
+	for _, path := range paths {
+		if len(path.Hops) == shortest {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+type pathLink struct {
+	to  int64
+	hop PathHop
+}
+
+// SearchPaths finds the simple paths of at most maxDepth hops from start to
+// goal, shortest first. expand loads every stored edge touching one of the
+// given entities. It is called with frontiers growing from both ends, half the
+// depth each, which reaches every edge such a path can use without loading the
+// whole neighbourhood of either end. relTypes restricts the edges followed and
+// may use inverse names, which then label the hops as GetRelationships does.
+func SearchPaths(start, goal int64, maxDepth int, types RelationshipTypes, relTypes []string, expand func(ids []int64) ([]PathEdge, error)) ([]Path, error) {
+	if start == goal {
+		return []Path{}, nil
+	}
+
+	links := map[int64][]pathLink{}
+	// A symmetric relationship is often stored once from each side; both
+	// copies are the same hop.
+	link := func(from, to int64, hop PathHop, symmetric bool) {
+		if symmetric {
+			for _, existing := range links[from] {
+				if existing.to == to && existing.hop.Type == hop.Type {
+					return
+				}
+			}
+		}
+		links[from] = append(links[from], pathLink{to, hop})
+	}
+	// loaded records whether each edge seen so far can be followed.
+	loaded := map[PathEdge]bool{}
+	grow := func(from int64, rounds int) error {
+		visited := map[int64]bool{from: true}
+		frontier := []int64{from}
+		for round := 0; round < rounds && len(frontier) > 0; round++ {
+			edges, err := expand(frontier)
+			if err != nil {
+				return err
+			}
+			var next []int64
+			for _, edge := range edges {
+				followed, seen := loaded[edge]
+				if !seen {
+					if label, direction, ok := matchPathEdge(types, edge.Type, "outgoing", relTypes); ok {
+						link(edge.SrcID, edge.DstID, PathHop{From: edge.Src, To: edge.Dst, Type: label, Direction: direction}, types[strings.ToUpper(edge.Type)].Symmetric)
+						followed = true
+					}
+					if label, direction, ok := matchPathEdge(types, edge.Type, "incoming", relTypes); ok {
+						link(edge.DstID, edge.SrcID, PathHop{From: edge.Dst, To: edge.Src, Type: label, Direction: direction}, types[strings.ToUpper(edge.Type)].Symmetric)
+						followed = true
+					}
+					loaded[edge] = followed
+				}
+				if !followed {
+					continue
+				}
+				for _, id := range []int64{edge.SrcID, edge.DstID} {
+					if !visited[id] {
+						visited[id] = true
+						next = append(next, id)
+					}
+				}
+			}
+			frontier = next
+		}
+		return nil
+	}
+	if err := grow(start, (maxDepth+1)/2); err != nil {
+		return nil, err
+	}
+	if err := grow(goal, maxDepth/2); err != nil {
+		return nil, err
+	}
+
+	for id := range links {
+		slices.SortFunc(links[id], func(a, b pathLink) int {
+			if c := strings.Compare(strings.ToLower(a.hop.To.Name), strings.ToLower(b.hop.To.Name)); c != 0 {
+				return c
+			}
+			return strings.Compare(a.hop.Type, b.hop.Type)
+		})
+	}
+
+	// Hops left to the goal, for pruning walks that cannot arrive in time.
+	// Every followed edge is linked from both ends, so walking links away
+	// from the goal measures the distance back to it.
+	remaining := map[int64]int{goal: 0}
+	queue := []int64{goal}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, link := range links[id] {
+			if _, ok := remaining[link.to]; !ok {
+				remaining[link.to] = remaining[id] + 1
+				queue = append(queue, link.to)
+			}
+		}
+	}
+	if _, ok := remaining[start]; !ok {
+		return []Path{}, nil
+	}
+
+	// Walk once per length so paths come out shortest first and the search
+	// stops as soon as MaxPaths is reached.
+	paths := []Path{}
+	onPath := map[int64]bool{start: true}
+	var hops []PathHop
+	var walk func(id int64, length int)
+	walk = func(id int64, length int) {
+		if len(paths) >= MaxPaths {
+			return
+		}
+		if id == goal {
+			if len(hops) == length {
+				paths = append(paths, Path{Hops: slices.Clone(hops)})
+			}
+			return
+		}
+		for _, link := range links[id] {
+			left, ok := remaining[link.to]
+			if !ok || onPath[link.to] || len(hops)+1+left > length {
+				continue
+			}
+			onPath[link.to] = true
+			hops = append(hops, link.hop)
+			walk(link.to, length)
+			hops = hops[:len(hops)-1]
+			delete(onPath, link.to)
+		}
+	}
+	for length := remaining[start]; length <= maxDepth; length++ {
+		walk(start, length)
+	}
+	return paths, nil
+}
+
+// matchPathEdge reports how an edge reads when walked from the side for
+// which it is naturally outgoing or incoming, if any requested type allows it.
+func matchPathEdge(types RelationshipTypes, storedType, natural string, relTypes []string) (string, string, bool) {
+	if len(relTypes) == 0 {
+		return types.Match(storedType, natural, "", "both")
+	}
+	for _, requested := range relTypes {
+		if label, direction, ok := types.Match(storedType, natural, requested, "both"); ok {
+			return label, direction, true
+		}
+	}
+	return "", "", false
+}
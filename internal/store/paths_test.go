@@ -0,0 +1,155 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type pathFixture struct {
+	names map[int64]string
+	edges []PathEdge
+}
+
+func newPathFixture(edges ...string) *pathFixture {
+	f := &pathFixture{names: map[int64]string{}}
+	ids := map[string]int64{}
+	id := func(name string) int64 {
+		if existing, ok := ids[name]; ok {
+			return existing
+		}
+		ids[name] = int64(len(ids) + 1)
+		f.names[ids[name]] = name
+		return ids[name]
+	}
+	for _, spec := range edges {
+		parts := strings.Fields(spec)
+		src, dst := id(parts[0]), id(parts[2])
+		f.edges = append(f.edges, PathEdge{
+			SrcID: src,
+			DstID: dst,
+			Src:   EntityRef{Name: parts[0]},
+			Dst:   EntityRef{Name: parts[2]},
+			Type:  parts[1],
+		})
+	}
+	return f
+}
+
+func (f *pathFixture) id(name string) int64 {
+	for id, n := range f.names {
+		if n == name {
+			return id
+		}
+	}
+	return 0
+}
+
+func (f *pathFixture) expand(ids []int64) ([]PathEdge, error) {
+	wanted := map[int64]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	var result []PathEdge
+	for _, edge := range f.edges {
+		if wanted[edge.SrcID] || wanted[edge.DstID] {
+			result = append(result, edge)
+		}
+	}
+	return result, nil
+}
+
+func describe(path Path) string {
+	var b strings.Builder
+	for i, hop := range path.Hops {
+		if i == 0 {
+			b.WriteString(hop.From.Name)
+		}
+		arrow := fmt.Sprintf(" -%s-> ", hop.Type)
+		if hop.Direction == "incoming" {
+			arrow = fmt.Sprintf(" <-%s- ", hop.Type)
+		}
+		b.WriteString(arrow + hop.To.Name)
+	}
+	return b.String()
+}
+
+func TestSearchPaths_ShortestFirst(t *testing.T) {
+	types := NewRelationshipTypes([]RelationshipType{{Name: "MEMBER_OF", Inverse: "HAS_MEMBER"}})
+	f := newPathFixture(
+		"Selin MEMBER_OF Bureau",
+		"Lysa MEMBER_OF Bureau",
+		"Lysa RIVAL_OF Tide",
+		"Selin KNOWS Marek",
+		"Marek KNOWS Ona",
+		"Ona HIRED Tide",
+	)
+
+	paths, err := SearchPaths(f.id("Selin"), f.id("Tide"), 4, types, nil, f.expand)
+	if err != nil {
+		t.Fatalf("search paths: %v", err)
+	}
+	got := make([]string, len(paths))
+	for i, path := range paths {
+		got[i] = describe(path)
+	}
+	want := []string{
+		"Selin -MEMBER_OF-> Bureau <-MEMBER_OF- Lysa -RIVAL_OF-> Tide",
+		"Selin -KNOWS-> Marek -KNOWS-> Ona -HIRED-> Tide",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected paths:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if shortest := Shortest(paths); len(shortest) != 2 {
+		t.Fatalf("expected both three-hop paths to be shortest, got %d", len(shortest))
+	}
+
+	paths, err = SearchPaths(f.id("Selin"), f.id("Tide"), 2, types, nil, f.expand)
+	if err != nil {
+		t.Fatalf("search paths: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Fatalf("expected no path within two hops, got %v", paths)
+	}
+}
+
+func TestSearchPaths_FiltersAndLabelsInverseTypes(t *testing.T) {
+	types := NewRelationshipTypes([]RelationshipType{{Name: "MEMBER_OF", Inverse: "HAS_MEMBER"}})
+	f := newPathFixture(
+		"Selin MEMBER_OF Bureau",
+		"Lysa MEMBER_OF Bureau",
+		"Selin KNOWS Lysa",
+	)
+
+	paths, err := SearchPaths(f.id("Bureau"), f.id("Lysa"), 3, types, []string{"HAS_MEMBER"}, f.expand)
+	if err != nil {
+		t.Fatalf("search paths: %v", err)
+	}
+	if len(paths) != 1 || describe(paths[0]) != "Bureau -HAS_MEMBER-> Lysa" {
+		t.Fatalf("unexpected paths: %#v", paths)
+	}
+
+	paths, err = SearchPaths(f.id("Selin"), f.id("Lysa"), 3, types, []string{"MEMBER_OF"}, f.expand)
+	if err != nil {
+		t.Fatalf("search paths: %v", err)
+	}
+	if len(paths) != 1 || describe(paths[0]) != "Selin -MEMBER_OF-> Bureau <-MEMBER_OF- Lysa" {
+		t.Fatalf("expected KNOWS to be skipped, got %#v", paths)
+	}
+}
+
+func TestSearchPaths_SymmetricEdgesStoredTwice(t *testing.T) {
+	types := NewRelationshipTypes([]RelationshipType{{Name: "RELATED_TO", Symmetric: true}})
+	f := newPathFixture(
+		"Lysa RELATED_TO Rellan",
+		"Rellan RELATED_TO Lysa",
+	)
+
+	paths, err := SearchPaths(f.id("Lysa"), f.id("Rellan"), 2, types, nil, f.expand)
+	if err != nil {
+		t.Fatalf("search paths: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("expected the two stored copies to form one path, got %#v", paths)
+	}
+}
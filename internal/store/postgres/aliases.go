@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"lorecraft/internal/store"
+)
+
+func normalizeAliases(aliases []string) []string {
+	normalized := make([]string, len(aliases))
+	for i, alias := range aliases {
+		normalized[i] = strings.ToLower(alias)
+	}
+	return normalized
+}
+
+// adoptAliasPlaceholders points edges at placeholders in the entity's layer
+// that are named by one of its aliases at the entity instead, and removes the
+// placeholders. References ingested before the alias was declared then resolve
+// without a full re-ingest, as references by name do.
+func adoptAliasPlaceholders(ctx context.Context, tx pgx.Tx, entityID int64, layer string, normalized []string) error {
+	if len(normalized) == 0 {
+		return nil
+	}
+	placeholders := `
+SELECT id FROM entities
+WHERE layer = $1
+  AND is_placeholder = TRUE
+  AND name_normalized = ANY($2)
+`
+
+	_, err := tx.Exec(ctx, `
+INSERT INTO edges (src_id, dst_id, rel_type)
+SELECT src_id, $3, rel_type FROM edges WHERE dst_id IN (`+placeholders+`)
+ON CONFLICT (src_id, dst_id, rel_type) DO NOTHING`,
+		layer, normalized, entityID,
+	)
+	if err != nil {
+		return fmt.Errorf("moving alias references: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM edges WHERE dst_id IN ("+placeholders+")", layer, normalized); err != nil {
+		return fmt.Errorf("removing alias placeholder edges: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM entities WHERE id IN ("+placeholders+")", layer, normalized); err != nil {
+		return fmt.Errorf("removing alias placeholders: %w", err)
+	}
+	return nil
+}
+
+// entityID resolves a name or alias the audience may see to an entity id,
+// preferring an entity with that name over one with that alias and a defined
+// entity over a placeholder.
+func (c *Client) entityID(ctx context.Context, name string) (int64, error) {
+	visible, visibleArgs := c.visibleClause("entities", 2)
+	query := `
+SELECT id FROM entities
+WHERE (name_normalized = $1 OR $1 = ANY(aliases_normalized))
+  AND ` + visible + `
+ORDER BY name_normalized = $1 DESC, is_placeholder
+LIMIT 1
+`
+
+	var id int64
+	err := c.conn().QueryRow(ctx, query, append([]any{strings.ToLower(name)}, visibleArgs...)...).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", store.ErrEntityNotFound, name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("finding entity %s: %w", name, err)
+	}
+	return id, nil
+}
+
+// aliasOwners names the entities an ambiguous alias points at.
+func aliasOwners(entities []store.Entity) string {
+	names := make([]string, len(entities))
+	for i, e := range entities {
+		names[i] = fmt.Sprintf("%s (%s)", e.Name, e.Layer)
+	}
+	return strings.Join(names, ", ")
+}
@@ -6,18 +6,21 @@ import (
 	"strings"
 )
 
-func (c *Client) RemoveStaleNodes(ctx context.Context, layer string, currentSourceFiles []string) (int64, error) {
+// sourceKey is store.SourceKey computed in SQL.
+const sourceKey = "source_file || CASE WHEN COALESCE(source_anchor, '') = '' THEN '' ELSE '#' || source_anchor END"
+
+func (c *Client) RemoveStaleNodes(ctx context.Context, layer string, currentSources []string) (int64, error) {
 	query := `
 DELETE FROM entities
 WHERE layer = $1
   AND source_file IS NOT NULL
   AND source_file <> ''
-  AND NOT (source_file = ANY($2))
+  AND NOT ((` + sourceKey + `) = ANY($2))
   AND is_placeholder = FALSE
 RETURNING id
 `
 
-	rows, err := c.pool.Query(ctx, query, layer, currentSourceFiles)
+	rows, err := c.conn().Query(ctx, query, layer, currentSources)
 	if err != nil {
 		return 0, fmt.Errorf("removing stale nodes: %w", err)
 	}
@@ -35,16 +38,30 @@ RETURNING id
 	return count, nil
 }
 
+func (c *Client) RemoveOrphanedPlaceholders(ctx context.Context) (int64, error) {
+	query := `
+DELETE FROM entities
+WHERE is_placeholder = TRUE
+  AND NOT EXISTS (SELECT 1 FROM edges WHERE edges.dst_id = entities.id)
+`
+
+	tag, err := c.conn().Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("removing orphaned placeholders: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
 func (c *Client) GetLayerHashes(ctx context.Context, layer string) (map[string]string, error) {
 	query := `
-SELECT source_file, source_hash FROM entities
+SELECT ` + sourceKey + `, source_hash FROM entities
 WHERE layer = $1
   AND source_file IS NOT NULL
   AND source_file <> ''
   AND is_placeholder = FALSE
 `
 
-	rows, err := c.pool.Query(ctx, query, layer)
+	rows, err := c.conn().Query(ctx, query, layer)
 	if err != nil {
 		return nil, fmt.Errorf("query layer hashes: %w", err)
 	}
@@ -52,11 +69,11 @@ WHERE layer = $1
 
 	hashes := make(map[string]string)
 	for rows.Next() {
-		var sourceFile, sourceHash string
-		if err := rows.Scan(&sourceFile, &sourceHash); err != nil {
+		var source, sourceHash string
+		if err := rows.Scan(&source, &sourceHash); err != nil {
 			return nil, fmt.Errorf("scanning layer hash: %w", err)
 		}
-		hashes[sourceFile] = sourceHash
+		hashes[source] = sourceHash
 	}
 
 	if err := rows.Err(); err != nil {
@@ -74,8 +91,8 @@ func (c *Client) FindEntityLayer(ctx context.Context, name string, layers []stri
 	nameNormalized := strings.ToLower(name)
 	for _, layer := range layers {
 		var found string
-		err := c.pool.QueryRow(ctx,
-			"SELECT layer FROM entities WHERE name_normalized = $1 AND layer = $2 LIMIT 1",
+		err := c.conn().QueryRow(ctx,
+			"SELECT layer FROM entities WHERE (name_normalized = $1 OR $1 = ANY(aliases_normalized)) AND layer = $2 LIMIT 1",
 			nameNormalized, layer,
 		).Scan(&found)
 		if err == nil {
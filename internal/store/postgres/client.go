@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"lorecraft/internal/config"
@@ -15,6 +17,21 @@ var _ store.Store = (*Client)(nil)
 type Client struct {
 	pool *pgxpool.Pool
 	cfg  *config.ProjectConfig
+
+	// tx is set on the client handed to an InTransaction callback; every
+	// query then runs on it so the unit of work sees its own writes.
+	tx pgx.Tx
+
+	visibility *store.Visibility
+
+	trigram *trigramState
+}
+
+// querier is satisfied by *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
 func New(ctx context.Context, dsn string, cfg *config.ProjectConfig) (*Client, error) {
@@ -26,10 +43,51 @@ func New(ctx context.Context, dsn string, cfg *config.ProjectConfig) (*Client, e
 		pool.Close()
 		return nil, fmt.Errorf("pinging postgres: %w", err)
 	}
-	return &Client{pool: pool, cfg: cfg}, nil
+	return &Client{pool: pool, cfg: cfg, trigram: &trigramState{}}, nil
 }
 
 func (c *Client) Close(ctx context.Context) error {
+	if c.tx != nil {
+		return nil
+	}
 	c.pool.Close()
 	return nil
 }
+
+// InTransaction runs fn against a client bound to a single transaction, which
+// is committed only if fn returns nil. Nested calls join the outer transaction.
+func (c *Client) InTransaction(ctx context.Context, fn func(store.Store) error) error {
+	if c.tx != nil {
+		return fn(c)
+	}
+
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&Client{pool: c.pool, cfg: c.cfg, tx: tx, visibility: c.visibility, trigram: c.trigram}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) conn() querier {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.pool
+}
+
+// begin opens the transaction a single store method writes through. Inside a
+// unit of work pgx implements it as a savepoint on the shared transaction.
+func (c *Client) begin(ctx context.Context) (pgx.Tx, error) {
+	if c.tx != nil {
+		return c.tx.Begin(ctx)
+	}
+	return c.pool.Begin(ctx)
+}
@@ -22,12 +22,36 @@ func (c *Client) UpsertEntity(ctx context.Context, e store.EntityInput) error {
 		tags = nil
 	}
 
+	aliases := e.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	aliasesNormalized := normalizeAliases(aliases)
+
+	defaulted := e.Defaulted
+	if defaulted == nil {
+		defaulted = []string{}
+	}
+
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-INSERT INTO entities (name, name_normalized, entity_type, layer, source_file, source_hash, tags, properties, body, is_placeholder, last_ingested, search_vector)
+INSERT INTO entities (name, name_normalized, entity_type, layer, source_file, source_hash, tags, properties, body, is_placeholder, last_ingested, search_vector, defaulted_properties, secret, secret_vector, aliases, aliases_normalized, source_anchor)
 VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, '{}'::text[]), $8, $9, FALSE, now(),
     setweight(to_tsvector('simple', coalesce($1, '')), 'A') ||
+    setweight(to_tsvector('simple', array_to_string($12::text[], ' ')), 'A') ||
     setweight(to_tsvector('english', coalesce(array_to_string(COALESCE($7, '{}'::text[]), ' '), '')), 'B') ||
-    setweight(to_tsvector('english', coalesce($9, '')), 'C')
+    setweight(to_tsvector('english', coalesce($9, '')), 'C'),
+    $10,
+    $11,
+    setweight(to_tsvector('english', coalesce($11, '')), 'D'),
+    $12,
+    $13,
+    $14
 )
 ON CONFLICT (name_normalized, layer) DO UPDATE SET
     name = EXCLUDED.name,
@@ -39,10 +63,18 @@ ON CONFLICT (name_normalized, layer) DO UPDATE SET
     body = EXCLUDED.body,
     is_placeholder = FALSE,
     last_ingested = now(),
-    search_vector = EXCLUDED.search_vector
+    search_vector = EXCLUDED.search_vector,
+    defaulted_properties = EXCLUDED.defaulted_properties,
+    secret = EXCLUDED.secret,
+    secret_vector = EXCLUDED.secret_vector,
+    aliases = EXCLUDED.aliases,
+    aliases_normalized = EXCLUDED.aliases_normalized,
+    source_anchor = EXCLUDED.source_anchor
+RETURNING id
 `
 
-	_, err = c.pool.Exec(ctx, query,
+	var entityID int64
+	err = tx.QueryRow(ctx, query,
 		e.Name,
 		nameNormalized,
 		e.EntityType,
@@ -52,44 +84,87 @@ ON CONFLICT (name_normalized, layer) DO UPDATE SET
 		tags,
 		propsJSON,
 		e.Body,
-	)
+		defaulted,
+		e.Secret,
+		aliases,
+		aliasesNormalized,
+		e.SourceAnchor,
+	).Scan(&entityID)
 	if err != nil {
 		return fmt.Errorf("upserting entity: %w", err)
 	}
+
+	// Outgoing edges are rebuilt from the current frontmatter by the caller, so
+	// references removed from the file do not linger.
+	if _, err := tx.Exec(ctx, "DELETE FROM edges WHERE src_id = $1", entityID); err != nil {
+		return fmt.Errorf("clearing outgoing edges: %w", err)
+	}
+
+	if err := upsertEvent(ctx, tx, entityID, e); err != nil {
+		return err
+	}
+
+	if err := adoptAliasPlaceholders(ctx, tx, entityID, e.Layer, aliasesNormalized); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
 	return nil
 }
 
 func (c *Client) GetEntity(ctx context.Context, name, entityType string) (*store.Entity, error) {
+	return c.getEntity(ctx, name, entityType, "")
+}
+
+func (c *Client) GetEntityInLayer(ctx context.Context, name, layer string) (*store.Entity, error) {
+	return c.getEntity(ctx, name, "", layer)
+}
+
+// getEntity looks an entity up by name or alias, optionally only among the
+// given type and its subtypes or within one layer.
+func (c *Client) getEntity(ctx context.Context, name, entityType, layer string) (*store.Entity, error) {
 	nameNormalized := strings.ToLower(name)
 
+	// An entity is looked up by name first and by alias only when no name
+	// matches.
+	visible, visibleArgs := c.visibleClause("entities", 4)
 	query := `
-SELECT id, name, entity_type, layer, source_file, source_hash, tags, properties, body
+SELECT name_normalized = $1, name, entity_type, layer, source_file, COALESCE(source_anchor, ''), source_hash, tags, COALESCE(aliases, '{}'::text[]), properties, body, COALESCE(defaulted_properties, '{}'::text[]), COALESCE(secret, '')
 FROM entities
-WHERE name_normalized = $1
-  AND ($2 = '' OR entity_type = $2)
+WHERE (name_normalized = $1 OR $1 = ANY(aliases_normalized))
+  AND ` + typeClause("entities", 2) + `
+  AND ($3 = '' OR lower(layer) = lower($3))
   AND is_placeholder = FALSE
-`
+  AND ` + visible
 
-	rows, err := c.pool.Query(ctx, query, nameNormalized, entityType)
+	args := append([]any{nameNormalized, entityType, layer}, visibleArgs...)
+	rows, err := c.conn().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("getting entity: %w", err)
 	}
 	defer rows.Close()
 
-	var entities []store.Entity
+	var entities, aliased []store.Entity
 	for rows.Next() {
 		var e store.Entity
+		var exact bool
 		var propsBytes []byte
 		err := rows.Scan(
-			new(int64),
+			&exact,
 			&e.Name,
 			&e.EntityType,
 			&e.Layer,
 			&e.SourceFile,
+			&e.SourceAnchor,
 			&e.SourceHash,
 			&e.Tags,
+			&e.Aliases,
 			&propsBytes,
 			&e.Body,
+			&e.Defaulted,
+			&e.Secret,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning entity: %w", err)
@@ -105,13 +180,27 @@ WHERE name_normalized = $1
 		if e.Tags == nil {
 			e.Tags = []string{}
 		}
-		entities = append(entities, e)
+		if c.visibility != nil {
+			e.Secret = ""
+		}
+		if exact {
+			entities = append(entities, e)
+		} else {
+			aliased = append(aliased, e)
+		}
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating entity rows: %w", err)
 	}
 
+	if len(entities) == 0 {
+		if len(aliased) > 1 {
+			return nil, fmt.Errorf("ambiguous alias %q: %s", name, aliasOwners(aliased))
+		}
+		entities = aliased
+	}
+
 	if len(entities) == 0 {
 		return nil, nil
 	}
@@ -123,17 +212,20 @@ WHERE name_normalized = $1
 }
 
 func (c *Client) ListEntities(ctx context.Context, entityType, layer, tag string) ([]store.EntitySummary, error) {
+	visible, visibleArgs := c.visibleClause("entities", 4)
 	query := `
 SELECT name, entity_type, layer, tags
 FROM entities
-WHERE ($1 = '' OR entity_type = $1)
+WHERE ` + typeClause("entities", 1) + `
   AND ($2 = '' OR layer = $2)
   AND ($3 = '' OR $3 = ANY(tags))
   AND is_placeholder = FALSE
+  AND ` + visible + `
 ORDER BY name
 `
 
-	rows, err := c.pool.Query(ctx, query, entityType, layer, tag)
+	args := append([]any{entityType, layer, tag}, visibleArgs...)
+	rows, err := c.conn().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("listing entities: %w", err)
 	}
@@ -164,14 +256,16 @@ ORDER BY name
 }
 
 func (c *Client) ListEntitiesWithProperties(ctx context.Context) ([]store.Entity, error) {
+	visible, visibleArgs := c.visibleClause("entities", 1)
 	query := `
-SELECT name, entity_type, layer, source_file, source_hash, tags, properties, body
+SELECT name, entity_type, layer, source_file, COALESCE(source_anchor, ''), source_hash, tags, COALESCE(aliases, '{}'::text[]), properties, body, COALESCE(defaulted_properties, '{}'::text[]), COALESCE(secret, '')
 FROM entities
 WHERE is_placeholder = FALSE
+  AND ` + visible + `
 ORDER BY name
 `
 
-	rows, err := c.pool.Query(ctx, query)
+	rows, err := c.conn().Query(ctx, query, visibleArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("listing entities with properties: %w", err)
 	}
@@ -186,10 +280,14 @@ ORDER BY name
 			&e.EntityType,
 			&e.Layer,
 			&e.SourceFile,
+			&e.SourceAnchor,
 			&e.SourceHash,
 			&e.Tags,
+			&e.Aliases,
 			&propsBytes,
 			&e.Body,
+			&e.Defaulted,
+			&e.Secret,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning entity: %w", err)
@@ -205,6 +303,9 @@ ORDER BY name
 		if e.Tags == nil {
 			e.Tags = []string{}
 		}
+		if c.visibility != nil {
+			e.Secret = ""
+		}
 		entities = append(entities, e)
 	}
 
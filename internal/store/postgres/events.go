@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"lorecraft/internal/store"
+)
+
+// upsertEvent keeps the events row for an entity in sync with its properties.
+// Only entities of type "event", or a type extending it, have a row; any other
+// type clears it so that an entity whose type changed does not linger on the
+// timeline. Rows for deleted entities are removed by the ON DELETE CASCADE on
+// events.entity_id.
+func upsertEvent(ctx context.Context, tx pgx.Tx, entityID int64, e store.EntityInput) error {
+	event, err := isEventType(ctx, tx, e.EntityType)
+	if err != nil {
+		return err
+	}
+	if !event {
+		if _, err := tx.Exec(ctx, "DELETE FROM events WHERE entity_id = $1", entityID); err != nil {
+			return fmt.Errorf("removing event: %w", err)
+		}
+		return nil
+	}
+
+	consequences := "[]"
+	if raw, ok := e.Properties["consequences_json"].(string); ok && strings.TrimSpace(raw) != "" {
+		consequences = raw
+	}
+
+	query := `
+INSERT INTO events (entity_id, layer, session, date_in_world, consequences)
+VALUES ($1, $2, $3, $4, $5::jsonb)
+ON CONFLICT (entity_id) DO UPDATE SET
+    layer = EXCLUDED.layer,
+    session = EXCLUDED.session,
+    date_in_world = EXCLUDED.date_in_world,
+    consequences = EXCLUDED.consequences
+`
+
+	_, err = tx.Exec(ctx, query,
+		entityID,
+		e.Layer,
+		store.EventSession(e.Properties["session"]),
+		store.EventDate(e.Properties["date_in_world"]),
+		consequences,
+	)
+	if err != nil {
+		return fmt.Errorf("upserting event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"lorecraft/internal/store"
+)
+
+func (c *Client) GetSchemaState(ctx context.Context) (*store.SchemaState, error) {
+	// schema diff reads the state without EnsureSchema, so the table may not
+	// exist yet.
+	var exists bool
+	if err := c.conn().QueryRow(ctx, "SELECT to_regclass('schema_state') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("query schema state: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var state store.SchemaState
+	var source string
+	err := c.conn().QueryRow(ctx, "SELECT fingerprint, source FROM schema_state WHERE id = 1").Scan(&state.Fingerprint, &source)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query schema state: %w", err)
+	}
+	state.Source = []byte(source)
+	return &state, nil
+}
+
+func (c *Client) SaveSchemaState(ctx context.Context, state store.SchemaState) error {
+	_, err := c.conn().Exec(ctx, `
+INSERT INTO schema_state (id, fingerprint, source, ingested_at)
+VALUES (1, $1, $2, now())
+ON CONFLICT (id) DO UPDATE SET
+    fingerprint = excluded.fingerprint,
+    source = excluded.source,
+    ingested_at = excluded.ingested_at
+`, state.Fingerprint, string(state.Source))
+	if err != nil {
+		return fmt.Errorf("saving schema state: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) RenameProperty(ctx context.Context, entityType, from, to string) (int64, error) {
+	query := `
+UPDATE entities
+SET properties = (properties - $2) || jsonb_build_object($3::text, properties -> $2),
+    defaulted_properties = array_replace(defaulted_properties, $2, $3)
+WHERE lower(entity_type) = lower($1)
+  AND is_placeholder = FALSE
+  AND properties ? $2
+  AND NOT properties ? $3
+`
+
+	tag, err := c.conn().Exec(ctx, query, entityType, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("renaming property %s of %s: %w", from, entityType, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (c *Client) RenameRelationshipType(ctx context.Context, from, to string) (int64, error) {
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// An edge that already exists under the new name makes the old one redundant.
+	_, err = tx.Exec(ctx, `
+DELETE FROM edges
+WHERE upper(rel_type) = upper($1)
+  AND EXISTS (
+    SELECT 1 FROM edges other
+    WHERE other.src_id = edges.src_id
+      AND other.dst_id = edges.dst_id
+      AND upper(other.rel_type) = upper($2)
+  )
+`, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("removing duplicate edges: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, "UPDATE edges SET rel_type = $1 WHERE upper(rel_type) = upper($2)", to, from)
+	if err != nil {
+		return 0, fmt.Errorf("renaming relationship %s: %w", from, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
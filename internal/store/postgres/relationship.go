@@ -2,10 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
+
 	"lorecraft/internal/store"
 )
 
@@ -16,7 +19,7 @@ func (c *Client) UpsertRelationship(ctx context.Context, fromName, fromLayer, to
 		return fmt.Errorf("invalid relationship type: %s", relType)
 	}
 
-	tx, err := c.pool.Begin(ctx)
+	tx, err := c.begin(ctx)
 	if err != nil {
 		return fmt.Errorf("beginning transaction: %w", err)
 	}
@@ -31,14 +34,29 @@ func (c *Client) UpsertRelationship(ctx context.Context, fromName, fromLayer, to
 		return fmt.Errorf("finding source entity: %w", err)
 	}
 
+	// A target named by an alias resolves to its entity unless some entity in
+	// the layer carries that name; otherwise a placeholder stands in for it.
 	var dstID int64
-	err = tx.QueryRow(ctx,
-		`INSERT INTO entities (name, name_normalized, entity_type, layer, is_placeholder)
+	toNormalized := strings.ToLower(toName)
+	err = tx.QueryRow(ctx, `
+SELECT id FROM entities
+WHERE layer = $1
+  AND is_placeholder = FALSE
+  AND $2 = ANY(aliases_normalized)
+  AND NOT EXISTS (SELECT 1 FROM entities named WHERE named.name_normalized = $2 AND named.layer = $1)
+ORDER BY id
+LIMIT 1`,
+		toLayer, toNormalized,
+	).Scan(&dstID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx,
+			`INSERT INTO entities (name, name_normalized, entity_type, layer, is_placeholder)
 VALUES ($1, $2, '', $3, TRUE)
 ON CONFLICT (name_normalized, layer) DO UPDATE SET name = entities.name
 RETURNING id`,
-		toName, strings.ToLower(toName), toLayer,
-	).Scan(&dstID)
+			toName, toNormalized, toLayer,
+		).Scan(&dstID)
+	}
 	if err != nil {
 		return fmt.Errorf("upserting target entity: %w", err)
 	}
@@ -80,15 +98,17 @@ func (c *Client) GetRelationships(ctx context.Context, name, relType, direction
 	// At depth 1, direction is unambiguous: edges from the starting node are "outgoing",
 	// edges to the starting node are "incoming". At depth > 1, the frontier contains
 	// intermediate nodes, and edges between frontier nodes may be traversed from either
-	// direction. The direction assigned to multi-hop relationships is based on the first
-	// frontier node matched in the result set, which may not match user intuition.
+	// direction. The direction assigned to multi-hop relationships is based on the
+	// frontier node that is the edge's source, which may not match user intuition.
 	// For "both" direction at depth > 1, consider results as undirected or use depth 1.
 
-	var startID int64
-	err := c.pool.QueryRow(ctx,
-		"SELECT id FROM entities WHERE name_normalized = $1",
-		strings.ToLower(name),
-	).Scan(&startID)
+	relTypes, err := c.loadRelationshipTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storedType := relTypes.StoredType(relType)
+
+	startID, err := c.entityID(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("finding start entity: %w", err)
 	}
@@ -98,35 +118,11 @@ func (c *Client) GetRelationships(ctx context.Context, name, relType, direction
 	frontier := []int64{startID}
 	var results []store.Relationship
 
-	for currentDepth := 1; currentDepth <= depth; currentDepth++ {
-		if len(frontier) == 0 {
-			break
-		}
-
-		var query string
-		switch direction {
-		case "outgoing":
-			query = `
-SELECT e.src_id, e.dst_id, e.rel_type,
-       s.name AS src_name, s.entity_type AS src_type, s.layer AS src_layer,
-       d.name AS dst_name, d.entity_type AS dst_type, d.layer AS dst_layer
-FROM edges e
-JOIN entities s ON e.src_id = s.id
-JOIN entities d ON e.dst_id = d.id
-WHERE e.src_id = ANY($1)
-  AND ($2 = '' OR e.rel_type = $2)`
-		case "incoming":
-			query = `
-SELECT e.src_id, e.dst_id, e.rel_type,
-       s.name AS src_name, s.entity_type AS src_type, s.layer AS src_layer,
-       d.name AS dst_name, d.entity_type AS dst_type, d.layer AS dst_layer
-FROM edges e
-JOIN entities s ON e.src_id = s.id
-JOIN entities d ON e.dst_id = d.id
-WHERE e.dst_id = ANY($1)
-  AND ($2 = '' OR e.rel_type = $2)`
-		case "both":
-			query = `
+	// Edges are fetched in both directions because inverse and symmetric types
+	// can match an edge against its stored direction; Match decides.
+	srcVisible, srcArgs := c.visibleClause("s", 3)
+	dstVisible, dstArgs := c.visibleClause("d", 3+len(srcArgs))
+	query := `
 SELECT e.src_id, e.dst_id, e.rel_type,
        s.name AS src_name, s.entity_type AS src_type, s.layer AS src_layer,
        d.name AS dst_name, d.entity_type AS dst_type, d.layer AS dst_layer
@@ -134,14 +130,26 @@ FROM edges e
 JOIN entities s ON e.src_id = s.id
 JOIN entities d ON e.dst_id = d.id
 WHERE (e.src_id = ANY($1) OR e.dst_id = ANY($1))
-  AND ($2 = '' OR e.rel_type = $2)`
+  AND ($2 = '' OR e.rel_type = $2)
+  AND ` + srcVisible + `
+  AND ` + dstVisible
+
+	for currentDepth := 1; currentDepth <= depth; currentDepth++ {
+		if len(frontier) == 0 {
+			break
 		}
 
-		rows, err := c.pool.Query(ctx, query, frontier, relType)
+		args := append([]any{frontier, storedType}, srcArgs...)
+		args = append(args, dstArgs...)
+		rows, err := c.conn().Query(ctx, query, args...)
 		if err != nil {
 			return nil, fmt.Errorf("querying relationships: %w", err)
 		}
-		defer rows.Close()
+
+		inFrontier := make(map[int64]bool, len(frontier))
+		for _, id := range frontier {
+			inFrontier[id] = true
+		}
 
 		var newFrontier []int64
 		for rows.Next() {
@@ -155,6 +163,7 @@ WHERE (e.src_id = ANY($1) OR e.dst_id = ANY($1))
 				&rel.To.Name, &dstType, &dstLayer,
 			)
 			if err != nil {
+				rows.Close()
 				return nil, fmt.Errorf("scanning relationship: %w", err)
 			}
 
@@ -163,36 +172,31 @@ WHERE (e.src_id = ANY($1) OR e.dst_id = ANY($1))
 			rel.To.EntityType = dstType
 			rel.To.Layer = dstLayer
 
-			var otherID int64
-			var isFromFrontier bool
-			for _, fid := range frontier {
-				if fid == srcID {
-					otherID = dstID
-					isFromFrontier = true
-					break
-				} else if fid == dstID {
-					otherID = srcID
-					isFromFrontier = false
-					break
-				}
+			otherID := dstID
+			natural := "outgoing"
+			if !inFrontier[srcID] {
+				otherID = srcID
+				natural = "incoming"
+				rel.From, rel.To = rel.To, rel.From
 			}
 
 			if visited[otherID] {
 				continue
 			}
 
-			if isFromFrontier {
-				rel.Direction = "outgoing"
-			} else {
-				rel.Direction = "incoming"
-				rel.From, rel.To = rel.To, rel.From
+			label, relDirection, ok := relTypes.Match(rel.Type, natural, relType, direction)
+			if !ok {
+				continue
 			}
 
+			rel.Type = label
+			rel.Direction = relDirection
 			rel.Depth = currentDepth
 			results = append(results, rel)
 			newFrontier = append(newFrontier, otherID)
 			visited[otherID] = true
 		}
+		rows.Close()
 
 		if err := rows.Err(); err != nil {
 			return nil, fmt.Errorf("iterating relationship rows: %w", err)
@@ -207,3 +211,26 @@ WHERE (e.src_id = ANY($1) OR e.dst_id = ANY($1))
 
 	return results, nil
 }
+
+func (c *Client) loadRelationshipTypes(ctx context.Context) (store.RelationshipTypes, error) {
+	rows, err := c.conn().Query(ctx, "SELECT name, inverse, symmetric FROM relationship_types")
+	if err != nil {
+		return nil, fmt.Errorf("loading relationship types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []store.RelationshipType
+	for rows.Next() {
+		var rel store.RelationshipType
+		if err := rows.Scan(&rel.Name, &rel.Inverse, &rel.Symmetric); err != nil {
+			return nil, fmt.Errorf("scanning relationship type: %w", err)
+		}
+		types = append(types, rel)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating relationship types: %w", err)
+	}
+
+	return store.NewRelationshipTypes(types), nil
+}
@@ -3,6 +3,9 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
 
 	"lorecraft/internal/config"
 )
@@ -33,6 +36,12 @@ CREATE TABLE IF NOT EXISTS entities (
 );
 
 ALTER TABLE entities ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+ALTER TABLE entities ADD COLUMN IF NOT EXISTS defaulted_properties TEXT[] DEFAULT '{}';
+ALTER TABLE entities ADD COLUMN IF NOT EXISTS secret TEXT DEFAULT '';
+ALTER TABLE entities ADD COLUMN IF NOT EXISTS secret_vector TSVECTOR;
+ALTER TABLE entities ADD COLUMN IF NOT EXISTS aliases TEXT[] DEFAULT '{}';
+ALTER TABLE entities ADD COLUMN IF NOT EXISTS aliases_normalized TEXT[] DEFAULT '{}';
+ALTER TABLE entities ADD COLUMN IF NOT EXISTS source_anchor TEXT DEFAULT '';
 
 CREATE TABLE IF NOT EXISTS edges (
     id       BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
@@ -52,6 +61,25 @@ CREATE TABLE IF NOT EXISTS events (
     CONSTRAINT uq_event_entity UNIQUE (entity_id)
 );
 
+CREATE TABLE IF NOT EXISTS relationship_types (
+    name      TEXT PRIMARY KEY,
+    inverse   TEXT DEFAULT '',
+    symmetric BOOLEAN DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS entity_type_ancestors (
+    entity_type TEXT NOT NULL,
+    ancestor    TEXT NOT NULL,
+    PRIMARY KEY (entity_type, ancestor)
+);
+
+CREATE TABLE IF NOT EXISTS schema_state (
+    id          INTEGER PRIMARY KEY CHECK (id = 1),
+    fingerprint TEXT NOT NULL,
+    source      TEXT NOT NULL,
+    ingested_at TIMESTAMPTZ DEFAULT now()
+);
+
 CREATE INDEX IF NOT EXISTS idx_entities_search ON entities USING GIN (search_vector);
 CREATE INDEX IF NOT EXISTS idx_entities_layer ON entities (layer);
 CREATE INDEX IF NOT EXISTS idx_entities_type ON entities (entity_type);
@@ -60,6 +88,7 @@ CREATE INDEX IF NOT EXISTS idx_entities_type_layer ON entities (entity_type, lay
 CREATE INDEX IF NOT EXISTS idx_entities_name_norm ON entities (name_normalized);
 CREATE INDEX IF NOT EXISTS idx_entities_placeholder ON entities (is_placeholder) WHERE is_placeholder = TRUE;
 CREATE INDEX IF NOT EXISTS idx_entities_tags ON entities USING GIN (tags);
+CREATE INDEX IF NOT EXISTS idx_entities_aliases ON entities USING GIN (aliases_normalized);
 CREATE INDEX IF NOT EXISTS idx_edges_src ON edges (src_id);
 CREATE INDEX IF NOT EXISTS idx_edges_dst ON edges (dst_id);
 CREATE INDEX IF NOT EXISTS idx_edges_type ON edges (rel_type);
@@ -68,9 +97,122 @@ CREATE INDEX IF NOT EXISTS idx_edges_dst_type ON edges (dst_id, rel_type);
 CREATE INDEX IF NOT EXISTS idx_events_layer ON events (layer);
 CREATE INDEX IF NOT EXISTS idx_events_layer_session ON events (layer, session);
 `
-	_, err := c.pool.Exec(ctx, ddl)
+	_, err := c.conn().Exec(ctx, ddl)
 	if err != nil {
 		return fmt.Errorf("ensuring schema: %w", err)
 	}
+	c.ensureTrigram(ctx)
+
+	if schema == nil {
+		return nil
+	}
+
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM relationship_types"); err != nil {
+		return fmt.Errorf("clearing relationship types: %w", err)
+	}
+	for _, rel := range schema.RelationshipTypes {
+		_, err := tx.Exec(ctx,
+			"INSERT INTO relationship_types (name, inverse, symmetric) VALUES ($1, $2, $3)",
+			rel.Name, rel.Inverse, rel.Symmetric,
+		)
+		if err != nil {
+			return fmt.Errorf("storing relationship type %s: %w", rel.Name, err)
+		}
+	}
+
+	// Type filters match subtypes through this table, so it is kept
+	// lower-cased like the names entities are compared by.
+	if _, err := tx.Exec(ctx, "DELETE FROM entity_type_ancestors"); err != nil {
+		return fmt.Errorf("clearing entity type ancestors: %w", err)
+	}
+	for _, entityType := range schema.EntityTypes {
+		for _, ancestor := range entityType.Ancestors() {
+			_, err := tx.Exec(ctx,
+				"INSERT INTO entity_type_ancestors (entity_type, ancestor) VALUES ($1, $2)",
+				strings.ToLower(entityType.Name), strings.ToLower(ancestor),
+			)
+			if err != nil {
+				return fmt.Errorf("storing ancestors of entity type %s: %w", entityType.Name, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing schema transaction: %w", err)
+	}
 	return nil
 }
+
+// trigramState caches whether pg_trgm is installed. Clients derived from one
+// New share it, so the extension is looked up and reported at most once.
+type trigramState struct {
+	mu        sync.Mutex
+	known     bool
+	available bool
+	reported  bool
+}
+
+// ensureTrigram installs pg_trgm and the name index that speeds up
+// SuggestEntities. Managed databases and roles without CREATE often cannot
+// install extensions, so failure is tolerated: it runs in its own
+// transaction, or savepoint, and SuggestEntities falls back to ranking in Go.
+func (c *Client) ensureTrigram(ctx context.Context) {
+	err := c.installTrigram(ctx)
+
+	c.trigram.mu.Lock()
+	defer c.trigram.mu.Unlock()
+	if err != nil && !c.trigram.reported {
+		log.Printf("pg_trgm is unavailable, name suggestions are ranked without it: %v", err)
+		c.trigram.reported = true
+	}
+	// Inside a unit of work the install may still be rolled back, so the
+	// result is only cached once it is committed.
+	if c.tx == nil {
+		c.trigram.known = true
+		c.trigram.available = err == nil
+	}
+}
+
+func (c *Client) installTrigram(ctx context.Context) error {
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	ddl := `
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_entities_name_trgm ON entities USING GIN (name_normalized gin_trgm_ops);
+`
+	if _, err := tx.Exec(ctx, ddl); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// hasTrigram reports whether pg_trgm is installed in the database, looking it
+// up only when EnsureSchema has not already found out.
+func (c *Client) hasTrigram(ctx context.Context) (bool, error) {
+	c.trigram.mu.Lock()
+	defer c.trigram.mu.Unlock()
+	if c.trigram.known {
+		return c.trigram.available, nil
+	}
+
+	var installed bool
+	err := c.conn().QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm')").Scan(&installed)
+	if err != nil {
+		return false, fmt.Errorf("checking for pg_trgm: %w", err)
+	}
+	if c.tx == nil {
+		c.trigram.known = true
+		c.trigram.available = installed
+	}
+	return installed, nil
+}
@@ -13,23 +13,40 @@ func (c *Client) Search(ctx context.Context, query, layer, entityType string) ([
 		return nil, fmt.Errorf("query must not be empty")
 	}
 
+	// Secrets are matched and quoted only for unrestricted readers; a body
+	// snippet is preferred unless only the secret matched.
+	vector := "search_vector"
+	secretSnippet := ""
+	if c.visibility == nil {
+		vector = "(search_vector || COALESCE(secret_vector, ''::tsvector))"
+		secretSnippet = `
+    WHEN secret <> '' AND NOT to_tsvector('english', body) @@ websearch_to_tsquery('english', $1)
+        AND secret_vector @@ websearch_to_tsquery('english', $1) THEN
+        ts_headline('english', secret, websearch_to_tsquery('english', $1),
+            'MaxFragments=2, MaxWords=40, MinWords=20, StartSel=**, StopSel=**')`
+	}
+
+	visible, visibleArgs := c.visibleClause("entities", 4)
 	sql := `
 SELECT name, entity_type, layer, tags,
-    ts_rank(search_vector, websearch_to_tsquery('english', $1)) AS score,
-    CASE WHEN body <> '' THEN
+    ts_rank(` + vector + `, websearch_to_tsquery('english', $1)) AS score,
+    CASE` + secretSnippet + `
+    WHEN body <> '' THEN
         ts_headline('english', body, websearch_to_tsquery('english', $1),
             'MaxFragments=2, MaxWords=40, MinWords=20, StartSel=**, StopSel=**')
     ELSE '' END AS snippet
 FROM entities
-WHERE search_vector @@ websearch_to_tsquery('english', $1)
+WHERE ` + vector + ` @@ websearch_to_tsquery('english', $1)
   AND ($2 = '' OR layer = $2)
-  AND ($3 = '' OR entity_type = $3)
+  AND ` + typeClause("entities", 3) + `
   AND is_placeholder = FALSE
+  AND ` + visible + `
 ORDER BY score DESC, name ASC
 LIMIT 50
 `
 
-	rows, err := c.pool.Query(ctx, sql, query, layer, entityType)
+	args := append([]any{query, layer, entityType}, visibleArgs...)
+	rows, err := c.conn().Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("searching entities: %w", err)
 	}
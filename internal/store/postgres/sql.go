@@ -7,6 +7,10 @@ import (
 )
 
 func (c *Client) RunSQL(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
+	if c.visibility != nil {
+		return nil, fmt.Errorf("raw SQL is not available to a restricted audience")
+	}
+
 	args := make([]any, 0, len(params))
 	for i := 1; i <= len(params); i++ {
 		key := strconv.Itoa(i)
@@ -15,7 +19,7 @@ func (c *Client) RunSQL(ctx context.Context, query string, params map[string]any
 		}
 	}
 
-	rows, err := c.pool.Query(ctx, query, args...)
+	rows, err := c.conn().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("running sql: %w", err)
 	}
@@ -54,6 +54,8 @@ func (c *Client) GetTimeline(ctx context.Context, layer, entity string, fromSess
 
 	entityNormalized := strings.ToLower(strings.TrimSpace(entity))
 
+	targetVisible, targetArgs := c.visibleClause("t", 5)
+	eventVisible, eventArgs := c.visibleClause("e_ent", 5+len(targetArgs))
 	query := `
 SELECT e_ent.id, e_ent.name, ev.layer, ev.session, ev.date_in_world, ev.consequences
 FROM events ev
@@ -65,13 +67,17 @@ WHERE ev.layer = $1
       WHERE ea.src_id = e_ent.id
         AND ea.rel_type IN ('AFFECTS', 'INVOLVES')
         AND t.name_normalized = $2
+        AND ` + targetVisible + `
   ))
   AND ($3 = 0 OR ev.session >= $3)
   AND ($4 = 0 OR ev.session <= $4)
+  AND ` + eventVisible + `
 ORDER BY ev.session ASC, ev.id ASC
 `
 
-	rows, err := c.pool.Query(ctx, query, layer, entityNormalized, fromSession, toSession)
+	args := append([]any{layer, entityNormalized, fromSession, toSession}, targetArgs...)
+	args = append(args, eventArgs...)
+	rows, err := c.conn().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get timeline: %w", err)
 	}
@@ -118,10 +124,12 @@ ORDER BY ev.session ASC, ev.id ASC
 }
 
 func (c *Client) fetchEntityProperties(ctx context.Context, name, layer string) (map[string]any, error) {
-	query := `SELECT properties FROM entities WHERE name_normalized = $1 AND layer = $2`
+	visible, visibleArgs := c.visibleClause("entities", 3)
+	query := `SELECT properties FROM entities WHERE name_normalized = $1 AND layer = $2 AND ` + visible
 
 	var propsBytes []byte
-	err := c.pool.QueryRow(ctx, query, strings.ToLower(name), layer).Scan(&propsBytes)
+	args := append([]any{strings.ToLower(name), layer}, visibleArgs...)
+	err := c.conn().QueryRow(ctx, query, args...).Scan(&propsBytes)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -143,6 +151,7 @@ func (c *Client) fetchEntityProperties(ctx context.Context, name, layer string)
 }
 
 func (c *Client) fetchEventsForEntity(ctx context.Context, name, layer string) ([]store.Event, error) {
+	visible, visibleArgs := c.visibleClause("e_ent", 3)
 	query := `
 SELECT e_ent.id, e_ent.name, ev.layer, ev.session, ev.date_in_world, ev.consequences
 FROM events ev
@@ -150,10 +159,12 @@ JOIN entities e_ent ON ev.entity_id = e_ent.id
 JOIN edges ed ON ed.src_id = e_ent.id AND ed.rel_type = 'AFFECTS'
 JOIN entities target ON ed.dst_id = target.id
 WHERE target.name_normalized = $1 AND ev.layer = $2
+  AND ` + visible + `
 ORDER BY ev.session ASC, ev.id ASC
 `
 
-	rows, err := c.pool.Query(ctx, query, strings.ToLower(name), layer)
+	args := append([]any{strings.ToLower(name), layer}, visibleArgs...)
+	rows, err := c.conn().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("fetching events: %w", err)
 	}
@@ -200,13 +211,14 @@ ORDER BY ev.session ASC, ev.id ASC
 }
 
 func (c *Client) fetchEventParticipants(ctx context.Context, entityID int64) ([]string, error) {
+	visible, visibleArgs := c.visibleClause("p", 2)
 	query := `
 SELECT p.name FROM edges ep
 JOIN entities p ON ep.dst_id = p.id
 WHERE ep.src_id = $1 AND ep.rel_type = 'INVOLVES'
-`
+  AND ` + visible
 
-	rows, err := c.pool.Query(ctx, query, entityID)
+	rows, err := c.conn().Query(ctx, query, append([]any{entityID}, visibleArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("fetching participants: %w", err)
 	}
@@ -228,13 +240,14 @@ WHERE ep.src_id = $1 AND ep.rel_type = 'INVOLVES'
 }
 
 func (c *Client) fetchEventLocations(ctx context.Context, entityID int64) ([]string, error) {
+	visible, visibleArgs := c.visibleClause("l", 2)
 	query := `
 SELECT l.name FROM edges el
 JOIN entities l ON el.dst_id = l.id
 WHERE el.src_id = $1 AND el.rel_type = 'OCCURS_IN'
-`
+  AND ` + visible
 
-	rows, err := c.pool.Query(ctx, query, entityID)
+	rows, err := c.conn().Query(ctx, query, append([]any{entityID}, visibleArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("fetching locations: %w", err)
 	}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lorecraft/internal/store"
+)
+
+// SuggestEntities ranks names and aliases with pg_trgm. word_similarity lets
+// a partial name such as "Lysa Quint" match "Bureau Director Lysa Quent".
+// Without the extension the names are ranked in Go, as the SQLite store does.
+func (c *Client) SuggestEntities(ctx context.Context, name string, limit int) ([]store.EntitySuggestion, error) {
+	if limit <= 0 {
+		limit = store.MaxSuggestions
+	}
+	trigram, err := c.hasTrigram(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !trigram {
+		return c.rankSuggestions(ctx, name, limit)
+	}
+
+	visible, visibleArgs := c.visibleClause("e", 4)
+	query := `
+SELECT name, entity_type, layer, alias, score
+FROM (
+    SELECT DISTINCT ON (e.id) e.name, e.entity_type, e.layer, candidates.alias, candidates.score
+    FROM entities e
+    CROSS JOIN LATERAL (
+        SELECT '' AS alias, GREATEST(similarity(e.name_normalized, $1), word_similarity($1, e.name_normalized)) AS score
+        UNION ALL
+        SELECT alias, GREATEST(similarity(lower(alias), $1), word_similarity($1, lower(alias)))
+        FROM unnest(COALESCE(e.aliases, '{}'::text[])) AS alias
+    ) candidates
+    WHERE e.is_placeholder = FALSE
+      AND candidates.score >= $2
+      AND ` + visible + `
+    ORDER BY e.id, candidates.score DESC
+) best
+ORDER BY score DESC, lower(name)
+LIMIT $3
+`
+
+	args := append([]any{strings.ToLower(name), store.SuggestionThreshold, limit}, visibleArgs...)
+	rows, err := c.conn().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("suggesting entities: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []store.EntitySuggestion{}
+	for rows.Next() {
+		var s store.EntitySuggestion
+		var score float32
+		if err := rows.Scan(&s.Name, &s.EntityType, &s.Layer, &s.Alias, &score); err != nil {
+			return nil, fmt.Errorf("scanning suggestion: %w", err)
+		}
+		s.Score = float64(score)
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating suggestions: %w", err)
+	}
+	return suggestions, nil
+}
+
+func (c *Client) rankSuggestions(ctx context.Context, name string, limit int) ([]store.EntitySuggestion, error) {
+	visible, visibleArgs := c.visibleClause("e", 1)
+	query := `
+SELECT e.name, e.entity_type, e.layer, COALESCE(e.aliases, '{}'::text[])
+FROM entities e
+WHERE e.is_placeholder = FALSE
+  AND ` + visible
+
+	rows, err := c.conn().Query(ctx, query, visibleArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("listing suggestion candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []store.SuggestionCandidate
+	for rows.Next() {
+		var candidate store.SuggestionCandidate
+		if err := rows.Scan(&candidate.Ref.Name, &candidate.Ref.EntityType, &candidate.Ref.Layer, &candidate.Aliases); err != nil {
+			return nil, fmt.Errorf("scanning suggestion candidate: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating suggestion candidates: %w", err)
+	}
+
+	return store.RankSuggestions(name, candidates, limit), nil
+}
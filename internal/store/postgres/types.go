@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// typeClause matches the entities of alias whose type, bound to parameter
+// param, is the one given or extends it, using the ancestors stored by
+// EnsureSchema. An empty type matches every entity.
+func typeClause(alias string, param int) string {
+	return fmt.Sprintf("($%[2]d = '' OR %[1]s.entity_type = $%[2]d OR lower(%[1]s.entity_type) IN (SELECT entity_type FROM entity_type_ancestors WHERE ancestor = lower($%[2]d)))", alias, param)
+}
+
+// isEventType reports whether entityType is "event" or extends it.
+func isEventType(ctx context.Context, tx querier, entityType string) (bool, error) {
+	if strings.EqualFold(entityType, "event") {
+		return true, nil
+	}
+	var exists bool
+	err := tx.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM entity_type_ancestors WHERE entity_type = lower($1) AND ancestor = 'event')",
+		entityType,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("checking ancestors of %s: %w", entityType, err)
+	}
+	return exists, nil
+}
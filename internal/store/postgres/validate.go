@@ -9,7 +9,7 @@ import (
 func (c *Client) ListDanglingPlaceholders(ctx context.Context) ([]store.EntitySummary, error) {
 	query := `SELECT name, entity_type, layer, tags FROM entities WHERE is_placeholder = TRUE`
 
-	rows, err := c.pool.Query(ctx, query)
+	rows, err := c.conn().Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +44,7 @@ WHERE NOT EXISTS (SELECT 1 FROM edges WHERE src_id = e.id OR dst_id = e.id)
   AND e.is_placeholder = FALSE
 `
 
-	rows, err := c.pool.Query(ctx, query)
+	rows, err := c.conn().Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -76,3 +76,48 @@ func (c *Client) ListCrossLayerViolations(ctx context.Context) ([]store.EntitySu
 	// TODO: Implement cross-layer violation detection once event/campaign layer logic is finalized
 	return []store.EntitySummary{}, nil
 }
+
+func (c *Client) ListEdges(ctx context.Context) ([]store.Edge, error) {
+	srcVisible, srcArgs := c.visibleClause("s", 1)
+	dstVisible, dstArgs := c.visibleClause("d", 1+len(srcArgs))
+	query := `
+SELECT s.name, s.entity_type, s.layer, COALESCE(s.source_file, ''),
+       d.name, d.entity_type, d.layer, d.is_placeholder,
+       e.rel_type
+FROM edges e
+JOIN entities s ON e.src_id = s.id
+JOIN entities d ON e.dst_id = d.id
+WHERE ` + srcVisible + `
+  AND ` + dstVisible + `
+ORDER BY s.name, e.rel_type, d.name
+`
+
+	rows, err := c.conn().Query(ctx, query, append(srcArgs, dstArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []store.Edge
+	for rows.Next() {
+		var edge store.Edge
+		err := rows.Scan(
+			&edge.From.Name, &edge.From.EntityType, &edge.From.Layer, &edge.SourceFile,
+			&edge.To.Name, &edge.To.EntityType, &edge.To.Layer, &edge.Placeholder,
+			&edge.Type,
+		)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if edges == nil {
+		edges = []store.Edge{}
+	}
+	return edges, nil
+}
@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"lorecraft/internal/store"
+)
+
+func (c *Client) WithVisibility(v *store.Visibility) store.Store {
+	view := *c
+	view.visibility = v
+	return &view
+}
+
+// visibleClause returns a condition on the entities table aliased as alias that
+// excludes entities hidden from the client's audience, numbering its two
+// parameters from next, and their arguments. It is always true when no
+// visibility filter is set.
+func (c *Client) visibleClause(alias string, next int) (string, []any) {
+	if c.visibility == nil || len(c.visibility.Hidden) == 0 {
+		return "TRUE", nil
+	}
+
+	hidden := make([]string, len(c.visibility.Hidden))
+	for i, value := range c.visibility.Hidden {
+		hidden[i] = strings.ToLower(value)
+	}
+	clause := fmt.Sprintf("NOT (lower(COALESCE(%s.properties->>$%d, '')) = ANY($%d))", alias, next, next+1)
+	return clause, []any{c.visibility.Property, hidden}
+}
@@ -0,0 +1,72 @@
+package store
+
+import "strings"
+
+// RelationshipType mirrors a schema relationship declaration as persisted by
+// EnsureSchema, so traversal can honour inverses without loading schema.yaml.
+type RelationshipType struct {
+	Name      string
+	Inverse   string
+	Symmetric bool
+}
+
+// RelationshipTypes indexes declarations by upper-cased name.
+type RelationshipTypes map[string]RelationshipType
+
+func NewRelationshipTypes(types []RelationshipType) RelationshipTypes {
+	index := make(RelationshipTypes, len(types))
+	for _, rel := range types {
+		index[strings.ToUpper(rel.Name)] = rel
+	}
+	return index
+}
+
+// StoredType maps a requested relationship type onto the type edges are stored
+// under. Inverse names resolve to their forward type; anything else, including
+// undeclared types, is returned unchanged.
+func (t RelationshipTypes) StoredType(requested string) string {
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return ""
+	}
+	if rel, ok := t[strings.ToUpper(requested)]; ok {
+		return rel.Name
+	}
+	for _, rel := range t {
+		if rel.Inverse != "" && strings.EqualFold(rel.Inverse, requested) {
+			return rel.Name
+		}
+	}
+	return requested
+}
+
+// Match decides whether a stored edge, seen from a traversal node for which the
+// edge is naturally "outgoing" or "incoming", answers a request for the given
+// type and direction. It returns the type and direction to report: inverse
+// requests are labelled with the inverse name and flipped direction, and
+// symmetric edges match in either direction.
+func (t RelationshipTypes) Match(storedType, natural, requestedType, requestedDirection string) (string, string, bool) {
+	rel := t[strings.ToUpper(storedType)]
+	wants := func(direction string) bool {
+		return requestedDirection == "both" || requestedDirection == direction
+	}
+	opposite := "incoming"
+	if natural == "incoming" {
+		opposite = "outgoing"
+	}
+
+	switch {
+	case requestedType == "" || strings.EqualFold(requestedType, storedType):
+		if wants(natural) {
+			return storedType, natural, true
+		}
+		if rel.Symmetric {
+			return storedType, opposite, true
+		}
+	case rel.Inverse != "" && strings.EqualFold(requestedType, rel.Inverse):
+		if wants(opposite) {
+			return rel.Inverse, opposite, true
+		}
+	}
+	return "", "", false
+}
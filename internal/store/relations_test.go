@@ -0,0 +1,60 @@
+package store
+
+import "testing"
+
+func TestRelationshipTypes_StoredType(t *testing.T) {
+	types := NewRelationshipTypes([]RelationshipType{
+		{Name: "MEMBER_OF", Inverse: "HAS_MEMBER"},
+		{Name: "ALLIED_WITH", Symmetric: true},
+	})
+
+	cases := map[string]string{
+		"":            "",
+		"MEMBER_OF":   "MEMBER_OF",
+		"HAS_MEMBER":  "MEMBER_OF",
+		"ALLIED_WITH": "ALLIED_WITH",
+		"UNDECLARED":  "UNDECLARED",
+	}
+	for requested, expected := range cases {
+		if got := types.StoredType(requested); got != expected {
+			t.Errorf("StoredType(%q) = %q, want %q", requested, got, expected)
+		}
+	}
+}
+
+func TestRelationshipTypes_Match(t *testing.T) {
+	types := NewRelationshipTypes([]RelationshipType{
+		{Name: "MEMBER_OF", Inverse: "HAS_MEMBER"},
+		{Name: "ALLIED_WITH", Symmetric: true},
+	})
+
+	tests := []struct {
+		name          string
+		stored        string
+		natural       string
+		requestedType string
+		requestedDir  string
+		wantType      string
+		wantDir       string
+		wantOK        bool
+	}{
+		{name: "unfiltered outgoing", stored: "MEMBER_OF", natural: "outgoing", requestedDir: "outgoing", wantType: "MEMBER_OF", wantDir: "outgoing", wantOK: true},
+		{name: "unfiltered wrong direction", stored: "MEMBER_OF", natural: "incoming", requestedDir: "outgoing"},
+		{name: "forward incoming", stored: "MEMBER_OF", natural: "incoming", requestedType: "MEMBER_OF", requestedDir: "incoming", wantType: "MEMBER_OF", wantDir: "incoming", wantOK: true},
+		{name: "inverse outgoing", stored: "MEMBER_OF", natural: "incoming", requestedType: "HAS_MEMBER", requestedDir: "outgoing", wantType: "HAS_MEMBER", wantDir: "outgoing", wantOK: true},
+		{name: "inverse both", stored: "MEMBER_OF", natural: "outgoing", requestedType: "HAS_MEMBER", requestedDir: "both", wantType: "HAS_MEMBER", wantDir: "incoming", wantOK: true},
+		{name: "inverse wrong direction", stored: "MEMBER_OF", natural: "outgoing", requestedType: "HAS_MEMBER", requestedDir: "outgoing"},
+		{name: "symmetric reversed", stored: "ALLIED_WITH", natural: "incoming", requestedType: "ALLIED_WITH", requestedDir: "outgoing", wantType: "ALLIED_WITH", wantDir: "outgoing", wantOK: true},
+		{name: "symmetric unfiltered", stored: "ALLIED_WITH", natural: "outgoing", requestedDir: "incoming", wantType: "ALLIED_WITH", wantDir: "incoming", wantOK: true},
+		{name: "other type", stored: "ALLIED_WITH", natural: "outgoing", requestedType: "MEMBER_OF", requestedDir: "both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotDir, ok := types.Match(tt.stored, tt.natural, tt.requestedType, tt.requestedDir)
+			if ok != tt.wantOK || gotType != tt.wantType || gotDir != tt.wantDir {
+				t.Fatalf("Match() = (%q, %q, %v), want (%q, %q, %v)", gotType, gotDir, ok, tt.wantType, tt.wantDir, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lorecraft/internal/store"
+)
+
+// aliasMatch is a condition on the entities table aliased as alias that holds
+// when one of the entity's aliases equals the single normalized argument.
+func aliasMatch(alias string) string {
+	return "EXISTS (SELECT 1 FROM json_each(" + alias + ".aliases_normalized) WHERE json_each.value = ?)"
+}
+
+func marshalAliases(aliases []string) ([]byte, []byte, error) {
+	if aliases == nil {
+		aliases = []string{}
+	}
+	normalized := make([]string, len(aliases))
+	for i, alias := range aliases {
+		normalized[i] = strings.ToLower(alias)
+	}
+	aliasesJSON, err := json.Marshal(aliases)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling aliases: %w", err)
+	}
+	normalizedJSON, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling aliases: %w", err)
+	}
+	return aliasesJSON, normalizedJSON, nil
+}
+
+// adoptAliasPlaceholders points edges at placeholders in the entity's layer
+// that are named by one of its aliases at the entity instead, and removes the
+// placeholders. References ingested before the alias was declared then resolve
+// without a full re-ingest, as references by name do.
+func adoptAliasPlaceholders(ctx context.Context, tx querier, entityID int64, layer string, normalizedJSON []byte) error {
+	placeholders := `
+	SELECT id FROM entities
+	WHERE layer = ?
+	  AND is_placeholder = 1
+	  AND name_normalized IN (SELECT value FROM json_each(?))
+	`
+	args := []any{layer, string(normalizedJSON)}
+
+	_, err := tx.ExecContext(ctx, `
+	INSERT OR IGNORE INTO edges (src_id, dst_id, rel_type)
+	SELECT src_id, ?, rel_type FROM edges WHERE dst_id IN (`+placeholders+`)`,
+		append([]any{entityID}, args...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("moving alias references: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM edges WHERE dst_id IN ("+placeholders+")", args...); err != nil {
+		return fmt.Errorf("removing alias placeholder edges: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM entities WHERE id IN ("+placeholders+")", args...); err != nil {
+		return fmt.Errorf("removing alias placeholders: %w", err)
+	}
+	return nil
+}
+
+// entityID resolves a name or alias the audience may see to an entity id,
+// preferring an entity with that name over one with that alias and a defined
+// entity over a placeholder.
+func (c *Client) entityID(ctx context.Context, name string) (int64, error) {
+	nameNormalized := strings.ToLower(name)
+	visible, visibleArgs := c.visibleClause("entities")
+	query := `
+	SELECT id FROM entities
+	WHERE (name_normalized = ? OR ` + aliasMatch("entities") + `)
+	  AND ` + visible + `
+	ORDER BY name_normalized = ? DESC, is_placeholder
+	LIMIT 1
+	`
+
+	args := append([]any{nameNormalized, nameNormalized}, visibleArgs...)
+	args = append(args, nameNormalized)
+	var id int64
+	err := c.conn().QueryRowContext(ctx, query, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", store.ErrEntityNotFound, name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("finding entity %s: %w", name, err)
+	}
+	return id, nil
+}
+
+// aliasOwners names the entities an ambiguous alias points at.
+func aliasOwners(entities []store.Entity) string {
+	names := make([]string, len(entities))
+	for i, e := range entities {
+		names[i] = fmt.Sprintf("%s (%s)", e.Name, e.Layer)
+	}
+	return strings.Join(names, ", ")
+}
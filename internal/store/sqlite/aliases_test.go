@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+)
+
+func TestAliases_ResolveReferencesAndLookups(t *testing.T) {
+	ctx := context.Background()
+	loreDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{loreDir}, Canonical: true}},
+	}
+	client := newTestClient(t, cfg)
+	schema := exampleSchema(t)
+
+	// The reference is ingested before any entity claims the alias, so it
+	// starts out as a placeholder.
+	writeLoreFile(t, loreDir, "quent.md", "---\ntitle: Lysa Quent\ntype: npc\nlocation: The Bureau\n---\n")
+	if _, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	writeLoreFile(t, loreDir, "bureau.md", "---\ntitle: Bureau of Civic Affairs\ntype: faction\naliases: [The Bureau, Greycoats]\n---\n")
+	writeLoreFile(t, loreDir, "rellan.md", "---\ntitle: Rellan Harth\ntype: npc\nlocation: greycoats\n---\n")
+	if _, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	edges, err := client.ListEdges(ctx)
+	if err != nil {
+		t.Fatalf("list edges: %v", err)
+	}
+	if len(edges) != 2 {
+		t.Fatalf("expected two edges, got %#v", edges)
+	}
+	for _, edge := range edges {
+		if edge.Placeholder || edge.To.Name != "Bureau of Civic Affairs" {
+			t.Fatalf("expected the alias to resolve to the bureau, got %#v", edge)
+		}
+	}
+
+	entity, err := client.GetEntity(ctx, "the bureau", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if entity == nil || entity.Name != "Bureau of Civic Affairs" {
+		t.Fatalf("expected lookup by alias, got %#v", entity)
+	}
+	if !reflect.DeepEqual(entity.Aliases, []string{"The Bureau", "Greycoats"}) {
+		t.Fatalf("unexpected aliases: %#v", entity.Aliases)
+	}
+
+	rels, err := client.GetRelationships(ctx, "Greycoats", "", "incoming", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if len(rels) != 2 {
+		t.Fatalf("expected two incoming relationships, got %#v", rels)
+	}
+
+	layer, err := client.FindEntityLayer(ctx, "The Bureau", []string{"setting"})
+	if err != nil {
+		t.Fatalf("find entity layer: %v", err)
+	}
+	if layer != "setting" {
+		t.Fatalf("expected the alias to be found in setting, got %q", layer)
+	}
+
+	results, err := client.Search(ctx, "greycoats", "", "")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "Bureau of Civic Affairs" {
+		t.Fatalf("expected the alias to be searchable, got %#v", results)
+	}
+}
+
+func TestGetEntity_AmbiguousAlias(t *testing.T) {
+	ctx := context.Background()
+	loreDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{loreDir}, Canonical: true}},
+	}
+	client := newTestClient(t, cfg)
+	schema := exampleSchema(t)
+
+	writeLoreFile(t, loreDir, "quent.md", "---\ntitle: Lysa Quent\ntype: npc\naliases: [The Director]\n---\n")
+	writeLoreFile(t, loreDir, "hale.md", "---\ntitle: Selin Hale\ntype: npc\naliases: [The Director]\n---\n")
+	writeLoreFile(t, loreDir, "director.md", "---\ntitle: Harbor Director\ntype: npc\naliases: [Director]\n---\n")
+	if _, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	if _, err := client.GetEntity(ctx, "The Director", ""); err == nil {
+		t.Fatalf("expected an ambiguous alias error")
+	}
+	entity, err := client.GetEntity(ctx, "Lysa Quent", "")
+	if err != nil || entity == nil {
+		t.Fatalf("expected lookup by name, got %#v, %v", entity, err)
+	}
+}
@@ -6,29 +6,34 @@ import (
 	"strings"
 )
 
-func (c *Client) RemoveStaleNodes(ctx context.Context, layer string, currentSourceFiles []string) (int64, error) {
-	if len(currentSourceFiles) == 0 {
+// sourceKey is store.SourceKey computed in SQL.
+const sourceKey = "source_file || CASE WHEN COALESCE(source_anchor, '') = '' THEN '' ELSE '#' || source_anchor END"
+
+func (c *Client) RemoveStaleNodes(ctx context.Context, layer string, currentSources []string) (int64, error) {
+	if len(currentSources) == 0 {
 		return 0, nil
 	}
 
-	placeholders := make([]string, len(currentSourceFiles))
-	args := make([]any, len(currentSourceFiles)+1)
+	placeholders := make([]string, len(currentSources))
+	args := make([]any, len(currentSources)+1)
 	args[0] = layer
-	for i, f := range currentSourceFiles {
+	for i, f := range currentSources {
 		placeholders[i] = "?"
 		args[i+1] = f
 	}
 
-	query := fmt.Sprintf(`
-	DELETE FROM entities
+	stale := fmt.Sprintf(`
+	SELECT id FROM entities
 	WHERE layer = ?
 	  AND source_file IS NOT NULL
 	  AND source_file <> ''
-	  AND source_file NOT IN (%s)
+	  AND %s NOT IN (%s)
 	  AND is_placeholder = 0
-	`, strings.Join(placeholders, ", "))
+	`, sourceKey, strings.Join(placeholders, ", "))
 
-	result, err := c.db.ExecContext(ctx, query, args...)
+	// Edges and events of the removed entities go with them through ON DELETE
+	// CASCADE; New enables foreign_keys on every pooled connection.
+	result, err := c.conn().ExecContext(ctx, "DELETE FROM entities WHERE id IN ("+stale+")", args...)
 	if err != nil {
 		return 0, fmt.Errorf("removing stale nodes: %w", err)
 	}
@@ -37,20 +42,38 @@ func (c *Client) RemoveStaleNodes(ctx context.Context, layer string, currentSour
 	if err != nil {
 		return 0, fmt.Errorf("getting rows affected: %w", err)
 	}
+	return affected, nil
+}
+
+func (c *Client) RemoveOrphanedPlaceholders(ctx context.Context) (int64, error) {
+	query := `
+	DELETE FROM entities
+	WHERE is_placeholder = 1
+	  AND NOT EXISTS (SELECT 1 FROM edges WHERE edges.dst_id = entities.id)
+	`
 
+	result, err := c.conn().ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("removing orphaned placeholders: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
 	return affected, nil
 }
 
 func (c *Client) GetLayerHashes(ctx context.Context, layer string) (map[string]string, error) {
 	query := `
-	SELECT source_file, source_hash FROM entities
+	SELECT ` + sourceKey + `, source_hash FROM entities
 	WHERE layer = ?
 	  AND source_file IS NOT NULL
 	  AND source_file <> ''
 	  AND is_placeholder = 0
 	`
 
-	rows, err := c.db.QueryContext(ctx, query, layer)
+	rows, err := c.conn().QueryContext(ctx, query, layer)
 	if err != nil {
 		return nil, fmt.Errorf("query layer hashes: %w", err)
 	}
@@ -58,11 +81,11 @@ func (c *Client) GetLayerHashes(ctx context.Context, layer string) (map[string]s
 
 	hashes := make(map[string]string)
 	for rows.Next() {
-		var sourceFile, sourceHash string
-		if err := rows.Scan(&sourceFile, &sourceHash); err != nil {
+		var source, sourceHash string
+		if err := rows.Scan(&source, &sourceHash); err != nil {
 			return nil, fmt.Errorf("scanning layer hash: %w", err)
 		}
-		hashes[sourceFile] = sourceHash
+		hashes[source] = sourceHash
 	}
 
 	if err := rows.Err(); err != nil {
@@ -80,9 +103,9 @@ func (c *Client) FindEntityLayer(ctx context.Context, name string, layers []stri
 	nameNormalized := strings.ToLower(name)
 	for _, layer := range layers {
 		var found string
-		err := c.db.QueryRowContext(ctx,
-			"SELECT layer FROM entities WHERE name_normalized = ? AND layer = ? LIMIT 1",
-			nameNormalized, layer,
+		err := c.conn().QueryRowContext(ctx,
+			"SELECT layer FROM entities WHERE (name_normalized = ? OR "+aliasMatch("entities")+") AND layer = ? LIMIT 1",
+			nameNormalized, nameNormalized, layer,
 		).Scan(&found)
 		if err == nil {
 			return found, nil
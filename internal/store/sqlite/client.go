@@ -17,6 +17,20 @@ var _ store.Store = (*Client)(nil)
 type Client struct {
 	db  *sql.DB
 	cfg *config.ProjectConfig
+
+	// tx is set on the client handed to an InTransaction callback; every
+	// query then runs on it so the unit of work sees its own writes.
+	tx         *sql.Tx
+	savepoints int
+
+	visibility *store.Visibility
+}
+
+// querier is satisfied by *sql.DB, *sql.Tx and txn.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 func New(ctx context.Context, dsn string, cfg *config.ProjectConfig) (*Client, error) {
@@ -25,6 +39,10 @@ func New(ctx context.Context, dsn string, cfg *config.ProjectConfig) (*Client, e
 		return nil, fmt.Errorf("parsing sqlite DSN: %w", err)
 	}
 
+	// Pragmas go in the DSN so the driver applies them to every pooled
+	// connection, not just the first; concurrent sessions open several.
+	driverDSN = withPragmas(driverDSN, "busy_timeout(30000)", "journal_mode(WAL)", "foreign_keys(1)")
+
 	db, err := sql.Open("sqlite", driverDSN)
 	if err != nil {
 		return nil, fmt.Errorf("opening sqlite database: %w", err)
@@ -38,21 +56,92 @@ func New(ctx context.Context, dsn string, cfg *config.ProjectConfig) (*Client, e
 		return nil, fmt.Errorf("pinging sqlite: %w", err)
 	}
 
-	pragmas := []string{
-		"PRAGMA busy_timeout = 30000;",
-		"PRAGMA journal_mode = WAL;",
-		"PRAGMA foreign_keys = ON;",
+	return &Client{db: db, cfg: cfg}, nil
+}
+
+func (c *Client) Close(ctx context.Context) error {
+	if c.tx != nil {
+		return nil
+	}
+	return c.db.Close()
+}
+
+// InTransaction runs fn against a client bound to a single transaction, which
+// is committed only if fn returns nil. Nested calls join the outer transaction.
+func (c *Client) InTransaction(ctx context.Context, fn func(store.Store) error) error {
+	if c.tx != nil {
+		return fn(c)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Client{db: c.db, cfg: c.cfg, tx: tx, visibility: c.visibility}); err != nil {
+		return err
 	}
-	for _, pragma := range pragmas {
-		if _, err := db.ExecContext(ctx, pragma); err != nil {
-			db.Close()
-			return nil, fmt.Errorf("setting pragma %q: %w", pragma, err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) conn() querier {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.db
+}
+
+// txn is the transaction a single store method writes through. Inside a unit
+// of work it is a savepoint on the shared transaction, so the method stays
+// atomic without committing or aborting the surrounding work.
+type txn struct {
+	*sql.Tx
+	ctx       context.Context
+	savepoint string
+	done      bool
+}
+
+func (c *Client) begin(ctx context.Context) (*txn, error) {
+	if c.tx == nil {
+		tx, err := c.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
 		}
+		return &txn{Tx: tx, ctx: ctx}, nil
 	}
 
-	return &Client{db: db, cfg: cfg}, nil
+	c.savepoints++
+	name := fmt.Sprintf("lorecraft_%d", c.savepoints)
+	if _, err := c.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &txn{Tx: c.tx, ctx: ctx, savepoint: name}, nil
 }
 
-func (c *Client) Close(ctx context.Context) error {
-	return c.db.Close()
+func (t *txn) Commit() error {
+	if t.savepoint == "" {
+		return t.Tx.Commit()
+	}
+	t.done = true
+	_, err := t.Tx.ExecContext(t.ctx, "RELEASE SAVEPOINT "+t.savepoint)
+	return err
+}
+
+func (t *txn) Rollback() error {
+	if t.savepoint == "" {
+		return t.Tx.Rollback()
+	}
+	if t.done {
+		return nil
+	}
+	t.done = true
+	if _, err := t.Tx.ExecContext(t.ctx, "ROLLBACK TO SAVEPOINT "+t.savepoint); err != nil {
+		return err
+	}
+	_, err := t.Tx.ExecContext(t.ctx, "RELEASE SAVEPOINT "+t.savepoint)
+	return err
 }
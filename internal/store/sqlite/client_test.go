@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+	"lorecraft/internal/store"
+)
+
+// failingStore fails UpsertEntity for one entity, including inside a unit of
+// work, to simulate a file that cannot be written.
+type failingStore struct {
+	store.Store
+	failName string
+}
+
+func (f failingStore) UpsertEntity(ctx context.Context, e store.EntityInput) error {
+	if e.Name == f.failName {
+		return errors.New("injected failure")
+	}
+	return f.Store.UpsertEntity(ctx, e)
+}
+
+func (f failingStore) InTransaction(ctx context.Context, fn func(store.Store) error) error {
+	return f.Store.InTransaction(ctx, func(tx store.Store) error {
+		return fn(failingStore{Store: tx, failName: f.failName})
+	})
+}
+
+func TestIngest_AtomicRunKeepsPreviousGraph(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "westlands",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{dir}, Canonical: true}},
+	}
+	schema := exampleSchema(t)
+	client := newTestClient(t, cfg)
+
+	writeLoreFile(t, dir, "westport.md", "---\ntitle: Westport\ntype: settlement\n---\n\nA harbor city.\n")
+	writeLoreFile(t, dir, "selin.md", "---\ntitle: Selin Hale\ntype: npc\nlocation: Westport\n---\n\nA dockside fixer.\n")
+	if result, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{Full: true, Atomic: true}); err != nil || len(result.Errors) > 0 {
+		t.Fatalf("initial ingest: %v %v", err, result)
+	}
+
+	writeLoreFile(t, dir, "selin.md", "---\ntitle: Selin Hale\ntype: npc\nlocation: Iron Tide Hold\n---\n\nNow a smuggler.\n")
+	writeLoreFile(t, dir, "broken.md", "---\ntitle: Broken\ntype: npc\n---\n")
+	if err := os.Remove(filepath.Join(dir, "westport.md")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	failing := failingStore{Store: client, failName: "Broken"}
+	result, err := ingest.Run(ctx, cfg, schema, failing, ingest.Options{Full: true, Atomic: true})
+	if err != nil {
+		t.Fatalf("atomic ingest: %v", err)
+	}
+	if !result.RolledBack || len(result.Errors) != 1 {
+		t.Fatalf("expected rollback with one error, got %#v", result)
+	}
+
+	selin, err := client.GetEntity(ctx, "Selin Hale", "npc")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if selin == nil || selin.Body != "\nA dockside fixer.\n" {
+		t.Fatalf("expected original Selin Hale, got %#v", selin)
+	}
+	westport, err := client.GetEntity(ctx, "Westport", "settlement")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if westport == nil {
+		t.Fatalf("expected Westport to survive the rolled back run")
+	}
+	rels, err := client.GetRelationships(ctx, "Selin Hale", "LOCATED_IN", "outgoing", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if len(rels) != 1 || !hasRelationship(rels, "Selin Hale", "Westport", "LOCATED_IN", "outgoing") {
+		t.Fatalf("expected only the original LOCATED_IN edge, got %#v", rels)
+	}
+
+	result, err = ingest.Run(ctx, cfg, schema, failing, ingest.Options{Full: true})
+	if err != nil {
+		t.Fatalf("non-atomic ingest: %v", err)
+	}
+	if result.RolledBack {
+		t.Fatalf("non-atomic run should not roll back")
+	}
+	selin, err = client.GetEntity(ctx, "Selin Hale", "npc")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if selin.Body != "\nNow a smuggler.\n" {
+		t.Fatalf("expected non-atomic run to apply partial changes, got %q", selin.Body)
+	}
+}
@@ -55,3 +55,20 @@ func parseDSN(dsn string) (string, error) {
 
 	return rest, nil
 }
+
+// withPragmas appends _pragma parameters to a driver DSN.
+func withPragmas(dsn string, pragmas ...string) string {
+	var b strings.Builder
+	b.WriteString(dsn)
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	for _, pragma := range pragmas {
+		b.WriteString(sep)
+		b.WriteString("_pragma=")
+		b.WriteString(url.QueryEscape(pragma))
+		sep = "&"
+	}
+	return b.String()
+}
@@ -22,70 +22,144 @@ func (c *Client) UpsertEntity(ctx context.Context, e store.EntityInput) error {
 		return fmt.Errorf("marshaling tags: %w", err)
 	}
 
+	aliasesJSON, aliasesNormalizedJSON, err := marshalAliases(e.Aliases)
+	if err != nil {
+		return err
+	}
+
+	defaulted := e.Defaulted
+	if defaulted == nil {
+		defaulted = []string{}
+	}
+	defaultedJSON, err := json.Marshal(defaulted)
+	if err != nil {
+		return fmt.Errorf("marshaling defaulted properties: %w", err)
+	}
+
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-	INSERT INTO entities (name, name_normalized, entity_type, layer, source_file, source_hash, tags, properties, body, is_placeholder, last_ingested)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0, datetime('now'))
+	INSERT INTO entities (name, name_normalized, entity_type, layer, source_file, source_anchor, source_hash, tags, aliases, aliases_normalized, properties, defaulted_properties, body, secret, is_placeholder, last_ingested)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, datetime('now'))
 	ON CONFLICT (name_normalized, layer) DO UPDATE SET
 		name = excluded.name,
 		entity_type = excluded.entity_type,
 		source_file = excluded.source_file,
+		source_anchor = excluded.source_anchor,
 		source_hash = excluded.source_hash,
 		tags = excluded.tags,
+		aliases = excluded.aliases,
+		aliases_normalized = excluded.aliases_normalized,
 		properties = excluded.properties,
+		defaulted_properties = excluded.defaulted_properties,
 		body = excluded.body,
+		secret = excluded.secret,
 		is_placeholder = 0,
 		last_ingested = datetime('now')
+	RETURNING id
 	`
 
-	_, err = c.db.ExecContext(ctx, query,
+	var entityID int64
+	err = tx.QueryRowContext(ctx, query,
 		e.Name,
 		nameNormalized,
 		e.EntityType,
 		e.Layer,
 		e.SourceFile,
+		e.SourceAnchor,
 		e.SourceHash,
 		tagsJSON,
+		aliasesJSON,
+		aliasesNormalizedJSON,
 		propsJSON,
+		defaultedJSON,
 		e.Body,
-	)
+		e.Secret,
+	).Scan(&entityID)
 	if err != nil {
 		return fmt.Errorf("upserting entity: %w", err)
 	}
+
+	// Outgoing edges are rebuilt from the current frontmatter by the caller, so
+	// references removed from the file do not linger.
+	if _, err := tx.ExecContext(ctx, "DELETE FROM edges WHERE src_id = ?", entityID); err != nil {
+		return fmt.Errorf("clearing outgoing edges: %w", err)
+	}
+
+	if err := upsertEvent(ctx, tx, entityID, e); err != nil {
+		return err
+	}
+
+	if err := adoptAliasPlaceholders(ctx, tx, entityID, e.Layer, aliasesNormalizedJSON); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
 	return nil
 }
 
 func (c *Client) GetEntity(ctx context.Context, name, entityType string) (*store.Entity, error) {
+	return c.getEntity(ctx, name, entityType, "")
+}
+
+func (c *Client) GetEntityInLayer(ctx context.Context, name, layer string) (*store.Entity, error) {
+	return c.getEntity(ctx, name, "", layer)
+}
+
+// getEntity looks an entity up by name or alias, optionally only among the
+// given type and its subtypes or within one layer.
+func (c *Client) getEntity(ctx context.Context, name, entityType, layer string) (*store.Entity, error) {
 	nameNormalized := strings.ToLower(name)
 
+	// An entity is looked up by name first and by alias only when no name
+	// matches.
+	visible, visibleArgs := c.visibleClause("entities")
+	typed, typeArgs := typeClause("entities", entityType)
 	query := `
-	SELECT id, name, entity_type, layer, source_file, source_hash, tags, properties, body
+	SELECT name_normalized = ?, name, entity_type, layer, source_file, COALESCE(source_anchor, ''), source_hash, tags, aliases, properties, defaulted_properties, body, secret
 	FROM entities
-	WHERE name_normalized = ?
-	  AND (? = '' OR entity_type = ?)
+	WHERE (name_normalized = ? OR ` + aliasMatch("entities") + `)
+	  AND ` + typed + `
+	  AND (? = '' OR LOWER(layer) = LOWER(?))
 	  AND is_placeholder = 0
-	`
+	  AND ` + visible
 
-	rows, err := c.db.QueryContext(ctx, query, nameNormalized, entityType, entityType)
+	args := append([]any{nameNormalized, nameNormalized, nameNormalized}, typeArgs...)
+	args = append(append(args, layer, layer), visibleArgs...)
+	rows, err := c.conn().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("getting entity: %w", err)
 	}
 	defer rows.Close()
 
-	var entities []store.Entity
+	var entities, aliased []store.Entity
 	for rows.Next() {
 		var e store.Entity
+		var exact bool
 		var propsBytes []byte
 		var tagsBytes []byte
+		var aliasesBytes []byte
+		var defaultedBytes []byte
 		err := rows.Scan(
-			new(int64),
+			&exact,
 			&e.Name,
 			&e.EntityType,
 			&e.Layer,
 			&e.SourceFile,
+			&e.SourceAnchor,
 			&e.SourceHash,
 			&tagsBytes,
+			&aliasesBytes,
 			&propsBytes,
+			&defaultedBytes,
 			&e.Body,
+			&e.Secret,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning entity: %w", err)
@@ -100,19 +174,42 @@ func (c *Client) GetEntity(ctx context.Context, name, entityType string) (*store
 				return nil, fmt.Errorf("unmarshaling tags: %w", err)
 			}
 		}
+		if len(aliasesBytes) > 0 {
+			if err := json.Unmarshal(aliasesBytes, &e.Aliases); err != nil {
+				return nil, fmt.Errorf("unmarshaling aliases: %w", err)
+			}
+		}
+		if len(defaultedBytes) > 0 {
+			if err := json.Unmarshal(defaultedBytes, &e.Defaulted); err != nil {
+				return nil, fmt.Errorf("unmarshaling defaulted properties: %w", err)
+			}
+		}
 		if e.Properties == nil {
 			e.Properties = map[string]any{}
 		}
 		if e.Tags == nil {
 			e.Tags = []string{}
 		}
-		entities = append(entities, e)
+		if c.visibility != nil {
+			e.Secret = ""
+		}
+		if exact {
+			entities = append(entities, e)
+		} else {
+			aliased = append(aliased, e)
+		}
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating entity rows: %w", err)
 	}
 
+	if len(entities) == 0 {
+		if len(aliased) > 1 {
+			return nil, fmt.Errorf("ambiguous alias %q: %s", name, aliasOwners(aliased))
+		}
+		entities = aliased
+	}
 	if len(entities) == 0 {
 		return nil, nil
 	}
@@ -124,16 +221,20 @@ func (c *Client) GetEntity(ctx context.Context, name, entityType string) (*store
 }
 
 func (c *Client) ListEntities(ctx context.Context, entityType, layer, tag string) ([]store.EntitySummary, error) {
+	visible, visibleArgs := c.visibleClause("entities")
+	typed, typeArgs := typeClause("entities", entityType)
 	query := `
 	SELECT name, entity_type, layer, tags
 	FROM entities
-	WHERE (? = '' OR entity_type = ?)
+	WHERE ` + typed + `
 	  AND (? = '' OR layer = ?)
 	  AND is_placeholder = 0
+	  AND ` + visible + `
 	ORDER BY name
 	`
 
-	rows, err := c.db.QueryContext(ctx, query, entityType, entityType, layer, layer)
+	args := append(append(typeArgs, layer, layer), visibleArgs...)
+	rows, err := c.conn().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("listing entities: %w", err)
 	}
@@ -175,14 +276,16 @@ func (c *Client) ListEntities(ctx context.Context, entityType, layer, tag string
 }
 
 func (c *Client) ListEntitiesWithProperties(ctx context.Context) ([]store.Entity, error) {
+	visible, visibleArgs := c.visibleClause("entities")
 	query := `
-	SELECT name, entity_type, layer, source_file, source_hash, tags, properties, body
+	SELECT name, entity_type, layer, source_file, COALESCE(source_anchor, ''), source_hash, tags, aliases, properties, defaulted_properties, body, secret
 	FROM entities
 	WHERE is_placeholder = 0
+	  AND ` + visible + `
 	ORDER BY name
 	`
 
-	rows, err := c.db.QueryContext(ctx, query)
+	rows, err := c.conn().QueryContext(ctx, query, visibleArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("listing entities with properties: %w", err)
 	}
@@ -193,15 +296,21 @@ func (c *Client) ListEntitiesWithProperties(ctx context.Context) ([]store.Entity
 		var e store.Entity
 		var propsBytes []byte
 		var tagsBytes []byte
+		var aliasesBytes []byte
+		var defaultedBytes []byte
 		err := rows.Scan(
 			&e.Name,
 			&e.EntityType,
 			&e.Layer,
 			&e.SourceFile,
+			&e.SourceAnchor,
 			&e.SourceHash,
 			&tagsBytes,
+			&aliasesBytes,
 			&propsBytes,
+			&defaultedBytes,
 			&e.Body,
+			&e.Secret,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning entity: %w", err)
@@ -216,12 +325,25 @@ func (c *Client) ListEntitiesWithProperties(ctx context.Context) ([]store.Entity
 				return nil, fmt.Errorf("unmarshaling tags: %w", err)
 			}
 		}
+		if len(aliasesBytes) > 0 {
+			if err := json.Unmarshal(aliasesBytes, &e.Aliases); err != nil {
+				return nil, fmt.Errorf("unmarshaling aliases: %w", err)
+			}
+		}
+		if len(defaultedBytes) > 0 {
+			if err := json.Unmarshal(defaultedBytes, &e.Defaulted); err != nil {
+				return nil, fmt.Errorf("unmarshaling defaulted properties: %w", err)
+			}
+		}
 		if e.Properties == nil {
 			e.Properties = map[string]any{}
 		}
 		if e.Tags == nil {
 			e.Tags = []string{}
 		}
+		if c.visibility != nil {
+			e.Secret = ""
+		}
 		entities = append(entities, e)
 	}
 
@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lorecraft/internal/store"
+)
+
+// upsertEvent keeps the events row for an entity in sync with its properties.
+// Only entities of type "event", or a type extending it, have a row; any other
+// type clears it so that an entity whose type changed does not linger on the
+// timeline.
+func upsertEvent(ctx context.Context, tx querier, entityID int64, e store.EntityInput) error {
+	event, err := isEventType(ctx, tx, e.EntityType)
+	if err != nil {
+		return err
+	}
+	if !event {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM events WHERE entity_id = ?", entityID); err != nil {
+			return fmt.Errorf("removing event: %w", err)
+		}
+		return nil
+	}
+
+	consequences := "[]"
+	if raw, ok := e.Properties["consequences_json"].(string); ok && strings.TrimSpace(raw) != "" {
+		consequences = raw
+	}
+
+	query := `
+	INSERT INTO events (entity_id, layer, session, date_in_world, consequences)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (entity_id) DO UPDATE SET
+		layer = excluded.layer,
+		session = excluded.session,
+		date_in_world = excluded.date_in_world,
+		consequences = excluded.consequences
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		entityID,
+		e.Layer,
+		store.EventSession(e.Properties["session"]),
+		store.EventDate(e.Properties["date_in_world"]),
+		consequences,
+	)
+	if err != nil {
+		return fmt.Errorf("upserting event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"lorecraft/internal/store"
+)
+
+func (c *Client) GetSchemaState(ctx context.Context) (*store.SchemaState, error) {
+	// schema diff reads the state without EnsureSchema, so the table may not
+	// exist yet.
+	var tables int
+	err := c.conn().QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_state'").Scan(&tables)
+	if err != nil {
+		return nil, fmt.Errorf("query schema state: %w", err)
+	}
+	if tables == 0 {
+		return nil, nil
+	}
+
+	var state store.SchemaState
+	var source string
+	err = c.conn().QueryRowContext(ctx, "SELECT fingerprint, source FROM schema_state WHERE id = 1").Scan(&state.Fingerprint, &source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query schema state: %w", err)
+	}
+	state.Source = []byte(source)
+	return &state, nil
+}
+
+func (c *Client) SaveSchemaState(ctx context.Context, state store.SchemaState) error {
+	_, err := c.conn().ExecContext(ctx, `
+	INSERT INTO schema_state (id, fingerprint, source, ingested_at)
+	VALUES (1, ?, ?, datetime('now'))
+	ON CONFLICT (id) DO UPDATE SET
+		fingerprint = excluded.fingerprint,
+		source = excluded.source,
+		ingested_at = excluded.ingested_at`,
+		state.Fingerprint, string(state.Source),
+	)
+	if err != nil {
+		return fmt.Errorf("saving schema state: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) RenameProperty(ctx context.Context, entityType, from, to string) (int64, error) {
+	fromPath := `$."` + from + `"`
+	toPath := `$."` + to + `"`
+
+	// A value read with -> is inserted as JSON, so lists and numbers keep
+	// their type.
+	result, err := c.conn().ExecContext(ctx, `
+	UPDATE entities
+	SET properties = json_set(json_remove(properties, ?), ?, properties -> ?),
+		defaulted_properties = (
+			SELECT json_group_array(CASE WHEN value = ? THEN ? ELSE value END)
+			FROM json_each(entities.defaulted_properties)
+		)
+	WHERE LOWER(entity_type) = LOWER(?)
+	  AND is_placeholder = 0
+	  AND json_type(properties, ?) IS NOT NULL
+	  AND json_type(properties, ?) IS NULL`,
+		fromPath, toPath, fromPath, from, to, entityType, fromPath, toPath,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("renaming property %s of %s: %w", from, entityType, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+	return affected, nil
+}
+
+func (c *Client) RenameRelationshipType(ctx context.Context, from, to string) (int64, error) {
+	tx, err := c.begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// An edge that already exists under the new name makes the old one redundant.
+	_, err = tx.ExecContext(ctx, `
+	DELETE FROM edges
+	WHERE UPPER(rel_type) = UPPER(?)
+	  AND EXISTS (
+		SELECT 1 FROM edges other
+		WHERE other.src_id = edges.src_id
+		  AND other.dst_id = edges.dst_id
+		  AND UPPER(other.rel_type) = UPPER(?)
+	  )`,
+		from, to,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("removing duplicate edges: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE edges SET rel_type = ? WHERE UPPER(rel_type) = UPPER(?)", to, from)
+	if err != nil {
+		return 0, fmt.Errorf("renaming relationship %s: %w", from, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("getting rows affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return affected, nil
+}
@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+	"lorecraft/internal/store"
+)
+
+func TestRenameProperty(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+	}
+	client := newTestClient(t, cfg)
+	if err := client.EnsureSchema(ctx, exampleSchema(t)); err != nil {
+		t.Fatalf("ensure schema: %v", err)
+	}
+
+	inputs := []store.EntityInput{
+		{Name: "Mira Vell", EntityType: "npc", Layer: "setting", Properties: map[string]any{"titles": []any{"Harbourmaster", "Warden"}, "status": "alive"}, Defaulted: []string{"status"}},
+		{Name: "Old Tom", EntityType: "npc", Layer: "setting", Properties: map[string]any{"titles": "Fisherman", "honours": "none"}},
+		{Name: "Tide Guild", EntityType: "faction", Layer: "setting", Properties: map[string]any{"titles": "Guild"}},
+	}
+	for _, input := range inputs {
+		if err := client.UpsertEntity(ctx, input); err != nil {
+			t.Fatalf("upsert %s: %v", input.Name, err)
+		}
+	}
+
+	renamed, err := client.RenameProperty(ctx, "NPC", "titles", "honours")
+	if err != nil {
+		t.Fatalf("rename property: %v", err)
+	}
+	if renamed != 1 {
+		t.Fatalf("expected only the npc without the new property to change, got %d", renamed)
+	}
+	mira, err := client.GetEntity(ctx, "Mira Vell", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if _, ok := mira.Properties["titles"]; ok || !reflect.DeepEqual(mira.Properties["honours"], []any{"Harbourmaster", "Warden"}) {
+		t.Fatalf("expected the list to move unchanged, got %#v", mira.Properties)
+	}
+	guild, err := client.GetEntity(ctx, "Tide Guild", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if guild.Properties["titles"] != "Guild" {
+		t.Fatalf("expected other entity types to be left alone, got %#v", guild.Properties)
+	}
+
+	renamed, err = client.RenameProperty(ctx, "npc", "status", "condition")
+	if err != nil {
+		t.Fatalf("rename property: %v", err)
+	}
+	mira, err = client.GetEntity(ctx, "Mira Vell", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if renamed != 1 || !reflect.DeepEqual(mira.Defaulted, []string{"condition"}) {
+		t.Fatalf("expected the defaulted property to be renamed, got %d and %#v", renamed, mira.Defaulted)
+	}
+}
+
+func TestIngest_SchemaChanges(t *testing.T) {
+	ctx := context.Background()
+	loreDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{loreDir}, Canonical: true}},
+		Links:    config.LinksConfig{Disabled: true},
+	}
+	client := newTestClient(t, cfg)
+
+	state, err := client.GetSchemaState(ctx)
+	if err != nil || state != nil {
+		t.Fatalf("expected no schema state before the tables exist, got %#v and %v", state, err)
+	}
+
+	before, err := config.ParseSchema([]byte(`version: 1
+entity_types:
+  - name: npc
+    properties:
+      - { name: occupation, type: string }
+    field_mappings:
+      - { field: faction, relationship: MEMBER, target_type: [faction] }
+  - name: faction
+  - name: rumour
+relationship_types:
+  - name: MEMBER
+`))
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	after, err := config.ParseSchema([]byte(`version: 1
+entity_types:
+  - name: npc
+    properties:
+      - { name: role, type: string, renamed_from: occupation }
+    field_mappings:
+      - { field: faction, relationship: MEMBER_OF, target_type: [faction] }
+  - name: faction
+relationship_types:
+  - name: MEMBER_OF
+    renamed_from: MEMBER
+`))
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	writeLoreFile(t, loreDir, "mira.md", "---\ntitle: Mira Vell\ntype: npc\noccupation: Harbourmaster\nfaction: Tide Guild\n---\n")
+	writeLoreFile(t, loreDir, "guild.md", "---\ntitle: Tide Guild\ntype: faction\n---\n")
+	writeLoreFile(t, loreDir, "rumour.md", "---\ntitle: The Drowned Bell\ntype: rumour\n---\n")
+
+	if _, err := ingest.Run(ctx, cfg, before, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	state, err = client.GetSchemaState(ctx)
+	if err != nil {
+		t.Fatalf("schema state: %v", err)
+	}
+	if state == nil || state.Fingerprint != before.Fingerprint() {
+		t.Fatalf("expected the ingested schema to be recorded, got %#v", state)
+	}
+
+	result, err := ingest.Run(ctx, cfg, after, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	if !reflect.DeepEqual(result.Reingested, []string{"npc"}) || result.Renamed != 2 || result.NodesUpserted != 1 {
+		t.Fatalf("expected the unchanged files of the changed type to be re-ingested, got %#v", result)
+	}
+
+	mira, err := client.GetEntity(ctx, "Mira Vell", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if mira.Properties["role"] != "Harbourmaster" {
+		t.Fatalf("expected the old frontmatter key to set the renamed property, got %#v", mira.Properties)
+	}
+	rels, err := client.GetRelationships(ctx, "Mira Vell", "", "outgoing", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if len(rels) != 1 || rels[0].Type != "MEMBER_OF" {
+		t.Fatalf("expected the renamed relationship, got %#v", rels)
+	}
+	rumour, err := client.GetEntity(ctx, "The Drowned Bell", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if rumour != nil {
+		t.Fatalf("expected the entity of the removed type to be dropped, got %#v", rumour)
+	}
+
+	result, err = ingest.Run(ctx, cfg, after, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+	if len(result.Reingested) != 0 || result.NodesUpserted != 0 {
+		t.Fatalf("expected nothing to re-ingest once the schema is recorded, got %#v", result)
+	}
+}
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lorecraft/internal/store"
+)
+
+func (c *Client) FindPaths(ctx context.Context, from, to string, maxDepth int, relTypes []string) ([]store.Path, error) {
+	if maxDepth < 1 || maxDepth > 5 {
+		return nil, fmt.Errorf("depth must be between 1 and 5")
+	}
+	for _, relType := range relTypes {
+		if !relTypePattern.MatchString(relType) {
+			return nil, fmt.Errorf("invalid relationship type: %s", relType)
+		}
+	}
+
+	types, err := c.loadRelationshipTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	startID, err := c.entityID(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	goalID, err := c.entityID(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	srcVisible, srcArgs := c.visibleClause("s")
+	dstVisible, dstArgs := c.visibleClause("d")
+	expand := func(ids []int64) ([]store.PathEdge, error) {
+		marks := make([]string, len(ids))
+		for i := range ids {
+			marks[i] = "?"
+		}
+		placeholders := strings.Join(marks, ",")
+		query := fmt.Sprintf(`
+		SELECT e.src_id, e.dst_id, e.rel_type,
+			   s.name, s.entity_type, s.layer,
+			   d.name, d.entity_type, d.layer
+		FROM edges e
+		JOIN entities s ON e.src_id = s.id
+		JOIN entities d ON e.dst_id = d.id
+		WHERE (e.src_id IN (%s) OR e.dst_id IN (%s))
+		  AND %s
+		  AND %s`, placeholders, placeholders, srcVisible, dstVisible)
+
+		args := make([]any, 0, len(ids)*2+len(srcArgs)+len(dstArgs))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		for _, id := range ids {
+			args = append(args, id)
+		}
+		args = append(args, srcArgs...)
+		args = append(args, dstArgs...)
+
+		rows, err := c.conn().QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("querying path edges: %w", err)
+		}
+		defer rows.Close()
+
+		var edges []store.PathEdge
+		for rows.Next() {
+			var edge store.PathEdge
+			err := rows.Scan(&edge.SrcID, &edge.DstID, &edge.Type,
+				&edge.Src.Name, &edge.Src.EntityType, &edge.Src.Layer,
+				&edge.Dst.Name, &edge.Dst.EntityType, &edge.Dst.Layer,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("scanning path edge: %w", err)
+			}
+			edges = append(edges, edge)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("iterating path edges: %w", err)
+		}
+		return edges, nil
+	}
+
+	return store.SearchPaths(startID, goalID, maxDepth, types, relTypes, expand)
+}
@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"lorecraft/internal/store"
+)
+
+func TestFindPaths(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+
+	paths, err := client.FindPaths(ctx, "Selin Hale", "The Iron Tide", 3, nil)
+	if err != nil {
+		t.Fatalf("find paths: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("expected one path within three hops, got %#v", paths)
+	}
+	want := []store.PathHop{
+		{From: store.EntityRef{Name: "Selin Hale", EntityType: "npc", Layer: "setting"}, To: store.EntityRef{Name: "Bureau Director Lysa Quent", EntityType: "npc", Layer: "setting"}, Type: "RELATED_TO", Direction: "incoming"},
+		{From: store.EntityRef{Name: "Bureau Director Lysa Quent", EntityType: "npc", Layer: "setting"}, To: store.EntityRef{Name: "Westport", EntityType: "settlement", Layer: "setting"}, Type: "LOCATED_IN", Direction: "outgoing"},
+		{From: store.EntityRef{Name: "Westport", EntityType: "settlement", Layer: "setting"}, To: store.EntityRef{Name: "The Iron Tide", EntityType: "faction", Layer: "setting"}, Type: "OPERATES_IN", Direction: "incoming"},
+	}
+	for i, hop := range paths[0].Hops {
+		if hop != want[i] {
+			t.Fatalf("hop %d = %#v, want %#v", i, hop, want[i])
+		}
+	}
+
+	paths, err = client.FindPaths(ctx, "Selin Hale", "The Iron Tide", 4, nil)
+	if err != nil {
+		t.Fatalf("find paths: %v", err)
+	}
+	if len(paths) < 2 || len(paths[0].Hops) != 3 || len(paths[len(paths)-1].Hops) != 4 {
+		t.Fatalf("expected longer paths after the shortest, got %d paths", len(paths))
+	}
+
+	paths, err = client.FindPaths(ctx, "Bureau of Civic Affairs", "Selin Hale", 3, []string{"HAS_MEMBER", "RELATED_TO"})
+	if err != nil {
+		t.Fatalf("find paths: %v", err)
+	}
+	if len(paths) != 1 || paths[0].Hops[0].Type != "HAS_MEMBER" || paths[0].Hops[0].Direction != "outgoing" {
+		t.Fatalf("expected a HAS_MEMBER hop from the bureau, got %#v", paths)
+	}
+
+	if _, err := client.FindPaths(ctx, "Selin Hale", "Nobody", 3, nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected an unknown entity error, got %v", err)
+	}
+}
+
+func TestFindPaths_HonoursVisibility(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+	player := client.WithVisibility(&store.Visibility{Property: "visibility", Hidden: []string{"gm"}})
+
+	paths, err := player.FindPaths(ctx, "Overlord Rellan Harth", "Bureau of Civic Affairs", 3, nil)
+	if err != nil {
+		t.Fatalf("find paths: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("expected a path around the hidden entity")
+	}
+	for _, path := range paths {
+		for _, hop := range path.Hops {
+			if hop.To.Name == "Bureau Director Lysa Quent" {
+				t.Fatalf("path through a hidden entity: %#v", path)
+			}
+		}
+	}
+	if _, err := player.FindPaths(ctx, "Overlord Rellan Harth", "Selin Hale", 3, nil); err == nil {
+		t.Fatalf("expected a hidden endpoint to be reported as not found")
+	}
+}
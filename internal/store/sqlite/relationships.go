@@ -2,6 +2,8 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -16,7 +18,7 @@ func (c *Client) UpsertRelationship(ctx context.Context, fromName, fromLayer, to
 		return fmt.Errorf("invalid relationship type: %s", relType)
 	}
 
-	tx, err := c.db.BeginTx(ctx, nil)
+	tx, err := c.begin(ctx)
 	if err != nil {
 		return fmt.Errorf("beginning transaction: %w", err)
 	}
@@ -31,14 +33,29 @@ func (c *Client) UpsertRelationship(ctx context.Context, fromName, fromLayer, to
 		return fmt.Errorf("finding source entity: %w", err)
 	}
 
+	// A target named by an alias resolves to its entity unless some entity in
+	// the layer carries that name; otherwise a placeholder stands in for it.
 	var dstID int64
-	err = tx.QueryRowContext(ctx,
-		`INSERT INTO entities (name, name_normalized, entity_type, layer, is_placeholder, tags, properties)
-		VALUES (?, ?, '', ?, 1, '[]', '{}')
-		ON CONFLICT (name_normalized, layer) DO UPDATE SET name = entities.name
-		RETURNING id`,
-		toName, strings.ToLower(toName), toLayer,
+	toNormalized := strings.ToLower(toName)
+	err = tx.QueryRowContext(ctx, `
+	SELECT id FROM entities
+	WHERE layer = ?
+	  AND is_placeholder = 0
+	  AND `+aliasMatch("entities")+`
+	  AND NOT EXISTS (SELECT 1 FROM entities named WHERE named.name_normalized = ? AND named.layer = ?)
+	ORDER BY id
+	LIMIT 1`,
+		toLayer, toNormalized, toNormalized, toLayer,
 	).Scan(&dstID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = tx.QueryRowContext(ctx,
+			`INSERT INTO entities (name, name_normalized, entity_type, layer, is_placeholder, tags, properties)
+			VALUES (?, ?, '', ?, 1, '[]', '{}')
+			ON CONFLICT (name_normalized, layer) DO UPDATE SET name = entities.name
+			RETURNING id`,
+			toName, toNormalized, toLayer,
+		).Scan(&dstID)
+	}
 	if err != nil {
 		return fmt.Errorf("upserting target entity: %w", err)
 	}
@@ -75,15 +92,20 @@ func (c *Client) GetRelationships(ctx context.Context, name, relType, direction
 		return nil, fmt.Errorf("invalid relationship type: %s", relType)
 	}
 
-	var startID int64
-	err := c.db.QueryRowContext(ctx,
-		"SELECT id FROM entities WHERE name_normalized = ?",
-		strings.ToLower(name),
-	).Scan(&startID)
+	relTypes, err := c.loadRelationshipTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storedType := relTypes.StoredType(relType)
+
+	startID, err := c.entityID(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("finding start entity: %w", err)
 	}
 
+	srcVisible, srcArgs := c.visibleClause("s")
+	dstVisible, dstArgs := c.visibleClause("d")
+
 	visited := make(map[int64]bool)
 	visited[startID] = true
 	frontier := []int64{startID}
@@ -94,64 +116,46 @@ func (c *Client) GetRelationships(ctx context.Context, name, relType, direction
 			break
 		}
 
-		placeholders := make([]string, len(frontier))
-		args := make([]any, len(frontier)+1)
-		for i, id := range frontier {
-			placeholders[i] = "?"
-			args[i+1] = id
-		}
-		args[0] = strings.Join(placeholders, ",")
-
-		var query string
-		switch direction {
-		case "outgoing":
-			query = fmt.Sprintf(`
-			SELECT e.src_id, e.dst_id, e.rel_type,
-				   s.name AS src_name, s.entity_type AS src_type, s.layer AS src_layer,
-				   d.name AS dst_name, d.entity_type AS dst_type, d.layer AS dst_layer
-			FROM edges e
-			JOIN entities s ON e.src_id = s.id
-			JOIN entities d ON e.dst_id = d.id
-			WHERE e.src_id IN (%s)
-			  AND (? = '' OR e.rel_type = ?)`, args[0].(string))
-		case "incoming":
-			query = fmt.Sprintf(`
-			SELECT e.src_id, e.dst_id, e.rel_type,
-				   s.name AS src_name, s.entity_type AS src_type, s.layer AS src_layer,
-				   d.name AS dst_name, d.entity_type AS dst_type, d.layer AS dst_layer
-			FROM edges e
-			JOIN entities s ON e.src_id = s.id
-			JOIN entities d ON e.dst_id = d.id
-			WHERE e.dst_id IN (%s)
-			  AND (? = '' OR e.rel_type = ?)`, args[0].(string))
-		case "both":
-			query = fmt.Sprintf(`
-			SELECT e.src_id, e.dst_id, e.rel_type,
-				   s.name AS src_name, s.entity_type AS src_type, s.layer AS src_layer,
-				   d.name AS dst_name, d.entity_type AS dst_type, d.layer AS dst_layer
-			FROM edges e
-			JOIN entities s ON e.src_id = s.id
-			JOIN entities d ON e.dst_id = d.id
-			WHERE (e.src_id IN (%s) OR e.dst_id IN (%s))
-			  AND (? = '' OR e.rel_type = ?)`, args[0].(string), args[0].(string))
+		// Edges are fetched in both directions because inverse and symmetric
+		// types can match an edge against its stored direction; Match decides.
+		marks := make([]string, len(frontier))
+		for i := range frontier {
+			marks[i] = "?"
 		}
-
-		queryArgs := make([]any, 0)
+		placeholders := strings.Join(marks, ",")
+		query := fmt.Sprintf(`
+		SELECT e.src_id, e.dst_id, e.rel_type,
+			   s.name AS src_name, s.entity_type AS src_type, s.layer AS src_layer,
+			   d.name AS dst_name, d.entity_type AS dst_type, d.layer AS dst_layer
+		FROM edges e
+		JOIN entities s ON e.src_id = s.id
+		JOIN entities d ON e.dst_id = d.id
+		WHERE (e.src_id IN (%s) OR e.dst_id IN (%s))
+		  AND (? = '' OR e.rel_type = ?)
+		  AND %s
+		  AND %s`, placeholders, placeholders, srcVisible, dstVisible)
+
+		queryArgs := make([]any, 0, len(frontier)*2+2)
 		for _, id := range frontier {
 			queryArgs = append(queryArgs, id)
 		}
-		if direction == "both" {
-			for _, id := range frontier {
-				queryArgs = append(queryArgs, id)
-			}
+		for _, id := range frontier {
+			queryArgs = append(queryArgs, id)
 		}
-		queryArgs = append(queryArgs, relType, relType)
+		queryArgs = append(queryArgs, storedType, storedType)
+		queryArgs = append(queryArgs, srcArgs...)
+		queryArgs = append(queryArgs, dstArgs...)
 
-		rows, err := c.db.QueryContext(ctx, query, queryArgs...)
+		rows, err := c.conn().QueryContext(ctx, query, queryArgs...)
 		if err != nil {
 			return nil, fmt.Errorf("querying relationships: %w", err)
 		}
 
+		inFrontier := make(map[int64]bool, len(frontier))
+		for _, id := range frontier {
+			inFrontier[id] = true
+		}
+
 		var newFrontier []int64
 		for rows.Next() {
 			var srcID, dstID int64
@@ -173,31 +177,25 @@ func (c *Client) GetRelationships(ctx context.Context, name, relType, direction
 			rel.To.EntityType = dstType
 			rel.To.Layer = dstLayer
 
-			var otherID int64
-			var isFromFrontier bool
-			for _, fid := range frontier {
-				if fid == srcID {
-					otherID = dstID
-					isFromFrontier = true
-					break
-				} else if fid == dstID {
-					otherID = srcID
-					isFromFrontier = false
-					break
-				}
+			otherID := dstID
+			natural := "outgoing"
+			if !inFrontier[srcID] {
+				otherID = srcID
+				natural = "incoming"
+				rel.From, rel.To = rel.To, rel.From
 			}
 
 			if visited[otherID] {
 				continue
 			}
 
-			if isFromFrontier {
-				rel.Direction = "outgoing"
-			} else {
-				rel.Direction = "incoming"
-				rel.From, rel.To = rel.To, rel.From
+			label, relDirection, ok := relTypes.Match(rel.Type, natural, relType, direction)
+			if !ok {
+				continue
 			}
 
+			rel.Type = label
+			rel.Direction = relDirection
 			rel.Depth = currentDepth
 			results = append(results, rel)
 			newFrontier = append(newFrontier, otherID)
@@ -218,3 +216,26 @@ func (c *Client) GetRelationships(ctx context.Context, name, relType, direction
 
 	return results, nil
 }
+
+func (c *Client) loadRelationshipTypes(ctx context.Context) (store.RelationshipTypes, error) {
+	rows, err := c.conn().QueryContext(ctx, "SELECT name, inverse, symmetric FROM relationship_types")
+	if err != nil {
+		return nil, fmt.Errorf("loading relationship types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []store.RelationshipType
+	for rows.Next() {
+		var rel store.RelationshipType
+		if err := rows.Scan(&rel.Name, &rel.Inverse, &rel.Symmetric); err != nil {
+			return nil, fmt.Errorf("scanning relationship type: %w", err)
+		}
+		types = append(types, rel)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating relationship types: %w", err)
+	}
+
+	return store.NewRelationshipTypes(types), nil
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+	"lorecraft/internal/store"
+)
+
+func TestGetRelationships_InverseType(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+
+	rels, err := client.GetRelationships(ctx, "Bureau of Civic Affairs", "HAS_MEMBER", "outgoing", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if !hasRelationship(rels, "Bureau of Civic Affairs", "Bureau Director Lysa Quent", "HAS_MEMBER", "outgoing") {
+		t.Fatalf("expected HAS_MEMBER edge to Lysa Quent, got %#v", rels)
+	}
+
+	rels, err = client.GetRelationships(ctx, "The Westlands", "CONTAINS", "both", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if !hasRelationship(rels, "The Westlands", "Westport", "CONTAINS", "outgoing") {
+		t.Fatalf("expected CONTAINS edge to Westport, got %#v", rels)
+	}
+	for _, rel := range rels {
+		if rel.Type != "CONTAINS" {
+			t.Fatalf("expected only CONTAINS edges, got %#v", rel)
+		}
+	}
+}
+
+func TestGetRelationships_SymmetricType(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+
+	rels, err := client.GetRelationships(ctx, "Selin Hale", "RELATED_TO", "outgoing", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if !hasRelationship(rels, "Selin Hale", "Bureau Director Lysa Quent", "RELATED_TO", "outgoing") {
+		t.Fatalf("expected symmetric RELATED_TO edge, got %#v", rels)
+	}
+
+	rels, err = client.GetRelationships(ctx, "Selin Hale", "MEMBER_OF", "outgoing", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if len(rels) != 0 {
+		t.Fatalf("expected no MEMBER_OF edges, got %#v", rels)
+	}
+}
+
+func TestIngest_RemovedReferenceDropsEdge(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "westlands",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{dir}, Canonical: true}},
+	}
+	schema := exampleSchema(t)
+	client := newTestClient(t, cfg)
+
+	writeLoreFile(t, dir, "westport.md", "---\ntitle: Westport\ntype: settlement\n---\n")
+	writeLoreFile(t, dir, "captain.md", "---\ntitle: Captain Vey\ntype: npc\nlocation: Westport\nfaction: The Watch\n---\n")
+	if _, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	writeLoreFile(t, dir, "captain.md", "---\ntitle: Captain Vey\ntype: npc\nlocation: Westport\n---\n")
+	result, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+	if result.NodesRemoved != 1 {
+		t.Fatalf("expected the orphaned placeholder to be removed, got %d nodes removed", result.NodesRemoved)
+	}
+
+	rels, err := client.GetRelationships(ctx, "Captain Vey", "", "outgoing", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if len(rels) != 1 || !hasRelationship(rels, "Captain Vey", "Westport", "LOCATED_IN", "outgoing") {
+		t.Fatalf("expected only LOCATED_IN to remain, got %#v", rels)
+	}
+
+	var placeholders int
+	if err := client.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM entities WHERE is_placeholder = 1").Scan(&placeholders); err != nil {
+		t.Fatalf("count placeholders: %v", err)
+	}
+	if placeholders != 0 {
+		t.Fatalf("expected The Watch placeholder to be removed, got %d placeholders", placeholders)
+	}
+}
+
+func TestRemoveStaleNodes_CascadesToEdgesAndEvents(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+	}
+	client := newTestClient(t, cfg)
+	if err := client.EnsureSchema(ctx, exampleSchema(t)); err != nil {
+		t.Fatalf("ensure schema: %v", err)
+	}
+
+	inputs := []store.EntityInput{
+		{Name: "Westport", EntityType: "settlement", Layer: "setting", SourceFile: "westport.md"},
+		{Name: "Harbour Fire", EntityType: "event", Layer: "setting", SourceFile: "fire.md", Properties: map[string]any{"session": 3}},
+	}
+	for _, input := range inputs {
+		if err := client.UpsertEntity(ctx, input); err != nil {
+			t.Fatalf("upsert %s: %v", input.Name, err)
+		}
+	}
+	if err := client.UpsertRelationship(ctx, "Harbour Fire", "setting", "Westport", "setting", "AFFECTS"); err != nil {
+		t.Fatalf("upsert relationship: %v", err)
+	}
+
+	removed, err := client.RemoveStaleNodes(ctx, "setting", []string{"westport.md"})
+	if err != nil {
+		t.Fatalf("remove stale nodes: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected the event to be removed, got %d", removed)
+	}
+
+	var edges, events int
+	if err := client.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM edges").Scan(&edges); err != nil {
+		t.Fatalf("count edges: %v", err)
+	}
+	if err := client.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM events").Scan(&events); err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	if edges != 0 || events != 0 {
+		t.Fatalf("expected the edges and events of the removed entity to cascade, got %d edges and %d events", edges, events)
+	}
+}
+
+func hasRelationship(rels []store.Relationship, from, to, relType, direction string) bool {
+	for _, rel := range rels {
+		if rel.From.Name == from && rel.To.Name == to && rel.Type == relType && rel.Direction == direction {
+			return true
+		}
+	}
+	return false
+}
@@ -17,10 +17,15 @@ func (c *Client) EnsureSchema(ctx context.Context, schema *config.Schema) error
 		entity_type     TEXT NOT NULL,
 		layer           TEXT NOT NULL,
 		source_file     TEXT,
+		source_anchor   TEXT DEFAULT '',
 		source_hash     TEXT,
 		tags            TEXT DEFAULT '[]',
+		aliases         TEXT DEFAULT '[]',
+		aliases_normalized TEXT DEFAULT '[]',
 		properties      TEXT DEFAULT '{}',
+		defaulted_properties TEXT DEFAULT '[]',
 		body            TEXT DEFAULT '',
+		secret          TEXT DEFAULT '',
 		is_placeholder  INTEGER DEFAULT 0,
 		last_ingested   TEXT DEFAULT (datetime('now')),
 		CONSTRAINT uq_entity_name_layer UNIQUE (name_normalized, layer)
@@ -44,6 +49,25 @@ func (c *Client) EnsureSchema(ctx context.Context, schema *config.Schema) error
 		CONSTRAINT uq_event_entity UNIQUE (entity_id)
 	);
 
+	CREATE TABLE IF NOT EXISTS relationship_types (
+		name      TEXT PRIMARY KEY,
+		inverse   TEXT DEFAULT '',
+		symmetric INTEGER DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS entity_type_ancestors (
+		entity_type TEXT NOT NULL,
+		ancestor    TEXT NOT NULL,
+		PRIMARY KEY (entity_type, ancestor)
+	);
+
+	CREATE TABLE IF NOT EXISTS schema_state (
+		id          INTEGER PRIMARY KEY CHECK (id = 1),
+		fingerprint TEXT NOT NULL,
+		source      TEXT NOT NULL,
+		ingested_at TEXT DEFAULT (datetime('now'))
+	);
+
 	CREATE INDEX IF NOT EXISTS idx_entities_layer ON entities (layer);
 	CREATE INDEX IF NOT EXISTS idx_entities_type ON entities (entity_type);
 	CREATE INDEX IF NOT EXISTS idx_entities_source_file ON entities (source_file);
@@ -62,34 +86,60 @@ func (c *Client) EnsureSchema(ctx context.Context, schema *config.Schema) error
 		name,
 		tags,
 		body,
+		secret,
+		aliases,
 		content=entities,
 		content_rowid=id
 	);
 
 	CREATE TRIGGER IF NOT EXISTS entities_ai AFTER INSERT ON entities BEGIN
-		INSERT INTO entities_fts(rowid, name, tags, body)
-		VALUES (new.id, new.name, new.tags, new.body);
+		INSERT INTO entities_fts(rowid, name, tags, body, secret, aliases)
+		VALUES (new.id, new.name, new.tags, new.body, new.secret, new.aliases);
 	END;
 
 	CREATE TRIGGER IF NOT EXISTS entities_ad AFTER DELETE ON entities BEGIN
-		INSERT INTO entities_fts(entities_fts, rowid, name, tags, body)
-		VALUES ('delete', old.id, old.name, old.tags, old.body);
+		INSERT INTO entities_fts(entities_fts, rowid, name, tags, body, secret, aliases)
+		VALUES ('delete', old.id, old.name, old.tags, old.body, old.secret, old.aliases);
 	END;
 
 	CREATE TRIGGER IF NOT EXISTS entities_au AFTER UPDATE ON entities BEGIN
-		INSERT INTO entities_fts(entities_fts, rowid, name, tags, body)
-		VALUES ('delete', old.id, old.name, old.tags, old.body);
-		INSERT INTO entities_fts(rowid, name, tags, body)
-		VALUES (new.id, new.name, new.tags, new.body);
+		INSERT INTO entities_fts(entities_fts, rowid, name, tags, body, secret, aliases)
+		VALUES ('delete', old.id, old.name, old.tags, old.body, old.secret, old.aliases);
+		INSERT INTO entities_fts(rowid, name, tags, body, secret, aliases)
+		VALUES (new.id, new.name, new.tags, new.body, new.secret, new.aliases);
 	END;
 	`
 
-	tx, err := c.db.BeginTx(ctx, nil)
+	tx, err := c.begin(ctx)
 	if err != nil {
 		return fmt.Errorf("beginning transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	rebuildFTS, err := dropOutdatedFTS(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	// Columns are added before the DDL runs because the search triggers it
+	// creates read them.
+	columns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{table: "entities", column: "defaulted_properties", definition: "TEXT DEFAULT '[]'"},
+		{table: "entities", column: "secret", definition: "TEXT DEFAULT ''"},
+		{table: "entities", column: "aliases", definition: "TEXT DEFAULT '[]'"},
+		{table: "entities", column: "aliases_normalized", definition: "TEXT DEFAULT '[]'"},
+		{table: "entities", column: "source_anchor", definition: "TEXT DEFAULT ''"},
+	}
+	for _, col := range columns {
+		if err := ensureColumn(ctx, tx, col.table, col.column, col.definition); err != nil {
+			return err
+		}
+	}
+
 	statements := splitStatements(ddl)
 	for _, stmt := range statements {
 		stmt = strings.TrimSpace(stmt)
@@ -101,6 +151,44 @@ func (c *Client) EnsureSchema(ctx context.Context, schema *config.Schema) error
 		}
 	}
 
+	if rebuildFTS {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO entities_fts(entities_fts) VALUES ('rebuild')"); err != nil {
+			return fmt.Errorf("rebuilding search index: %w", err)
+		}
+	}
+
+	if schema != nil {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM relationship_types"); err != nil {
+			return fmt.Errorf("clearing relationship types: %w", err)
+		}
+		for _, rel := range schema.RelationshipTypes {
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO relationship_types (name, inverse, symmetric) VALUES (?, ?, ?)",
+				rel.Name, rel.Inverse, rel.Symmetric,
+			)
+			if err != nil {
+				return fmt.Errorf("storing relationship type %s: %w", rel.Name, err)
+			}
+		}
+
+		// Type filters match subtypes through this table, so it is kept
+		// lower-cased like the names entities are compared by.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM entity_type_ancestors"); err != nil {
+			return fmt.Errorf("clearing entity type ancestors: %w", err)
+		}
+		for _, entityType := range schema.EntityTypes {
+			for _, ancestor := range entityType.Ancestors() {
+				_, err := tx.ExecContext(ctx,
+					"INSERT INTO entity_type_ancestors (entity_type, ancestor) VALUES (?, ?)",
+					strings.ToLower(entityType.Name), strings.ToLower(ancestor),
+				)
+				if err != nil {
+					return fmt.Errorf("storing ancestors of entity type %s: %w", entityType.Name, err)
+				}
+			}
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("committing schema transaction: %w", err)
 	}
@@ -108,21 +196,101 @@ func (c *Client) EnsureSchema(ctx context.Context, schema *config.Schema) error
 	return nil
 }
 
+// ensureColumn adds a column to an existing table. SQLite has no
+// ADD COLUMN IF NOT EXISTS, so databases created by older versions are
+// migrated by inspecting table_info. A table that does not exist yet is left
+// to the DDL, which creates it with every column.
+func ensureColumn(ctx context.Context, tx querier, table, column, definition string) error {
+	columns, err := tableColumns(ctx, tx, table)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 || columns[strings.ToLower(column)] {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("adding column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// dropOutdatedFTS drops a search index created before the secret and alias
+// columns were indexed, along with its triggers, so the DDL can recreate both.
+// FTS5 tables cannot gain columns in place. It reports whether the index must
+// be rebuilt.
+func dropOutdatedFTS(ctx context.Context, tx querier) (bool, error) {
+	columns, err := tableColumns(ctx, tx, "entities_fts")
+	if err != nil {
+		return false, err
+	}
+	if len(columns) == 0 || (columns["secret"] && columns["aliases"]) {
+		return false, nil
+	}
+
+	for _, stmt := range []string{
+		"DROP TRIGGER IF EXISTS entities_ai",
+		"DROP TRIGGER IF EXISTS entities_ad",
+		"DROP TRIGGER IF EXISTS entities_au",
+		"DROP TABLE IF EXISTS entities_fts",
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return false, fmt.Errorf("dropping outdated search index: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// tableColumns returns the lowercased column names of table, or none if the
+// table does not exist.
+func tableColumns(ctx context.Context, tx querier, table string) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("inspecting %s columns: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue any
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, fmt.Errorf("scanning %s columns: %w", table, err)
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating %s columns: %w", table, err)
+	}
+	return columns, nil
+}
+
 func splitStatements(ddl string) []string {
 	var statements []string
 	var current strings.Builder
+	inTrigger := false
 
 	for _, line := range strings.Split(ddl, "\n") {
 		stripped := strings.TrimSpace(line)
 		if strings.HasPrefix(stripped, "--") {
 			continue
 		}
+		if current.Len() == 0 || strings.TrimSpace(current.String()) == "" {
+			inTrigger = strings.HasPrefix(strings.ToUpper(stripped), "CREATE TRIGGER")
+		}
 		current.WriteString(line)
 		current.WriteString("\n")
 
+		// Trigger bodies contain their own semicolon-terminated statements,
+		// so a trigger only ends at its closing END;.
+		if inTrigger && !strings.EqualFold(stripped, "END;") {
+			continue
+		}
 		if strings.HasSuffix(stripped, ";") {
 			statements = append(statements, current.String())
 			current.Reset()
+			inTrigger = false
 		}
 	}
 
@@ -16,21 +16,35 @@ func (c *Client) Search(ctx context.Context, query, layer, entityType string) ([
 
 	ftsQuery := convertWebsearchToFTS5(query)
 
+	// Secrets are matched and quoted only for unrestricted readers; a body
+	// snippet is preferred when the body itself matched.
+	snippet := `snippet(entities_fts, 2, '**', '**', '...', 50)`
+	if c.visibility != nil {
+		ftsQuery = "{name tags body aliases} : (" + ftsQuery + ")"
+	} else {
+		snippet = `CASE WHEN instr(` + snippet + `, '**') > 0 OR e.secret = '' THEN ` + snippet + `
+			ELSE snippet(entities_fts, 3, '**', '**', '...', 50) END`
+	}
+
+	visible, visibleArgs := c.visibleClause("e")
+	typed, typeArgs := typeClause("e", entityType)
 	sqlQuery := `
 	SELECT e.name, e.entity_type, e.layer, e.tags,
-		   bm25(entities_fts, 10.0, 4.0, 1.0) AS score,
-		   snippet(entities_fts, 2, '**', '**', '...', 50) AS snippet
+		   bm25(entities_fts, 10.0, 4.0, 1.0, 1.0, 8.0) AS score,
+		   ` + snippet + ` AS snippet
 	FROM entities_fts
 	JOIN entities e ON entities_fts.rowid = e.id
 	WHERE entities_fts MATCH ?
 	  AND (? = '' OR e.layer = ?)
-	  AND (? = '' OR e.entity_type = ?)
+	  AND ` + typed + `
 	  AND e.is_placeholder = 0
+	  AND ` + visible + `
 	ORDER BY score DESC, e.name ASC
 	LIMIT 50
 	`
 
-	rows, err := c.db.QueryContext(ctx, sqlQuery, ftsQuery, layer, layer, entityType, entityType)
+	args := append(append([]any{ftsQuery, layer, layer}, typeArgs...), visibleArgs...)
+	rows, err := c.conn().QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("searching entities: %w", err)
 	}
@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+	"lorecraft/internal/store"
+)
+
+func TestIngest_MultiEntityFile(t *testing.T) {
+	ctx := context.Background()
+	loreDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{loreDir}, Canonical: true}},
+		Links:    config.LinksConfig{Markdown: true},
+	}
+	client := newTestClient(t, cfg)
+	schema := exampleSchema(t)
+
+	folk := "---\ntitle: Harbour Folk\n---\n\n" +
+		"## Mira Vell\n\n---\ntype: npc\nrole: Harbourmaster\n---\n\nKeeps the tide tables.\n\n" +
+		"## Tomas Brine\n\n---\ntype: npc\nrole: Fisherman\n---\n\nSells bait.\n"
+	writeLoreFile(t, loreDir, "folk.md", folk)
+	writeLoreFile(t, loreDir, "rellan.md", "---\ntitle: Rellan Harth\ntype: npc\n---\n\nOwes [Tomas](folk.md#tomas-brine) money.\n")
+
+	result, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if len(result.Errors) > 0 || result.NodesUpserted != 3 {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	mira, err := client.GetEntity(ctx, "Mira Vell", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if mira == nil || mira.SourceFile != filepath.Join(loreDir, "folk.md") || mira.SourceAnchor != "mira-vell" {
+		t.Fatalf("unexpected source of Mira Vell: %#v", mira)
+	}
+
+	rels, err := client.GetRelationships(ctx, "Rellan Harth", "", "outgoing", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if len(rels) != 1 || rels[0].To.Name != "Tomas Brine" {
+		t.Fatalf("expected the link to resolve to the anchored entity, got %#v", rels)
+	}
+
+	// Editing one section re-ingests only that entity.
+	writeLoreFile(t, loreDir, "folk.md", folk+"He knows every reef.\n")
+	result, err = ingest.Run(ctx, cfg, schema, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+	if result.NodesUpserted != 1 || result.EntitiesSkipped != 1 || result.FilesSkipped != 1 {
+		t.Fatalf("expected only the edited section to be upserted, got %#v", result)
+	}
+
+	// Unchanged files are skipped whole.
+	result, err = ingest.Run(ctx, cfg, schema, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+	if result.NodesUpserted != 0 || result.EntitiesSkipped != 0 || result.FilesSkipped != 2 {
+		t.Fatalf("expected both files to be skipped unparsed, got %#v", result)
+	}
+
+	// Deleting a section removes its entity and keeps the rest of the file.
+	writeLoreFile(t, loreDir, "folk.md", "---\ntitle: Harbour Folk\n---\n\n"+
+		"## Mira Vell\n\n---\ntype: npc\nrole: Harbourmaster\n---\n\nKeeps the tide tables.\n\n")
+	result, err = ingest.Run(ctx, cfg, schema, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+	if result.NodesRemoved != 1 {
+		t.Fatalf("expected the deleted section to be removed, got %#v", result)
+	}
+	tomas, err := client.GetEntity(ctx, "Tomas Brine", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if tomas != nil {
+		t.Fatalf("expected the deleted section's entity to go, got %#v", tomas)
+	}
+	mira, err = client.GetEntity(ctx, "Mira Vell", "")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if mira == nil {
+		t.Fatalf("expected the remaining section to be kept")
+	}
+
+	hashes, err := client.GetLayerHashes(ctx, "setting")
+	if err != nil {
+		t.Fatalf("layer hashes: %v", err)
+	}
+	if _, ok := hashes[store.SourceKey(filepath.Join(loreDir, "folk.md"), "mira-vell")]; !ok {
+		t.Fatalf("expected hashes keyed by section, got %#v", hashes)
+	}
+}
+
+func TestIngest_FileWithErrorIsNotSkipped(t *testing.T) {
+	ctx := context.Background()
+	loreDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{loreDir}, Canonical: true}},
+	}
+	client := newTestClient(t, cfg)
+	schema := exampleSchema(t)
+
+	harbour := "---\ntitle: Harbour\n---\n\n" +
+		"## The Flood\n\n---\ntype: event\nsession: 2\n---\n\n" +
+		"## Tomas Brine\n\n---\ntype: npc\n---\n\nSells bait.\n"
+	writeLoreFile(t, loreDir, "harbour.md", harbour)
+	if _, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	writeLoreFile(t, loreDir, "harbour.md", strings.Replace(harbour, "session: 2\n", "session: 2\nconsequences: 42\n", 1))
+	for run := range 2 {
+		result, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{})
+		if err != nil {
+			t.Fatalf("ingest: %v", err)
+		}
+		if len(result.Errors) != 1 || result.FilesSkipped != 0 {
+			t.Fatalf("run %d: expected the broken section to be reported again, got %#v", run+1, result)
+		}
+	}
+}
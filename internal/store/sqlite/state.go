@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -52,6 +53,8 @@ func (c *Client) GetTimeline(ctx context.Context, layer, entity string, fromSess
 
 	entityNormalized := strings.ToLower(strings.TrimSpace(entity))
 
+	targetVisible, targetArgs := c.visibleClause("t")
+	eventVisible, eventArgs := c.visibleClause("e_ent")
 	query := `
 	SELECT e_ent.id, e_ent.name, ev.layer, ev.session, ev.date_in_world, ev.consequences
 	FROM events ev
@@ -63,13 +66,19 @@ func (c *Client) GetTimeline(ctx context.Context, layer, entity string, fromSess
 		  WHERE ea.src_id = e_ent.id
 			AND ea.rel_type IN ('AFFECTS', 'INVOLVES')
 			AND t.name_normalized = ?
+			AND ` + targetVisible + `
 	  ))
 	  AND (? = 0 OR ev.session >= ?)
 	  AND (? = 0 OR ev.session <= ?)
+	  AND ` + eventVisible + `
 	ORDER BY ev.session ASC, ev.id ASC
 	`
 
-	rows, err := c.db.QueryContext(ctx, query, layer, entityNormalized, entityNormalized, fromSession, fromSession, toSession, toSession)
+	args := []any{layer, entityNormalized, entityNormalized}
+	args = append(args, targetArgs...)
+	args = append(args, fromSession, fromSession, toSession, toSession)
+	args = append(args, eventArgs...)
+	rows, err := c.conn().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get timeline: %w", err)
 	}
@@ -116,12 +125,14 @@ func (c *Client) GetTimeline(ctx context.Context, layer, entity string, fromSess
 }
 
 func (c *Client) fetchEntityProperties(ctx context.Context, name, layer string) (map[string]any, error) {
-	query := `SELECT properties FROM entities WHERE name_normalized = ? AND layer = ?`
+	visible, visibleArgs := c.visibleClause("entities")
+	query := `SELECT properties FROM entities WHERE name_normalized = ? AND layer = ? AND ` + visible
 
 	var propsBytes []byte
-	err := c.db.QueryRowContext(ctx, query, strings.ToLower(name), layer).Scan(&propsBytes)
+	args := append([]any{strings.ToLower(name), layer}, visibleArgs...)
+	err := c.conn().QueryRowContext(ctx, query, args...).Scan(&propsBytes)
 	if err != nil {
-		if errors.Is(err, errors.New("sql: no rows")) {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("fetching base entity: %w", err)
@@ -141,6 +152,7 @@ func (c *Client) fetchEntityProperties(ctx context.Context, name, layer string)
 }
 
 func (c *Client) fetchEventsForEntity(ctx context.Context, name, layer string) ([]store.Event, error) {
+	visible, visibleArgs := c.visibleClause("e_ent")
 	query := `
 	SELECT e_ent.id, e_ent.name, ev.layer, ev.session, ev.date_in_world, ev.consequences
 	FROM events ev
@@ -148,10 +160,12 @@ func (c *Client) fetchEventsForEntity(ctx context.Context, name, layer string) (
 	JOIN edges ed ON ed.src_id = e_ent.id AND ed.rel_type = 'AFFECTS'
 	JOIN entities target ON ed.dst_id = target.id
 	WHERE target.name_normalized = ? AND ev.layer = ?
+	  AND ` + visible + `
 	ORDER BY ev.session ASC, ev.id ASC
 	`
 
-	rows, err := c.db.QueryContext(ctx, query, strings.ToLower(name), layer)
+	args := append([]any{strings.ToLower(name), layer}, visibleArgs...)
+	rows, err := c.conn().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("fetching events: %w", err)
 	}
@@ -198,13 +212,14 @@ func (c *Client) fetchEventsForEntity(ctx context.Context, name, layer string) (
 }
 
 func (c *Client) fetchEventParticipants(ctx context.Context, entityID int64) ([]string, error) {
+	visible, visibleArgs := c.visibleClause("p")
 	query := `
 	SELECT p.name FROM edges ep
 	JOIN entities p ON ep.dst_id = p.id
 	WHERE ep.src_id = ? AND ep.rel_type = 'INVOLVES'
-	`
+	  AND ` + visible
 
-	rows, err := c.db.QueryContext(ctx, query, entityID)
+	rows, err := c.conn().QueryContext(ctx, query, append([]any{entityID}, visibleArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("fetching participants: %w", err)
 	}
@@ -226,13 +241,14 @@ func (c *Client) fetchEventParticipants(ctx context.Context, entityID int64) ([]
 }
 
 func (c *Client) fetchEventLocations(ctx context.Context, entityID int64) ([]string, error) {
+	visible, visibleArgs := c.visibleClause("l")
 	query := `
 	SELECT l.name FROM edges el
 	JOIN entities l ON el.dst_id = l.id
 	WHERE el.src_id = ? AND el.rel_type = 'OCCURS_IN'
-	`
+	  AND ` + visible
 
-	rows, err := c.db.QueryContext(ctx, query, entityID)
+	rows, err := c.conn().QueryContext(ctx, query, append([]any{entityID}, visibleArgs...)...)
 	if err != nil {
 		return nil, fmt.Errorf("fetching locations: %w", err)
 	}
@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+)
+
+func TestIngestExample_CurrentState(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+
+	state, err := client.GetCurrentState(ctx, "Westport", "campaign-shadow-war")
+	if err != nil {
+		t.Fatalf("get current state: %v", err)
+	}
+	if state == nil {
+		t.Fatalf("expected state for Westport")
+	}
+	if len(state.Events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(state.Events))
+	}
+	if state.BaseProperties["government"] != "Merchant Council" || state.BaseProperties["size"] != "city" {
+		t.Fatalf("unexpected base properties: %#v", state.BaseProperties)
+	}
+	if state.CurrentProperties["government"] != "Emergency Harbor Council" {
+		t.Fatalf("expected government change, got %#v", state.CurrentProperties["government"])
+	}
+	if state.CurrentProperties["size"] != "town" {
+		t.Fatalf("expected size change, got %#v", state.CurrentProperties["size"])
+	}
+	districts, ok := state.CurrentProperties["districts"].([]any)
+	if !ok || len(districts) != 1 || districts[0] != "Harbor Ward" {
+		t.Fatalf("expected districts to gain Harbor Ward, got %#v", state.CurrentProperties["districts"])
+	}
+}
+
+func TestIngestExample_Timeline(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+
+	events, err := client.GetTimeline(ctx, "campaign-shadow-war", "", 0, 0)
+	if err != nil {
+		t.Fatalf("get timeline: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Name != "Storm Surge" || events[0].Session != 1 || events[0].DateInWorld != "12 Rainmoot 1243" {
+		t.Fatalf("unexpected first event: %#v", events[0])
+	}
+	if events[1].Name != "Reconstruction Effort" || events[1].Session != 2 {
+		t.Fatalf("unexpected second event: %#v", events[1])
+	}
+	if len(events[0].Location) != 1 || events[0].Location[0] != "Westport" {
+		t.Fatalf("unexpected event location: %#v", events[0].Location)
+	}
+	if len(events[0].Participants) != 1 || events[0].Participants[0] != "Bureau Director Lysa Quent" {
+		t.Fatalf("unexpected event participants: %#v", events[0].Participants)
+	}
+
+	filtered, err := client.GetTimeline(ctx, "campaign-shadow-war", "Westport", 2, 0)
+	if err != nil {
+		t.Fatalf("get filtered timeline: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Name != "Reconstruction Effort" {
+		t.Fatalf("unexpected filtered timeline: %#v", filtered)
+	}
+}
+
+func TestIngestExample_RemovesStaleEvents(t *testing.T) {
+	ctx := context.Background()
+	client, cfg := ingestExample(t)
+
+	cfg.Layers[1].Paths = []string{t.TempDir()}
+	placeholder := filepath.Join(cfg.Layers[1].Paths[0], "notes.md")
+	if err := os.WriteFile(placeholder, []byte("No frontmatter here.\n"), 0o600); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+
+	if _, err := ingest.Run(ctx, cfg, exampleSchema(t), client, ingest.Options{}); err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+
+	events, err := client.GetTimeline(ctx, "campaign-shadow-war", "", 0, 0)
+	if err != nil {
+		t.Fatalf("get timeline: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected stale events to be removed, got %d", len(events))
+	}
+
+	var count int
+	if err := client.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM events").Scan(&count); err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected events table to be empty, got %d rows", count)
+	}
+}
+
+func TestIngestExample_DefaultedProperties(t *testing.T) {
+	ctx := context.Background()
+	client, cfg := ingestExample(t)
+
+	selin, err := client.GetEntity(ctx, "Selin Hale", "npc")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if selin.Properties["status"] != "alive" || selin.Properties["visibility"] != "gm" {
+		t.Fatalf("expected schema defaults, got %#v", selin.Properties)
+	}
+	if !reflect.DeepEqual(selin.Defaulted, []string{"status", "visibility"}) {
+		t.Fatalf("expected status and visibility to be defaulted, got %#v", selin.Defaulted)
+	}
+
+	lysa, err := client.GetEntity(ctx, "Bureau Director Lysa Quent", "npc")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if lysa == nil {
+		t.Fatalf("expected Bureau Director Lysa Quent")
+	}
+	for _, key := range lysa.Defaulted {
+		if key == "status" {
+			t.Fatalf("authored status reported as defaulted: %#v", lysa.Defaulted)
+		}
+	}
+
+	cfg.Layers[1].Paths = []string{t.TempDir()}
+	writeLoreFile(t, cfg.Layers[1].Paths[0], "duel.md", `---
+title: Harbor Duel
+type: event
+session: 3
+affects: [Selin Hale]
+consequences:
+  - entity: Selin Hale
+    property: status
+    value: dead
+---
+`)
+	if _, err := ingest.Run(ctx, cfg, exampleSchema(t), client, ingest.Options{}); err != nil {
+		t.Fatalf("re-ingest: %v", err)
+	}
+
+	state, err := client.GetCurrentState(ctx, "Selin Hale", "campaign-shadow-war")
+	if err != nil {
+		t.Fatalf("get current state: %v", err)
+	}
+	if state.BaseProperties["status"] != "alive" {
+		t.Fatalf("expected defaulted base status, got %#v", state.BaseProperties)
+	}
+	if state.CurrentProperties["status"] != "dead" || state.CurrentProperties["visibility"] != "gm" {
+		t.Fatalf("unexpected current properties: %#v", state.CurrentProperties)
+	}
+}
+
+func ingestExample(t *testing.T) (*Client, *config.ProjectConfig) {
+	t.Helper()
+	ctx := context.Background()
+	exampleDir := filepath.Join("..", "..", "..", "example")
+
+	cfg := &config.ProjectConfig{
+		Project:  "westlands",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers: []config.Layer{
+			{Name: "setting", Paths: []string{filepath.Join(exampleDir, "lore")}, Canonical: true},
+			{Name: "campaign-shadow-war", Paths: []string{filepath.Join(exampleDir, "campaigns", "shadow-war")}, DependsOn: []string{"setting"}},
+		},
+	}
+
+	client := newTestClient(t, cfg)
+
+	result, err := ingest.Run(ctx, cfg, exampleSchema(t), client, ingest.Options{Full: true})
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("ingest errors: %v", result.Errors)
+	}
+	return client, cfg
+}
+
+func newTestClient(t *testing.T, cfg *config.ProjectConfig) *Client {
+	t.Helper()
+	ctx := context.Background()
+	client, err := New(ctx, cfg.Database.DSN, cfg)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { client.Close(ctx) })
+	return client
+}
+
+func exampleSchema(t *testing.T) *config.Schema {
+	t.Helper()
+	schema, err := config.LoadSchema(filepath.Join("..", "..", "..", "example", "schema.yaml"))
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+	return schema
+}
@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"lorecraft/internal/store"
+)
+
+// SuggestEntities ranks entity names in Go: SQLite has no trigram
+// similarity, and the names of a lore project fit comfortably in memory.
+func (c *Client) SuggestEntities(ctx context.Context, name string, limit int) ([]store.EntitySuggestion, error) {
+	if limit <= 0 {
+		limit = store.MaxSuggestions
+	}
+
+	visible, visibleArgs := c.visibleClause("entities")
+	query := `
+	SELECT name, entity_type, layer, aliases
+	FROM entities
+	WHERE is_placeholder = 0
+	  AND ` + visible
+
+	rows, err := c.conn().QueryContext(ctx, query, visibleArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("listing suggestion candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []store.SuggestionCandidate
+	for rows.Next() {
+		var candidate store.SuggestionCandidate
+		var aliasesBytes []byte
+		if err := rows.Scan(&candidate.Ref.Name, &candidate.Ref.EntityType, &candidate.Ref.Layer, &aliasesBytes); err != nil {
+			return nil, fmt.Errorf("scanning suggestion candidate: %w", err)
+		}
+		if len(aliasesBytes) > 0 {
+			if err := json.Unmarshal(aliasesBytes, &candidate.Aliases); err != nil {
+				return nil, fmt.Errorf("unmarshaling aliases: %w", err)
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating suggestion candidates: %w", err)
+	}
+
+	return store.RankSuggestions(name, candidates, limit), nil
+}
@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/store"
+)
+
+func TestSuggestEntities(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+
+	suggestions, err := client.SuggestEntities(ctx, "Lysa Quint", store.MaxSuggestions)
+	if err != nil {
+		t.Fatalf("suggest entities: %v", err)
+	}
+	if len(suggestions) == 0 || suggestions[0].Name != "Bureau Director Lysa Quent" {
+		t.Fatalf("expected Lysa Quent first, got %#v", suggestions)
+	}
+
+	player := client.WithVisibility(&store.Visibility{Property: "visibility", Hidden: []string{"gm"}})
+	suggestions, err = player.SuggestEntities(ctx, "Lysa Quint", store.MaxSuggestions)
+	if err != nil {
+		t.Fatalf("suggest entities: %v", err)
+	}
+	for _, s := range suggestions {
+		if s.Name == "Bureau Director Lysa Quent" {
+			t.Fatalf("hidden entity suggested to a restricted audience: %#v", suggestions)
+		}
+	}
+}
+
+func TestSuggestEntities_DefaultLimit(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+	}
+	client := newTestClient(t, cfg)
+	if err := client.EnsureSchema(ctx, exampleSchema(t)); err != nil {
+		t.Fatalf("ensure schema: %v", err)
+	}
+	for i := range store.MaxSuggestions + 3 {
+		if err := client.UpsertEntity(ctx, store.EntityInput{Name: fmt.Sprintf("Sailor %d", i), EntityType: "npc", Layer: "setting"}); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+
+	suggestions, err := client.SuggestEntities(ctx, "Sailor", 0)
+	if err != nil {
+		t.Fatalf("suggest entities: %v", err)
+	}
+	if len(suggestions) != store.MaxSuggestions {
+		t.Fatalf("expected %d suggestions, got %d", store.MaxSuggestions, len(suggestions))
+	}
+}
+
+func TestGetRelationships_NotFound(t *testing.T) {
+	client, _ := ingestExample(t)
+
+	_, err := client.GetRelationships(context.Background(), "Lysa Quint", "", "both", 1)
+	if !errors.Is(err, store.ErrEntityNotFound) {
+		t.Fatalf("expected ErrEntityNotFound, got %v", err)
+	}
+}
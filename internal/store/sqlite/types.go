@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// typeClause matches the entities of alias whose type is entityType or
+// extends it, using the ancestors stored by EnsureSchema. An empty
+// entityType matches every entity.
+func typeClause(alias, entityType string) (string, []any) {
+	column := alias + ".entity_type"
+	clause := `(? = '' OR ` + column + ` = ? OR LOWER(` + column + `) IN (SELECT entity_type FROM entity_type_ancestors WHERE ancestor = LOWER(?)))`
+	return clause, []any{entityType, entityType, entityType}
+}
+
+// isEventType reports whether entityType is "event" or extends it.
+func isEventType(ctx context.Context, tx querier, entityType string) (bool, error) {
+	if strings.EqualFold(entityType, "event") {
+		return true, nil
+	}
+	var count int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM entity_type_ancestors WHERE entity_type = LOWER(?) AND ancestor = 'event'",
+		entityType,
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("checking ancestors of %s: %w", entityType, err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+)
+
+func TestTypeFilters_MatchSubtypes(t *testing.T) {
+	ctx := context.Background()
+	loreDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{loreDir}, Canonical: true}},
+	}
+	client := newTestClient(t, cfg)
+
+	schemaPath := filepath.Join(t.TempDir(), "schema.yaml")
+	schemaYAML := "version: 1\nentity_types:\n" +
+		"  - { name: character, abstract: true, properties: [{ name: role, type: string }] }\n" +
+		"  - { name: npc, extends: character }\n" +
+		"  - { name: deity, extends: character }\n" +
+		"  - { name: settlement }\n" +
+		"relationship_types:\n  - name: MENTIONS\n  - name: RELATED_TO\n"
+	if err := os.WriteFile(schemaPath, []byte(schemaYAML), 0o600); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	schema, err := config.LoadSchema(schemaPath)
+	if err != nil {
+		t.Fatalf("load schema: %v", err)
+	}
+
+	writeLoreFile(t, loreDir, "mira.md", "---\ntitle: Mira Vell\ntype: npc\nrole: Harbourmaster\n---\n\nWatches the tide.\n")
+	writeLoreFile(t, loreDir, "tide.md", "---\ntitle: The Tide Mother\ntype: Deity\n---\n\nRules the tide.\n")
+	writeLoreFile(t, loreDir, "westport.md", "---\ntitle: Westport\ntype: settlement\n---\n\nA tide-washed port.\n")
+	writeLoreFile(t, loreDir, "folk.md", "---\ntitle: Harbour Folk\ntype: character\n---\n")
+	result, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected the abstract type to be rejected, got %v", result.Errors)
+	}
+
+	characters, err := client.ListEntities(ctx, "character", "", "")
+	if err != nil {
+		t.Fatalf("list entities: %v", err)
+	}
+	if len(characters) != 2 || characters[0].Name != "Mira Vell" || characters[1].Name != "The Tide Mother" {
+		t.Fatalf("expected both subtypes, got %#v", characters)
+	}
+
+	results, err := client.Search(ctx, "tide", "", "character")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected search to match subtypes only, got %#v", results)
+	}
+
+	entity, err := client.GetEntity(ctx, "Mira Vell", "character")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if entity == nil || entity.EntityType != "npc" {
+		t.Fatalf("expected lookup by supertype, got %#v", entity)
+	}
+	if entity, err := client.GetEntity(ctx, "Westport", "character"); err != nil || entity != nil {
+		t.Fatalf("expected no match outside the type, got %#v, %v", entity, err)
+	}
+}
+
+func TestIngest_EventSubtype(t *testing.T) {
+	ctx := context.Background()
+	loreDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "campaign", Paths: []string{loreDir}, Canonical: true}},
+	}
+	client := newTestClient(t, cfg)
+
+	schema, err := config.ParseSchema([]byte("version: 1\nentity_types:\n" +
+		"  - name: event\n    properties: [{ name: session, type: integer }]\n" +
+		"    field_mappings: [{ field: affects, relationship: AFFECTS }]\n" +
+		"  - { name: battle, extends: event }\n" +
+		"  - { name: settlement, properties: [{ name: government, type: string }] }\n" +
+		"relationship_types:\n  - name: AFFECTS\n  - name: MENTIONS\n  - name: RELATED_TO\n"))
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	writeLoreFile(t, loreDir, "westport.md", "---\ntitle: Westport\ntype: settlement\ngovernment: Harbour Council\n---\n")
+	writeLoreFile(t, loreDir, "siege.md", "---\ntitle: Siege of Westport\ntype: battle\nsession: 3\naffects: [Westport]\n"+
+		"consequences:\n  - entity: Westport\n    property: government\n    value: Military Governor\n---\n")
+	result, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{})
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+
+	events, err := client.GetTimeline(ctx, "campaign", "", 0, 0)
+	if err != nil {
+		t.Fatalf("timeline: %v", err)
+	}
+	if len(events) != 1 || events[0].Name != "Siege of Westport" || events[0].Session != 3 {
+		t.Fatalf("expected the battle on the timeline, got %#v", events)
+	}
+
+	state, err := client.GetCurrentState(ctx, "Westport", "campaign")
+	if err != nil {
+		t.Fatalf("current state: %v", err)
+	}
+	if state == nil || state.CurrentProperties["government"] != "Military Governor" {
+		t.Fatalf("expected the battle's consequences to apply, got %#v", state)
+	}
+}
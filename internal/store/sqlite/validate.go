@@ -10,7 +10,7 @@ import (
 func (c *Client) ListDanglingPlaceholders(ctx context.Context) ([]store.EntitySummary, error) {
 	query := `SELECT name, entity_type, layer, tags FROM entities WHERE is_placeholder = 1`
 
-	rows, err := c.db.QueryContext(ctx, query)
+	rows, err := c.conn().QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +51,7 @@ func (c *Client) ListOrphanedEntities(ctx context.Context) ([]store.EntitySummar
 	  AND e.is_placeholder = 0
 	`
 
-	rows, err := c.db.QueryContext(ctx, query)
+	rows, err := c.conn().QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -88,3 +88,48 @@ func (c *Client) ListOrphanedEntities(ctx context.Context) ([]store.EntitySummar
 func (c *Client) ListCrossLayerViolations(ctx context.Context) ([]store.EntitySummary, error) {
 	return []store.EntitySummary{}, nil
 }
+
+func (c *Client) ListEdges(ctx context.Context) ([]store.Edge, error) {
+	srcVisible, srcArgs := c.visibleClause("s")
+	dstVisible, dstArgs := c.visibleClause("d")
+	query := `
+	SELECT s.name, s.entity_type, s.layer, COALESCE(s.source_file, ''),
+		   d.name, d.entity_type, d.layer, d.is_placeholder,
+		   e.rel_type
+	FROM edges e
+	JOIN entities s ON e.src_id = s.id
+	JOIN entities d ON e.dst_id = d.id
+	WHERE ` + srcVisible + `
+	  AND ` + dstVisible + `
+	ORDER BY s.name, e.rel_type, d.name
+	`
+
+	rows, err := c.conn().QueryContext(ctx, query, append(srcArgs, dstArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []store.Edge
+	for rows.Next() {
+		var edge store.Edge
+		err := rows.Scan(
+			&edge.From.Name, &edge.From.EntityType, &edge.From.Layer, &edge.SourceFile,
+			&edge.To.Name, &edge.To.EntityType, &edge.To.Layer, &edge.Placeholder,
+			&edge.Type,
+		)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if edges == nil {
+		edges = []store.Edge{}
+	}
+	return edges, nil
+}
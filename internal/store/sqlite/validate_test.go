@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lorecraft/internal/config"
+	"lorecraft/internal/ingest"
+)
+
+func TestListEdges_PlaceholderResolvedLater(t *testing.T) {
+	ctx := context.Background()
+	loreDir := t.TempDir()
+	cfg := &config.ProjectConfig{
+		Project:  "test",
+		Version:  1,
+		Database: config.DatabaseConfig{DSN: "sqlite://" + filepath.Join(t.TempDir(), "lorecraft.db")},
+		Layers:   []config.Layer{{Name: "setting", Paths: []string{loreDir}, Canonical: true}},
+	}
+	client := newTestClient(t, cfg)
+	schema := exampleSchema(t)
+
+	writeLoreFile(t, loreDir, "quent.md", "---\ntitle: Lysa Quent\ntype: npc\nlocation: Bureau of Civic Affairs\n---\n")
+	if _, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	edges, err := client.ListEdges(ctx)
+	if err != nil {
+		t.Fatalf("list edges: %v", err)
+	}
+	if len(edges) != 1 || !edges[0].Placeholder {
+		t.Fatalf("expected one placeholder edge, got %#v", edges)
+	}
+
+	writeLoreFile(t, loreDir, "bureau.md", "---\ntitle: Bureau of Civic Affairs\ntype: faction\n---\n")
+	if _, err := ingest.Run(ctx, cfg, schema, client, ingest.Options{}); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+
+	edges, err = client.ListEdges(ctx)
+	if err != nil {
+		t.Fatalf("list edges: %v", err)
+	}
+	if len(edges) != 1 {
+		t.Fatalf("expected one edge, got %#v", edges)
+	}
+	edge := edges[0]
+	if edge.Placeholder || edge.To.EntityType != "faction" || edge.Type != "LOCATED_IN" {
+		t.Fatalf("expected resolved faction target, got %#v", edge)
+	}
+	if edge.SourceFile != filepath.Join(loreDir, "quent.md") {
+		t.Fatalf("unexpected source file: %q", edge.SourceFile)
+	}
+}
+
+func writeLoreFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"lorecraft/internal/store"
+)
+
+func (c *Client) WithVisibility(v *store.Visibility) store.Store {
+	view := *c
+	view.visibility = v
+	return &view
+}
+
+// visibleClause returns a condition on the entities table aliased as alias that
+// excludes entities hidden from the client's audience, and its arguments. It is
+// always true when no visibility filter is set.
+func (c *Client) visibleClause(alias string) (string, []any) {
+	if c.visibility == nil || len(c.visibility.Hidden) == 0 {
+		return "1 = 1", nil
+	}
+
+	marks := make([]string, len(c.visibility.Hidden))
+	args := make([]any, 0, len(c.visibility.Hidden)+1)
+	args = append(args, `$."`+c.visibility.Property+`"`)
+	for i, value := range c.visibility.Hidden {
+		marks[i] = "?"
+		args = append(args, strings.ToLower(value))
+	}
+	clause := fmt.Sprintf("lower(COALESCE(json_extract(%s.properties, ?), '')) NOT IN (%s)", alias, strings.Join(marks, ", "))
+	return clause, args
+}
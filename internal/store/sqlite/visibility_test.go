@@ -0,0 +1,170 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"lorecraft/internal/store"
+)
+
+func TestWithVisibility_HidesEntities(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+	player := client.WithVisibility(&store.Visibility{Property: "visibility", Hidden: []string{"GM"}})
+
+	lysa, err := player.GetEntity(ctx, "Bureau Director Lysa Quent", "npc")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if lysa != nil {
+		t.Fatalf("expected hidden entity to be omitted, got %#v", lysa)
+	}
+	rellan, err := player.GetEntity(ctx, "Overlord Rellan Harth", "npc")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if rellan == nil {
+		t.Fatalf("expected player-visible entity")
+	}
+	westport, err := player.GetEntity(ctx, "Westport", "settlement")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if westport == nil {
+		t.Fatalf("expected entity without the visibility property to stay visible")
+	}
+
+	npcs, err := player.ListEntities(ctx, "npc", "", "")
+	if err != nil {
+		t.Fatalf("list entities: %v", err)
+	}
+	for _, npc := range npcs {
+		if npc.Name != "Overlord Rellan Harth" {
+			t.Fatalf("expected only player-visible npcs, got %#v", npcs)
+		}
+	}
+
+	results, err := player.Search(ctx, "Bureau", "", "")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	for _, result := range results {
+		if result.Name == "Bureau Director Lysa Quent" {
+			t.Fatalf("hidden entity returned from search: %#v", results)
+		}
+	}
+
+	rels, err := player.GetRelationships(ctx, "Overlord Rellan Harth", "", "both", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	for _, rel := range rels {
+		if rel.From.Name == "Bureau Director Lysa Quent" || rel.To.Name == "Bureau Director Lysa Quent" {
+			t.Fatalf("edge to hidden entity returned: %#v", rel)
+		}
+	}
+	all, err := client.GetRelationships(ctx, "Overlord Rellan Harth", "", "both", 1)
+	if err != nil {
+		t.Fatalf("get relationships: %v", err)
+	}
+	if len(all) <= len(rels) {
+		t.Fatalf("expected the unrestricted client to see more edges, got %d and %d", len(all), len(rels))
+	}
+
+	entities, err := player.ListEntitiesWithProperties(ctx)
+	if err != nil {
+		t.Fatalf("list entities with properties: %v", err)
+	}
+	for _, entity := range entities {
+		if entity.Name == "Bureau Director Lysa Quent" || entity.Secret != "" {
+			t.Fatalf("hidden entity or secret listed: %#v", entity)
+		}
+	}
+	edges, err := player.ListEdges(ctx)
+	if err != nil {
+		t.Fatalf("list edges: %v", err)
+	}
+	for _, edge := range edges {
+		if edge.From.Name == "Bureau Director Lysa Quent" || edge.To.Name == "Bureau Director Lysa Quent" {
+			t.Fatalf("edge to hidden entity listed: %#v", edge)
+		}
+	}
+
+	if _, err := player.RunSQL(ctx, "SELECT name FROM entities", nil); err == nil {
+		t.Fatalf("expected raw SQL to be refused for a restricted audience")
+	}
+}
+
+func TestWithVisibility_HidesSecrets(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+	player := client.WithVisibility(&store.Visibility{Property: "visibility", Hidden: []string{"gm"}})
+
+	rellan, err := client.GetEntity(ctx, "Overlord Rellan Harth", "npc")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if !strings.Contains(rellan.Secret, "ledgers") || strings.Contains(rellan.Body, "ledgers") {
+		t.Fatalf("expected the gm block split from the body, got body %q secret %q", rellan.Body, rellan.Secret)
+	}
+	results, err := client.Search(ctx, "ledgers", "", "")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 1 || !strings.Contains(results[0].Snippet, "**ledgers**") {
+		t.Fatalf("expected the secret to be searchable without an audience, got %#v", results)
+	}
+
+	rellan, err = player.GetEntity(ctx, "Overlord Rellan Harth", "npc")
+	if err != nil {
+		t.Fatalf("get entity: %v", err)
+	}
+	if rellan.Secret != "" {
+		t.Fatalf("secret leaked to a restricted audience: %q", rellan.Secret)
+	}
+	results, err = player.Search(ctx, "ledgers", "", "")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("secret matched for a restricted audience: %#v", results)
+	}
+	results, err = player.Search(ctx, "Westlands", "", "npc")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	for _, result := range results {
+		if strings.Contains(result.Snippet, "ledgers") {
+			t.Fatalf("secret quoted in a snippet: %q", result.Snippet)
+		}
+	}
+}
+
+func TestEnsureSchema_RebuildsOutdatedSearchIndex(t *testing.T) {
+	ctx := context.Background()
+	client, _ := ingestExample(t)
+
+	for _, stmt := range []string{
+		"DROP TRIGGER entities_ai",
+		"DROP TRIGGER entities_ad",
+		"DROP TRIGGER entities_au",
+		"DROP TABLE entities_fts",
+		"CREATE VIRTUAL TABLE entities_fts USING fts5(name, tags, body, content=entities, content_rowid=id)",
+	} {
+		if _, err := client.db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if err := client.EnsureSchema(ctx, exampleSchema(t)); err != nil {
+		t.Fatalf("ensure schema: %v", err)
+	}
+	results, err := client.Search(ctx, "ledgers", "", "")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected the rebuilt index to cover secrets, got %#v", results)
+	}
+}
@@ -10,14 +10,49 @@ type Store interface {
 	Close(ctx context.Context) error
 	EnsureSchema(ctx context.Context, schema *config.Schema) error
 
+	// InTransaction runs fn as one unit of work: writes made through the Store
+	// passed to fn are committed together only if fn returns nil.
+	InTransaction(ctx context.Context, fn func(Store) error) error
+	// WithVisibility returns a view of the store whose queries exclude
+	// entities hidden by v. A nil v sees everything.
+	WithVisibility(v *Visibility) Store
+
+	// UpsertEntity writes an entity and clears its outgoing edges, which the
+	// caller re-creates with UpsertRelationship from the current frontmatter.
 	UpsertEntity(ctx context.Context, e EntityInput) error
 	UpsertRelationship(ctx context.Context, fromName, fromLayer, toName, toLayer, relType string) error
-	RemoveStaleNodes(ctx context.Context, layer string, currentSourceFiles []string) (int64, error)
+	// RemoveStaleNodes deletes the layer's entities whose SourceKey is not
+	// listed in currentSources.
+	RemoveStaleNodes(ctx context.Context, layer string, currentSources []string) (int64, error)
+	RemoveOrphanedPlaceholders(ctx context.Context) (int64, error)
+	// GetLayerHashes maps the SourceKey of every entity in the layer to its
+	// source hash.
 	GetLayerHashes(ctx context.Context, layer string) (map[string]string, error)
 	FindEntityLayer(ctx context.Context, name string, layers []string) (string, error)
 
+	// GetSchemaState returns the schema recorded by the last complete
+	// ingestion, or nil if none was. It creates nothing, so it may be called
+	// before EnsureSchema.
+	GetSchemaState(ctx context.Context) (*SchemaState, error)
+	SaveSchemaState(ctx context.Context, state SchemaState) error
+	// RenameProperty moves the stored values of property from to to on the
+	// entities of entityType that do not already have a value for to.
+	RenameProperty(ctx context.Context, entityType, from, to string) (int64, error)
+	// RenameRelationshipType renames the stored edges of a relationship type.
+	RenameRelationshipType(ctx context.Context, from, to string) (int64, error)
+
 	GetEntity(ctx context.Context, name, entityType string) (*Entity, error)
+	// GetEntityInLayer looks an entity up by name or alias within one layer,
+	// for names that a campaign layer overlays on another.
+	GetEntityInLayer(ctx context.Context, name, layer string) (*Entity, error)
 	GetRelationships(ctx context.Context, name, relType, direction string, depth int) ([]Relationship, error)
+	// FindPaths returns the simple paths of at most maxDepth hops between two
+	// entities, shortest first and at most MaxPaths of them. relTypes, when
+	// set, restricts the relationships a path may use.
+	FindPaths(ctx context.Context, from, to string, maxDepth int, relTypes []string) ([]Path, error)
+	// SuggestEntities ranks the entities whose name or alias resembles name,
+	// best first and at most limit of them, for lookups that found nothing.
+	SuggestEntities(ctx context.Context, name string, limit int) ([]EntitySuggestion, error)
 	ListEntities(ctx context.Context, entityType, layer, tag string) ([]EntitySummary, error)
 	ListEntitiesWithProperties(ctx context.Context) ([]Entity, error)
 	Search(ctx context.Context, query, layer, entityType string) ([]SearchResult, error)
@@ -27,6 +62,7 @@ type Store interface {
 	ListDanglingPlaceholders(ctx context.Context) ([]EntitySummary, error)
 	ListOrphanedEntities(ctx context.Context) ([]EntitySummary, error)
 	ListCrossLayerViolations(ctx context.Context) ([]EntitySummary, error)
+	ListEdges(ctx context.Context) ([]Edge, error)
 
 	RunSQL(ctx context.Context, query string, params map[string]any) ([]map[string]any, error)
 }
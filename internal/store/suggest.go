@@ -0,0 +1,132 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ErrEntityNotFound is wrapped by lookups that name an entity the audience
+// cannot see, so callers can offer suggestions instead.
+var ErrEntityNotFound = errors.New("entity not found")
+
+// MaxSuggestions is the number of suggestions offered for a name that did
+// not match.
+const MaxSuggestions = 5
+
+// SuggestionThreshold is the lowest score worth suggesting. It matches the
+// pg_trgm default similarity threshold.
+const SuggestionThreshold = 0.3
+
+// EntitySuggestion is an entity whose name, or the alias in Alias, resembles
+// a name that did not match. Score runs from 0 to 1.
+type EntitySuggestion struct {
+	Name       string
+	EntityType string
+	Layer      string
+	Alias      string
+	Score      float64
+}
+
+// SuggestionCandidate is an entity considered by RankSuggestions.
+type SuggestionCandidate struct {
+	Ref     EntityRef
+	Aliases []string
+}
+
+// RankSuggestions scores candidates against name with trigram similarity and
+// returns the best limit of them that reach SuggestionThreshold, highest
+// first. It is what backends without trigram support in the database use.
+func RankSuggestions(name string, candidates []SuggestionCandidate, limit int) []EntitySuggestion {
+	query := trigrams(name)
+	suggestions := []EntitySuggestion{}
+	for _, candidate := range candidates {
+		best := EntitySuggestion{
+			Name:       candidate.Ref.Name,
+			EntityType: candidate.Ref.EntityType,
+			Layer:      candidate.Ref.Layer,
+			Score:      trigramScore(query, candidate.Ref.Name),
+		}
+		for _, alias := range candidate.Aliases {
+			if score := trigramScore(query, alias); score > best.Score {
+				best.Score = score
+				best.Alias = alias
+			}
+		}
+		if best.Score >= SuggestionThreshold {
+			suggestions = append(suggestions, best)
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return strings.ToLower(suggestions[i].Name) < strings.ToLower(suggestions[j].Name)
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// trigramScore rates how well the trigrams of a queried name match text: the
+// better of their similarity and the similarity to the closest run of words
+// in text, so that "Lysa Quint" scores well against "Bureau Director Lysa
+// Quent". This approximates GREATEST(similarity, word_similarity) in pg_trgm.
+func trigramScore(query map[string]bool, text string) float64 {
+	words := splitWords(text)
+	best := jaccard(query, trigrams(text))
+	for start := range words {
+		for end := start + 1; end <= len(words); end++ {
+			best = max(best, jaccard(query, trigrams(strings.Join(words[start:end], " "))))
+		}
+	}
+	return best
+}
+
+// FormatSuggestions renders suggestions as "Name (type, layer)" items,
+// mentioning the alias that matched, for "did you mean" messages.
+func FormatSuggestions(suggestions []EntitySuggestion) string {
+	items := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		items[i] = fmt.Sprintf("%s (%s, %s)", s.Name, s.EntityType, s.Layer)
+		if s.Alias != "" {
+			items[i] = fmt.Sprintf("%s (%s, %s, alias %s)", s.Name, s.EntityType, s.Layer, s.Alias)
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+// trigrams returns the trigram set of text the way pg_trgm builds it: each
+// lowercased word padded with two spaces in front and one behind.
+func trigrams(text string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range splitWords(text) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for gram := range a {
+		if b[gram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,41 @@
+package store
+
+import "testing"
+
+func TestRankSuggestions(t *testing.T) {
+	candidates := []SuggestionCandidate{
+		{Ref: EntityRef{Name: "Bureau Director Lysa Quent", EntityType: "npc", Layer: "setting"}},
+		{Ref: EntityRef{Name: "Selin Hale", EntityType: "npc", Layer: "setting"}},
+		{Ref: EntityRef{Name: "Westport", EntityType: "settlement", Layer: "setting"}, Aliases: []string{"The Harbor City"}},
+		{Ref: EntityRef{Name: "Iron Tide", EntityType: "faction", Layer: "setting"}},
+	}
+
+	suggestions := RankSuggestions("Lysa Quint", candidates, MaxSuggestions)
+	if len(suggestions) != 1 || suggestions[0].Name != "Bureau Director Lysa Quent" {
+		t.Fatalf("expected Lysa Quent to be suggested, got %#v", suggestions)
+	}
+
+	suggestions = RankSuggestions("harbour city", candidates, MaxSuggestions)
+	if len(suggestions) != 1 || suggestions[0].Name != "Westport" || suggestions[0].Alias != "The Harbor City" {
+		t.Fatalf("expected Westport by alias, got %#v", suggestions)
+	}
+
+	if suggestions := RankSuggestions("Dragonspire", candidates, MaxSuggestions); len(suggestions) != 0 {
+		t.Fatalf("expected no suggestions, got %#v", suggestions)
+	}
+
+	suggestions = RankSuggestions("e", candidates, 2)
+	if len(suggestions) > 2 {
+		t.Fatalf("expected the limit to apply, got %#v", suggestions)
+	}
+}
+
+func TestTrigramScore(t *testing.T) {
+	if score := trigramScore(trigrams("Westport"), "westport"); score != 1 {
+		t.Fatalf("expected identical names to score 1, got %v", score)
+	}
+	whole := jaccard(trigrams("Lysa Quint"), trigrams("Bureau Director Lysa Quent"))
+	if score := trigramScore(trigrams("Lysa Quint"), "Bureau Director Lysa Quent"); score <= whole {
+		t.Fatalf("expected the closest words to score above the whole name, got %v <= %v", score, whole)
+	}
+}
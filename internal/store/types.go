@@ -5,21 +5,49 @@ type EntityInput struct {
 	EntityType string
 	Layer      string
 	SourceFile string
+	// SourceAnchor names the section of SourceFile that defines the entity
+	// when the file defines several; it is empty otherwise.
+	SourceAnchor string
+	// SourceHash is the hash of the part of the file that defines the entity.
+	// The last entity of a file that ingested cleanly records the hash of the
+	// whole file instead, so ingest can skip the file unparsed.
 	SourceHash string
 	Properties map[string]any
 	Tags       []string
-	Body       string
+	// Aliases are alternate names that resolve to this entity in lookups and
+	// relationship targets.
+	Aliases []string
+	Body    string
+	// Secret is the GM-only text split out of the body. It is indexed
+	// separately so restricted audiences never match or see it.
+	Secret string
+	// Defaulted lists the properties whose values came from schema defaults
+	// rather than the source file.
+	Defaulted []string
 }
 
 type Entity struct {
-	Name       string
-	EntityType string
-	Layer      string
-	SourceFile string
-	SourceHash string
-	Tags       []string
-	Properties map[string]any
-	Body       string
+	Name         string
+	EntityType   string
+	Layer        string
+	SourceFile   string
+	SourceAnchor string
+	SourceHash   string
+	Tags         []string
+	Aliases      []string
+	Properties   map[string]any
+	Body         string
+	Secret       string
+	Defaulted    []string
+}
+
+// SourceKey identifies the part of a source file that defines one entity:
+// the file itself, or file#anchor for a section of a multi-entity file.
+func SourceKey(file, anchor string) string {
+	if anchor == "" {
+		return file
+	}
+	return file + "#" + anchor
 }
 
 type EntitySummary struct {
@@ -43,6 +71,17 @@ type Relationship struct {
 	Depth     int
 }
 
+// Edge is a stored relationship with both endpoints resolved. SourceFile is
+// the file of the entity that declared the edge; Placeholder reports whether
+// the target has not been defined by any file yet.
+type Edge struct {
+	From        EntityRef
+	To          EntityRef
+	Type        string
+	SourceFile  string
+	Placeholder bool
+}
+
 type SearchResult struct {
 	Name       string
 	EntityType string
@@ -74,3 +113,18 @@ type CurrentState struct {
 	Events            []Event
 	CurrentProperties map[string]any
 }
+
+// Visibility describes what an audience may not see: entities whose Property
+// value matches one of Hidden, compared case-insensitively, are left out of
+// query results together with their edges, events and search snippets.
+type Visibility struct {
+	Property string
+	Hidden   []string
+}
+
+// SchemaState records the schema the last complete ingestion ran with.
+type SchemaState struct {
+	Fingerprint string
+	// Source is the schema file as it was then.
+	Source []byte
+}
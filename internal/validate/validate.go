@@ -22,6 +22,9 @@ const (
 	codeDanglingPlaceholder = "dangling_placeholder"
 	codeOrphanedEntity      = "orphaned_entity"
 	codeCrossLayerViolation = "cross_layer_violation"
+	codeTargetTypeMismatch  = "target_type_mismatch"
+	codeTypeMismatch        = "property_type_mismatch"
+	codeAliasCollision      = "alias_collision"
 )
 
 type Issue struct {
@@ -31,6 +34,7 @@ type Issue struct {
 	Layer    string
 	Entity   string
 	FilePath string
+	Field    string
 }
 
 type Report struct {
@@ -57,9 +61,11 @@ func Run(ctx context.Context, schema *config.Schema, db Store) (*Report, error)
 		if !ok {
 			continue
 		}
+		issues = append(issues, validatePropertyTypes(&entity, entityType)...)
 		issues = append(issues, validateEnumValues(&entity, entityType)...)
 		issues = append(issues, validateRequiredProperties(&entity, entityType)...)
 	}
+	issues = append(issues, validateAliases(entities)...)
 
 	placeholders, err := db.ListDanglingPlaceholders(ctx)
 	if err != nil {
@@ -85,6 +91,12 @@ func Run(ctx context.Context, schema *config.Schema, db Store) (*Report, error)
 		issues = append(issues, issueFromSummary(summary, SeverityError, codeCrossLayerViolation, "cross-layer violation"))
 	}
 
+	edges, err := db.ListEdges(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list edges: %w", err)
+	}
+	issues = append(issues, validateTargetTypes(schema, edges)...)
+
 	return &Report{Issues: issues}, nil
 }
 
@@ -113,7 +125,34 @@ func validateEnumValues(entity *store.Entity, entityType *config.EntityType) []I
 				Message:  fmt.Sprintf("invalid enum value for %s: %s", prop.Name, valueStr),
 				Layer:    entity.Layer,
 				Entity:   entity.Name,
-				FilePath: entity.SourceFile,
+				FilePath: store.SourceKey(entity.SourceFile, entity.SourceAnchor),
+			})
+		}
+	}
+
+	return issues
+}
+
+func validatePropertyTypes(entity *store.Entity, entityType *config.EntityType) []Issue {
+	if entity == nil || entityType == nil {
+		return nil
+	}
+
+	var issues []Issue
+	for _, prop := range entityType.Properties {
+		value, ok := entity.Properties[prop.Name]
+		if !ok || value == nil {
+			continue
+		}
+		if _, err := prop.Coerce(value); err != nil {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Code:     codeTypeMismatch,
+				Message:  fmt.Sprintf("invalid %s value for %s: %v", prop.BaseType(), prop.Name, err),
+				Layer:    entity.Layer,
+				Entity:   entity.Name,
+				FilePath: store.SourceKey(entity.SourceFile, entity.SourceAnchor),
+				Field:    prop.Name,
 			})
 		}
 	}
@@ -139,7 +178,7 @@ func validateRequiredProperties(entity *store.Entity, entityType *config.EntityT
 				Message:  fmt.Sprintf("missing required property: %s", prop.Name),
 				Layer:    entity.Layer,
 				Entity:   entity.Name,
-				FilePath: entity.SourceFile,
+				FilePath: store.SourceKey(entity.SourceFile, entity.SourceAnchor),
 			})
 			continue
 		}
@@ -150,7 +189,7 @@ func validateRequiredProperties(entity *store.Entity, entityType *config.EntityT
 				Message:  fmt.Sprintf("missing required property: %s", prop.Name),
 				Layer:    entity.Layer,
 				Entity:   entity.Name,
-				FilePath: entity.SourceFile,
+				FilePath: store.SourceKey(entity.SourceFile, entity.SourceAnchor),
 			})
 		}
 	}
@@ -158,6 +197,104 @@ func validateRequiredProperties(entity *store.Entity, entityType *config.EntityT
 	return issues
 }
 
+// validateAliases reports aliases that are another entity's name or alias,
+// which makes lookups by that alias ambiguous. Entities sharing a name, such as
+// a campaign's take on a setting entity, may repeat each other's aliases.
+func validateAliases(entities []store.Entity) []Issue {
+	names := map[string][]*store.Entity{}
+	aliases := map[string][]*store.Entity{}
+	for i := range entities {
+		entity := &entities[i]
+		names[strings.ToLower(entity.Name)] = append(names[strings.ToLower(entity.Name)], entity)
+		for _, alias := range entity.Aliases {
+			aliases[strings.ToLower(alias)] = append(aliases[strings.ToLower(alias)], entity)
+		}
+	}
+
+	var issues []Issue
+	for i := range entities {
+		entity := &entities[i]
+		for _, alias := range entity.Aliases {
+			key := strings.ToLower(alias)
+			var others []string
+			for _, other := range names[key] {
+				if !strings.EqualFold(other.Name, entity.Name) {
+					others = append(others, fmt.Sprintf("the name of %s (%s)", other.Name, other.Layer))
+				}
+			}
+			for _, other := range aliases[key] {
+				if !strings.EqualFold(other.Name, entity.Name) {
+					others = append(others, fmt.Sprintf("an alias of %s (%s)", other.Name, other.Layer))
+				}
+			}
+			if len(others) == 0 {
+				continue
+			}
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Code:     codeAliasCollision,
+				Message:  fmt.Sprintf("alias %s is also %s", alias, strings.Join(others, " and ")),
+				Layer:    entity.Layer,
+				Entity:   entity.Name,
+				FilePath: store.SourceKey(entity.SourceFile, entity.SourceAnchor),
+				Field:    "aliases",
+			})
+		}
+	}
+	return issues
+}
+
+// validateTargetTypes reports edges created by a field mapping whose target
+// entity type is neither listed in the mapping's target_type nor a subtype of
+// one that is. Unresolved
+// placeholders have no type yet and are reported as dangling instead; once a
+// placeholder is defined by a file its real type is checked here.
+func validateTargetTypes(schema *config.Schema, edges []store.Edge) []Issue {
+	var issues []Issue
+	for _, edge := range edges {
+		if edge.Placeholder || edge.To.EntityType == "" {
+			continue
+		}
+		entityType, ok := schema.EntityTypeByName(edge.From.EntityType)
+		if !ok {
+			continue
+		}
+
+		var fields []string
+		var allowed []string
+		matched := false
+		for _, mapping := range entityType.FieldMappings {
+			if !strings.EqualFold(mapping.Relationship, edge.Type) || len(mapping.TargetType) == 0 {
+				continue
+			}
+			for _, target := range mapping.TargetType {
+				matched = matched || schema.IsA(edge.To.EntityType, target)
+			}
+			if matched {
+				break
+			}
+			fields = append(fields, mapping.Field)
+			allowed = append(allowed, mapping.TargetType...)
+		}
+		if matched || len(fields) == 0 {
+			continue
+		}
+
+		field := strings.Join(fields, ", ")
+		issues = append(issues, Issue{
+			Severity: SeverityError,
+			Code:     codeTargetTypeMismatch,
+			Message: fmt.Sprintf("%s target %s is a %s, expected %s",
+				field, edge.To.Name, edge.To.EntityType, strings.Join(allowed, " or ")),
+			Layer:    edge.From.Layer,
+			Entity:   edge.From.Name,
+			FilePath: edge.SourceFile,
+			Field:    field,
+		})
+	}
+	return issues
+}
+
 func issueFromSummary(summary store.EntitySummary, severity Severity, code, message string) Issue {
 	return Issue{
 		Severity: severity,
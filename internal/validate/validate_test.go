@@ -17,6 +17,7 @@ type mockStore struct {
 	orphans       []store.EntitySummary
 	duplicates    []store.EntitySummary
 	crossLayer    []store.EntitySummary
+	edges         []store.Edge
 }
 
 func (m *mockStore) Close(ctx context.Context) error { return nil }
@@ -29,10 +30,20 @@ func (m *mockStore) UpsertRelationship(ctx context.Context, fromName, fromLayer,
 	return nil
 }
 
+func (m *mockStore) InTransaction(ctx context.Context, fn func(store.Store) error) error {
+	return fn(m)
+}
+
+func (m *mockStore) WithVisibility(v *store.Visibility) store.Store { return m }
+
 func (m *mockStore) RemoveStaleNodes(ctx context.Context, layer string, currentSourceFiles []string) (int64, error) {
 	return 0, nil
 }
 
+func (m *mockStore) RemoveOrphanedPlaceholders(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
 func (m *mockStore) GetLayerHashes(ctx context.Context, layer string) (map[string]string, error) {
 	return nil, nil
 }
@@ -41,6 +52,22 @@ func (m *mockStore) FindEntityLayer(ctx context.Context, name string, layers []s
 	return "", nil
 }
 
+func (m *mockStore) GetSchemaState(ctx context.Context) (*store.SchemaState, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SaveSchemaState(ctx context.Context, state store.SchemaState) error {
+	return nil
+}
+
+func (m *mockStore) RenameProperty(ctx context.Context, entityType, from, to string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockStore) RenameRelationshipType(ctx context.Context, from, to string) (int64, error) {
+	return 0, nil
+}
+
 func (m *mockStore) ListEntities(ctx context.Context, entityType, layer, tag string) ([]store.EntitySummary, error) {
 	return m.entities, nil
 }
@@ -55,6 +82,10 @@ func (m *mockStore) ListEntitiesWithProperties(ctx context.Context) ([]store.Ent
 	return entities, nil
 }
 
+func (m *mockStore) GetEntityInLayer(ctx context.Context, name, layer string) (*store.Entity, error) {
+	return nil, nil
+}
+
 func (m *mockStore) GetEntity(ctx context.Context, name, entityType string) (*store.Entity, error) {
 	if m.entityDetails == nil {
 		return nil, nil
@@ -70,6 +101,14 @@ func (m *mockStore) GetRelationships(ctx context.Context, name, relType, directi
 	return nil, nil
 }
 
+func (m *mockStore) FindPaths(ctx context.Context, from, to string, maxDepth int, relTypes []string) ([]store.Path, error) {
+	return nil, nil
+}
+
+func (m *mockStore) SuggestEntities(ctx context.Context, name string, limit int) ([]store.EntitySuggestion, error) {
+	return nil, nil
+}
+
 func (m *mockStore) Search(ctx context.Context, query, layer, entityType string) ([]store.SearchResult, error) {
 	return nil, nil
 }
@@ -94,6 +133,10 @@ func (m *mockStore) ListCrossLayerViolations(ctx context.Context) ([]store.Entit
 	return m.crossLayer, nil
 }
 
+func (m *mockStore) ListEdges(ctx context.Context) ([]store.Edge, error) {
+	return m.edges, nil
+}
+
 func (m *mockStore) RunSQL(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
 	return nil, nil
 }
@@ -130,6 +173,45 @@ relationship_types:
 	}
 }
 
+func TestRun_PropertyTypeMismatch(t *testing.T) {
+	schema := loadSchema(t, `version: 1
+entity_types:
+  - name: event
+    properties:
+      - { name: session, type: integer }
+      - { name: canon, type: boolean }
+relationship_types:
+  - name: RELATED_TO
+`)
+
+	validator := &mockStore{
+		entities: []store.EntitySummary{{Name: "Storm Surge", EntityType: "event", Layer: "campaign"}},
+		entityDetails: map[string]*store.Entity{
+			"Storm Surge|event": {
+				Name:       "Storm Surge",
+				EntityType: "event",
+				Layer:      "campaign",
+				SourceFile: "campaigns/01.md",
+				Properties: map[string]any{"session": "two", "canon": true},
+			},
+		},
+	}
+
+	report, err := Run(context.Background(), schema, validator)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	var mismatches []Issue
+	for _, issue := range report.Issues {
+		if issue.Code == codeTypeMismatch {
+			mismatches = append(mismatches, issue)
+		}
+	}
+	if len(mismatches) != 1 || mismatches[0].Field != "session" {
+		t.Fatalf("expected session type mismatch, got %+v", mismatches)
+	}
+}
+
 func TestRun_MissingRequiredProperty(t *testing.T) {
 	schema := loadSchema(t, `version: 1
 entity_types:
@@ -204,6 +286,108 @@ relationship_types:
 	}
 }
 
+func TestRun_TargetTypeMismatch(t *testing.T) {
+	schema := loadSchema(t, `version: 1
+entity_types:
+  - name: npc
+    field_mappings:
+      - { field: location, relationship: LOCATED_IN, target_type: [settlement, region] }
+      - { field: faction, relationship: MEMBER_OF, target_type: [faction] }
+  - name: settlement
+  - name: city
+    extends: settlement
+  - name: faction
+relationship_types:
+  - name: LOCATED_IN
+  - name: MEMBER_OF
+`)
+
+	validator := &mockStore{
+		edges: []store.Edge{
+			{
+				From:       store.EntityRef{Name: "Lysa Quent", EntityType: "npc", Layer: "setting"},
+				To:         store.EntityRef{Name: "Bureau of Civic Affairs", EntityType: "faction", Layer: "setting"},
+				Type:       "LOCATED_IN",
+				SourceFile: "lore/lysa-quent.md",
+			},
+			{
+				From: store.EntityRef{Name: "Lysa Quent", EntityType: "npc", Layer: "setting"},
+				To:   store.EntityRef{Name: "Bureau of Civic Affairs", EntityType: "faction", Layer: "setting"},
+				Type: "MEMBER_OF",
+			},
+			{
+				From: store.EntityRef{Name: "Selin Hale", EntityType: "npc", Layer: "setting"},
+				To:   store.EntityRef{Name: "Westport", EntityType: "city", Layer: "setting"},
+				Type: "LOCATED_IN",
+			},
+			{
+				From:        store.EntityRef{Name: "Lysa Quent", EntityType: "npc", Layer: "setting"},
+				To:          store.EntityRef{Name: "Nowhere", Layer: "setting"},
+				Type:        "LOCATED_IN",
+				Placeholder: true,
+			},
+		},
+	}
+
+	report, err := Run(context.Background(), schema, validator)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var mismatches []Issue
+	for _, issue := range report.Issues {
+		if issue.Code == codeTargetTypeMismatch {
+			mismatches = append(mismatches, issue)
+		}
+	}
+	if len(mismatches) != 1 {
+		t.Fatalf("expected 1 target type mismatch, got %+v", mismatches)
+	}
+	if mismatches[0].Field != "location" || mismatches[0].FilePath != "lore/lysa-quent.md" {
+		t.Fatalf("unexpected mismatch issue: %+v", mismatches[0])
+	}
+}
+
+func TestRun_AliasCollision(t *testing.T) {
+	schema := loadSchema(t, `version: 1
+entity_types:
+  - name: npc
+relationship_types:
+  - name: RELATED_TO
+`)
+
+	validator := &mockStore{
+		entities: []store.EntitySummary{
+			{Name: "Lysa Quent", EntityType: "npc", Layer: "setting"},
+			{Name: "Selin Hale", EntityType: "npc", Layer: "setting"},
+			{Name: "The Director", EntityType: "npc", Layer: "setting"},
+		},
+		entityDetails: map[string]*store.Entity{
+			"Lysa Quent|npc":   {Name: "Lysa Quent", EntityType: "npc", Layer: "setting", SourceFile: "lore/lysa-quent.md", Aliases: []string{"The Director", "Lysa"}},
+			"Selin Hale|npc":   {Name: "Selin Hale", EntityType: "npc", Layer: "setting", Aliases: []string{"Hale"}},
+			"The Director|npc": {Name: "The Director", EntityType: "npc", Layer: "setting"},
+		},
+	}
+
+	report, err := Run(context.Background(), schema, validator)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var collisions []Issue
+	for _, issue := range report.Issues {
+		if issue.Code == codeAliasCollision {
+			collisions = append(collisions, issue)
+		}
+	}
+	if len(collisions) != 1 {
+		t.Fatalf("expected 1 alias collision, got %+v", collisions)
+	}
+	if collisions[0].Entity != "Lysa Quent" || collisions[0].FilePath != "lore/lysa-quent.md" {
+		t.Fatalf("unexpected collision issue: %+v", collisions[0])
+	}
+}
+
 func hasIssueCode(issues []Issue, code string) bool {
 	for _, issue := range issues {
 		if issue.Code == code {